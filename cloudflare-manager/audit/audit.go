@@ -0,0 +1,299 @@
+// Package audit compares zones with a baseline policy: zone settings, the
+// SSL mode, the DNSSEC state and records every zone has to have.
+package audit
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare-manager/zonesettings"
+	"github.com/cloudflare/cloudflare-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Baseline is the policy zones are audited against, read from YAML:
+//
+//	ssl: [full, strict]
+//	dnssec: true
+//	settings:
+//	  always_use_https: on
+//	  min_tls_version: "1.2"
+//	records:
+//	  spf: true
+//	  dmarc: true
+//	  caa_issuers: [letsencrypt.org]
+type Baseline struct {
+	// SSL lists the accepted SSL modes.
+	SSL StringList `yaml:"ssl"`
+	// DNSSEC requires DNSSEC to be active (true) or disabled (false).
+	DNSSEC   *bool                  `yaml:"dnssec"`
+	Settings map[string]interface{} `yaml:"settings"`
+	Records  RecordPolicy           `yaml:"records"`
+
+	profile *zonesettings.Profile
+}
+
+// RecordPolicy lists the records every zone needs.
+type RecordPolicy struct {
+	// SPF requires exactly one v=spf1 TXT record at the apex.
+	SPF bool `yaml:"spf"`
+	// DMARC requires a v=DMARC1 TXT record at _dmarc.
+	DMARC bool `yaml:"dmarc"`
+	// CAA requires at least one CAA record at the apex.
+	CAA bool `yaml:"caa"`
+	// CAAIssuers are CAs that must be allowed by an "issue" CAA record.
+	CAAIssuers []string `yaml:"caa_issuers"`
+}
+
+// StringList is a YAML list that can also be given as a single value.
+type StringList []string
+
+// UnmarshalYAML accepts a scalar or a sequence.
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Load reads and validates a baseline.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := b.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &b, nil
+}
+
+func (b *Baseline) validate() error {
+	if _, ok := b.Settings["ssl"]; ok && len(b.SSL) > 0 {
+		return fmt.Errorf("give the SSL mode either as ssl or under settings, not both")
+	}
+	for i, mode := range b.SSL {
+		v, err := zonesettings.Normalize("ssl", mode)
+		if err != nil {
+			return err
+		}
+		b.SSL[i] = v.(string)
+	}
+
+	profile, err := zonesettings.NewProfile(b.Settings)
+	if err != nil {
+		return err
+	}
+	b.profile = profile
+
+	r := b.Records
+	if len(b.SSL) == 0 && b.DNSSEC == nil && len(b.Settings) == 0 && !r.SPF && !r.DMARC && !r.CAA && len(r.CAAIssuers) == 0 {
+		return fmt.Errorf("baseline has no checks")
+	}
+	return nil
+}
+
+// NeedsSettings reports whether the baseline checks zone settings.
+func (b *Baseline) NeedsSettings() bool {
+	return len(b.SSL) > 0 || len(b.Settings) > 0
+}
+
+// NeedsRecords reports whether the baseline checks DNS records.
+func (b *Baseline) NeedsRecords() bool {
+	r := b.Records
+	return r.SPF || r.DMARC || r.CAA || len(r.CAAIssuers) > 0
+}
+
+// Zone is what is known about a zone. Fields the baseline does not need may
+// be left empty.
+type Zone struct {
+	Name     string
+	Settings []cloudflare.ZoneSetting
+	DNSSEC   string
+	Records  []cloudflare.DNSRecord
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check    string `json:"check"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	OK       bool   `json:"ok"`
+}
+
+// Check runs every check of the baseline against a zone.
+func (b *Baseline) Check(z Zone) []Result {
+	var results []Result
+
+	if len(b.SSL) > 0 {
+		actual := "(not set)"
+		for _, s := range z.Settings {
+			if s.ID == "ssl" {
+				actual = zonesettings.Format(s.Value)
+			}
+		}
+		results = append(results, Result{Check: "ssl", Expected: strings.Join(b.SSL, " or "), Actual: actual, OK: contains(b.SSL, actual)})
+	}
+
+	if b.DNSSEC != nil {
+		want := "disabled"
+		if *b.DNSSEC {
+			want = "active"
+		}
+		results = append(results, Result{Check: "dnssec", Expected: want, Actual: z.DNSSEC, OK: z.DNSSEC == want})
+	}
+
+	if len(b.Settings) > 0 {
+		failed := map[string]zonesettings.Change{}
+		for _, ch := range b.profile.Diff(z.Settings) {
+			failed[ch.Name] = ch
+		}
+		names := make([]string, 0, len(b.profile.Settings))
+		for name := range b.profile.Settings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			want := zonesettings.Format(b.profile.Settings[name])
+			res := Result{Check: "setting " + name, Expected: want, Actual: want, OK: true}
+			if ch, ok := failed[name]; ok {
+				res.Actual, res.OK = ch.From, false
+			}
+			results = append(results, res)
+		}
+	}
+
+	apex := strings.ToLower(z.Name)
+	if b.Records.SPF {
+		results = append(results, txtCheck("spf", "one v=spf1 TXT record at "+apex, txtRecords(z.Records, apex, "v=spf1")))
+	}
+	if b.Records.DMARC {
+		results = append(results, txtCheck("dmarc", "one v=DMARC1 TXT record at _dmarc."+apex, txtRecords(z.Records, "_dmarc."+apex, "v=DMARC1")))
+	}
+	if b.Records.CAA || len(b.Records.CAAIssuers) > 0 {
+		results = append(results, b.caaCheck(z.Records, apex))
+	}
+	return results
+}
+
+// txtRecords returns the TXT records at name whose text starts with prefix.
+func txtRecords(records []cloudflare.DNSRecord, name, prefix string) []string {
+	var found []string
+	for _, rec := range records {
+		text := strings.Trim(rec.Content, `"`)
+		if rec.Type == "TXT" && strings.EqualFold(rec.Name, name) && strings.HasPrefix(strings.ToLower(text), strings.ToLower(prefix)) {
+			found = append(found, text)
+		}
+	}
+	return found
+}
+
+func txtCheck(check, expected string, found []string) Result {
+	res := Result{Check: check, Expected: expected, OK: len(found) == 1}
+	switch len(found) {
+	case 0:
+		res.Actual = "missing"
+	case 1:
+		res.Actual = found[0]
+	default:
+		res.Actual = fmt.Sprintf("%d records", len(found))
+	}
+	return res
+}
+
+func (b *Baseline) caaCheck(records []cloudflare.DNSRecord, apex string) Result {
+	res := Result{Check: "caa", Expected: "CAA records at " + apex}
+	if len(b.Records.CAAIssuers) > 0 {
+		res.Expected = "CAA issue records for " + strings.Join(b.Records.CAAIssuers, ", ")
+	}
+
+	var count int
+	issuers := map[string]bool{}
+	for _, rec := range records {
+		if rec.Type != "CAA" || !strings.EqualFold(rec.Name, apex) {
+			continue
+		}
+		count++
+		if tag, value := caaValue(rec); tag == "issue" {
+			issuers[strings.ToLower(value)] = true
+		}
+	}
+
+	var missing []string
+	for _, issuer := range b.Records.CAAIssuers {
+		if !issuers[strings.ToLower(issuer)] {
+			missing = append(missing, issuer)
+		}
+	}
+	switch {
+	case count == 0:
+		res.Actual = "missing"
+	case len(missing) > 0:
+		res.Actual = "no issue record for " + strings.Join(missing, ", ")
+	default:
+		res.Actual = fmt.Sprintf("%d records", count)
+		res.OK = true
+	}
+	return res
+}
+
+// caaValue returns the tag and value of a CAA record, from its data when
+// the API returns it and from the content otherwise.
+func caaValue(rec cloudflare.DNSRecord) (tag, value string) {
+	if data, ok := rec.Data.(map[string]interface{}); ok {
+		tag, _ = data["tag"].(string)
+		value, _ = data["value"].(string)
+		return tag, value
+	}
+	fields := strings.Fields(rec.Content)
+	if len(fields) < 3 {
+		return "", ""
+	}
+	return fields[1], strings.Trim(strings.Join(fields[2:], " "), `"`)
+}
+
+// Report is the audit result of one zone.
+type Report struct {
+	Account string   `json:"account"`
+	Zone    string   `json:"zone"`
+	ZoneID  string   `json:"zone_id"`
+	Error   string   `json:"error,omitempty"`
+	Results []Result `json:"checks"`
+}
+
+// OK reports whether the zone could be audited and passed every check.
+func (r Report) OK() bool {
+	return r.Error == "" && len(r.Failed()) == 0
+}
+
+// Failed returns the checks that did not pass.
+func (r Report) Failed() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if !res.OK {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
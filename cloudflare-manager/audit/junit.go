@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the reports as JUnit XML: one test suite per zone and
+// one test case per check. A zone that could not be audited gets a single
+// errored case.
+func WriteJUnit(w io.Writer, reports []Report) error {
+	doc := junitSuites{Name: "cfm zone audit"}
+	for _, r := range reports {
+		suite := junitSuite{Name: r.Account + "/" + r.Zone}
+		if r.Error != "" {
+			suite.Cases = append(suite.Cases, junitCase{Name: "audit", ClassName: suite.Name, Error: &junitMessage{Message: r.Error}})
+			suite.Errors++
+		}
+		for _, res := range r.Results {
+			c := junitCase{Name: res.Check, ClassName: suite.Name}
+			if !res.OK {
+				c.Failure = &junitMessage{Message: fmt.Sprintf("expected %s, got %s", res.Expected, res.Actual)}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Tests = len(suite.Cases)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -3,9 +3,12 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/cloudflare-manager/config"
 	"github.com/cloudflare/cloudflare-go"
+	"golang.org/x/time/rate"
 )
 
 type Client struct {
@@ -15,7 +18,39 @@ type Client struct {
 }
 
 func New(account *config.Account) (*Client, error) {
-	api, err := cloudflare.NewWithAPIToken(account.APIToken)
+	if err := account.LoadSecret(); err != nil {
+		return nil, fmt.Errorf("failed to load %s for %s: %w", account.AuthDescription(), account.Name, err)
+	}
+
+	opts, limiter := currentOptions()
+	httpClient := &http.Client{
+		Transport: &retryTransport{
+			base:    http.DefaultTransport,
+			opts:    opts,
+			limiter: limiter,
+		},
+	}
+	// Retries and rate limiting are handled by retryTransport, so the
+	// library's own policy is switched off.
+	apiOpts := []cloudflare.Option{
+		cloudflare.HTTPClient(httpClient),
+		cloudflare.UsingRetryPolicy(0, 0, 0),
+		cloudflare.UsingRateLimit(float64(rate.Inf)),
+	}
+	if account.BaseURL != "" {
+		apiOpts = append(apiOpts, cloudflare.BaseURL(strings.TrimSuffix(account.BaseURL, "/")))
+	}
+
+	var api *cloudflare.API
+	var err error
+	if account.UsesAPIKey() {
+		if account.Email == "" {
+			return nil, fmt.Errorf("account %s uses a Global API Key but has no email", account.Name)
+		}
+		api, err = cloudflare.New(account.APIKey, account.Email, apiOpts...)
+	} else {
+		api, err = cloudflare.NewWithAPIToken(account.APIToken, apiOpts...)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Cloudflare API client: %w", err)
 	}
@@ -23,17 +58,12 @@ func New(account *config.Account) (*Client, error) {
 	return &Client{
 		API:     api,
 		Account: account,
-		Context: context.Background(),
+		Context: opts.Context,
 	}, nil
 }
 
 func NewFromConfig() (*Client, error) {
-	cfg, err := config.Load()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
-	}
-
-	account, err := cfg.GetCurrentAccount()
+	account, err := config.ActiveAccount()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current account: %w", err)
 	}
@@ -41,20 +71,60 @@ func NewFromConfig() (*Client, error) {
 	return New(account)
 }
 
+// ListAccounts returns every account the credentials can access.
+func (c *Client) ListAccounts() ([]cloudflare.Account, error) {
+	var all []cloudflare.Account
+	params := cloudflare.AccountsListParams{
+		PaginationOptions: cloudflare.PaginationOptions{Page: 1, PerPage: 50},
+	}
+	for {
+		accounts, info, err := c.API.Accounts(c.Context, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts: %w", err)
+		}
+		all = append(all, accounts...)
+		if len(accounts) < params.PerPage || !info.HasMorePages() {
+			return all, nil
+		}
+		params.Page++
+	}
+}
+
+// GetAccountID returns the configured account ID, or looks it up when the
+// credentials can access exactly one account. It refuses to guess when
+// several are accessible.
 func (c *Client) GetAccountID() (string, error) {
 	if c.Account.AccountID != "" {
 		return c.Account.AccountID, nil
 	}
 
-	accounts, _, err := c.API.Accounts(c.Context, cloudflare.AccountsListParams{})
+	accounts, err := c.ListAccounts()
 	if err != nil {
-		return "", fmt.Errorf("failed to list accounts: %w", err)
+		return "", err
 	}
 
 	if len(accounts) == 0 {
 		return "", fmt.Errorf("no accounts found")
 	}
+	if len(accounts) > 1 {
+		return "", &AmbiguousAccountError{Accounts: accounts}
+	}
 
 	c.Account.AccountID = accounts[0].ID
 	return c.Account.AccountID, nil
 }
+
+// AmbiguousAccountError is returned when no account ID is configured and the
+// credentials can access more than one account.
+type AmbiguousAccountError struct {
+	Accounts []cloudflare.Account
+}
+
+func (e *AmbiguousAccountError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "credentials can access %d accounts; set an account ID (account add --account-id or $%s):", len(e.Accounts), config.EnvAccountID)
+	for _, acc := range e.Accounts {
+		fmt.Fprintf(&b, "\n  %s  %s", acc.ID, acc.Name)
+	}
+	return b.String()
+}
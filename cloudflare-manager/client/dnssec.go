@@ -0,0 +1,47 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// DNSSEC is a zone's DNSSEC state, including the multi-signer settings that
+// cloudflare.ZoneDNSSEC leaves out.
+type DNSSEC struct {
+	cloudflare.ZoneDNSSEC
+	MultiSigner bool `json:"dnssec_multi_signer"`
+	Presigned   bool `json:"dnssec_presigned"`
+}
+
+// DNSSECUpdate changes a zone's DNSSEC settings. Nil fields are left as they
+// are.
+type DNSSECUpdate struct {
+	Status      string `json:"status,omitempty"`
+	MultiSigner *bool  `json:"dnssec_multi_signer,omitempty"`
+	Presigned   *bool  `json:"dnssec_presigned,omitempty"`
+}
+
+// DNSSEC returns the DNSSEC state of a zone.
+func (c *Client) DNSSEC(zoneID string) (DNSSEC, error) {
+	return c.dnssecRequest(http.MethodGet, zoneID, nil)
+}
+
+// UpdateDNSSEC changes the DNSSEC settings of a zone.
+func (c *Client) UpdateDNSSEC(zoneID string, update DNSSECUpdate) (DNSSEC, error) {
+	return c.dnssecRequest(http.MethodPatch, zoneID, update)
+}
+
+func (c *Client) dnssecRequest(method, zoneID string, body interface{}) (DNSSEC, error) {
+	res, err := c.API.Raw(c.Context, method, "/zones/"+zoneID+"/dnssec", body, nil)
+	if err != nil {
+		return DNSSEC{}, err
+	}
+	var d DNSSEC
+	if err := json.Unmarshal(res.Result, &d); err != nil {
+		return DNSSEC{}, fmt.Errorf("failed to parse DNSSEC response: %w", err)
+	}
+	return d, nil
+}
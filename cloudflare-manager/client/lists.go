@@ -0,0 +1,104 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// ZonePages lists the zones of the client's account by page number.
+// cloudflare-go only pages zones internally, reading every page at once.
+func (c *Client) ZonePages() PageFunc[cloudflare.Zone] {
+	return ByPage(50, func(page, perPage int) ([]cloudflare.Zone, cloudflare.ResultInfo, error) {
+		// The API takes at least 5 zones per page.
+		if perPage > 0 && perPage < 5 {
+			perPage = 5
+		}
+		q := url.Values{"page": {strconv.Itoa(page)}}
+		if perPage > 0 {
+			q.Set("per_page", strconv.Itoa(perPage))
+		}
+		if c.Account.AccountID != "" {
+			q.Set("account.id", c.Account.AccountID)
+		}
+		var zones []cloudflare.Zone
+		info, err := c.rawList("/zones?"+q.Encode(), &zones)
+		return zones, info, err
+	})
+}
+
+// KVKeyPages lists the keys of a Workers KV namespace by cursor.
+func (c *Client) KVKeyPages(accountID, namespaceID string) PageFunc[cloudflare.StorageKey] {
+	return ByCursor(1000, func(cursor string, limit int) ([]cloudflare.StorageKey, string, error) {
+		// The API takes at least 10 keys per page.
+		if limit > 0 && limit < 10 {
+			limit = 10
+		}
+		res, err := c.API.ListWorkersKVKeys(c.Context, cloudflare.AccountIdentifier(accountID), cloudflare.ListWorkersKVsParams{
+			NamespaceID: namespaceID,
+			Limit:       limit,
+			Cursor:      cursor,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Result, res.Cursor, nil
+	})
+}
+
+// PagesDeploymentPages lists the deployments of a Pages project by page
+// number, newest first.
+func (c *Client) PagesDeploymentPages(accountID, project string) PageFunc[cloudflare.PagesProjectDeployment] {
+	return ByPage(25, func(page, perPage int) ([]cloudflare.PagesProjectDeployment, cloudflare.ResultInfo, error) {
+		// Setting a page turns off cloudflare-go's own pagination.
+		deployments, info, err := c.API.ListPagesDeployments(c.Context, cloudflare.AccountIdentifier(accountID), cloudflare.ListPagesDeploymentsParams{
+			ProjectName: project,
+			ResultInfo:  cloudflare.ResultInfo{Page: page, PerPage: perPage},
+		})
+		if err != nil {
+			return nil, cloudflare.ResultInfo{}, err
+		}
+		return deployments, *info, nil
+	})
+}
+
+// R2BucketPages lists the R2 buckets of an account by cursor. It calls the
+// API directly because cloudflare.ListR2Buckets drops the cursor.
+func (c *Client) R2BucketPages(accountID string) PageFunc[cloudflare.R2Bucket] {
+	return ByCursor(1000, func(cursor string, limit int) ([]cloudflare.R2Bucket, string, error) {
+		q := url.Values{}
+		if limit > 0 {
+			q.Set("per_page", strconv.Itoa(limit))
+		}
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		path := "/accounts/" + accountID + "/r2/buckets"
+		if len(q) > 0 {
+			path += "?" + q.Encode()
+		}
+		var result cloudflare.R2Buckets
+		info, err := c.rawList(path, &result)
+		return result.Buckets, info.Cursor, err
+	})
+}
+
+// rawList GETs a list endpoint, decodes its result into v and returns the
+// result info.
+func (c *Client) rawList(path string, v interface{}) (cloudflare.ResultInfo, error) {
+	res, err := c.API.Raw(c.Context, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return cloudflare.ResultInfo{}, err
+	}
+	if err := json.Unmarshal(res.Result, v); err != nil {
+		return cloudflare.ResultInfo{}, fmt.Errorf("failed to parse list response: %w", err)
+	}
+	if res.ResultInfo == nil {
+		return cloudflare.ResultInfo{}, nil
+	}
+	return *res.ResultInfo, nil
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Pagination selects how much of a list to read.
+type Pagination struct {
+	// Limit stops after this many items; 0 means no limit.
+	Limit int
+	// All follows every page. Without All or Limit only one page is read.
+	All bool
+	// Cursor is where to start: a page number for page-numbered lists and
+	// an opaque cursor for cursor lists. Empty starts at the beginning.
+	Cursor string
+}
+
+// Page is one page of a list. Next is the cursor of the following page and
+// is empty on the last one.
+type Page[T any] struct {
+	Items []T
+	Next  string
+}
+
+// PageFunc fetches the page at cursor. size is how many items are still
+// wanted, or 0 for the API's default page size.
+type PageFunc[T any] func(cursor string, size int) (Page[T], error)
+
+// Position is where a listing stopped.
+type Position struct {
+	// Count is how many items were read.
+	Count int
+	// More reports whether the list has items that were not read.
+	More bool
+	// Next is the cursor to continue from. It is empty when the list is
+	// exhausted, and also when the limit ended inside a page, since pages
+	// cannot be resumed part-way.
+	Next string
+}
+
+// Paginate reads pages with fetch and passes every item to fn as it
+// arrives, so no more than one page is held at a time. It stops at the end
+// of the list, after p.Limit items, or after the first page when neither
+// p.All nor p.Limit is set.
+func Paginate[T any](p Pagination, fetch PageFunc[T], fn func(T) error) (Position, error) {
+	var pos Position
+	cursor := p.Cursor
+	for {
+		size := 0
+		if p.Limit > 0 {
+			size = p.Limit - pos.Count
+		}
+		page, err := fetch(cursor, size)
+		if err != nil {
+			return pos, err
+		}
+		for _, item := range page.Items {
+			if p.Limit > 0 && pos.Count == p.Limit {
+				pos.More = true
+				return pos, nil
+			}
+			if err := fn(item); err != nil {
+				return pos, err
+			}
+			pos.Count++
+		}
+
+		pos.Next, pos.More = page.Next, page.Next != ""
+		switch {
+		case page.Next == "":
+			return pos, nil
+		case p.Limit > 0 && pos.Count >= p.Limit:
+			return pos, nil
+		case p.Limit == 0 && !p.All:
+			return pos, nil
+		case page.Next == cursor:
+			return pos, fmt.Errorf("the API returned cursor %q twice", cursor)
+		}
+		cursor = page.Next
+	}
+}
+
+// ByPage adapts a page-numbered list. The page size is fixed by the first
+// request, so that page numbers mean the same thing on every page: the
+// wanted size when it fits in maxPerPage and maxPerPage otherwise. A
+// perPage of 0 asks for the API's default.
+func ByPage[T any](maxPerPage int, list func(page, perPage int) ([]T, cloudflare.ResultInfo, error)) PageFunc[T] {
+	perPage := -1
+	return func(cursor string, size int) (Page[T], error) {
+		page := 1
+		if cursor != "" {
+			n, err := strconv.Atoi(cursor)
+			if err != nil || n < 1 {
+				return Page[T]{}, fmt.Errorf("invalid page %q", cursor)
+			}
+			page = n
+		}
+		if perPage < 0 {
+			perPage = min(size, maxPerPage)
+		}
+
+		items, info, err := list(page, perPage)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		next := ""
+		if page < info.TotalPages {
+			next = strconv.Itoa(page + 1)
+		}
+		return Page[T]{Items: items, Next: next}, nil
+	}
+}
+
+// ByCursor adapts a cursor list. The wanted size is capped at maxPerPage;
+// 0 asks for the API's default.
+func ByCursor[T any](maxPerPage int, list func(cursor string, limit int) ([]T, string, error)) PageFunc[T] {
+	return func(cursor string, size int) (Page[T], error) {
+		items, next, err := list(cursor, min(size, maxPerPage))
+		if err != nil {
+			return Page[T]{}, err
+		}
+		return Page[T]{Items: items, Next: next}, nil
+	}
+}
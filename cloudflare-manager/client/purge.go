@@ -0,0 +1,31 @@
+package client
+
+import "net/http"
+
+// MaxPurgeItems is how many files, tags, hosts or prefixes one purge
+// request may contain.
+const MaxPurgeItems = 30
+
+// PurgeFile is a URL to purge. Headers are the request headers that are
+// part of the URL's cache key, e.g. CF-Device-Type or Origin; without them
+// only the default variant is purged.
+type PurgeFile struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// PurgeRequest is a cache purge. Unlike cloudflare.PurgeCacheRequest it
+// can send headers with files.
+type PurgeRequest struct {
+	Everything bool        `json:"purge_everything,omitempty"`
+	Files      []PurgeFile `json:"files,omitempty"`
+	Tags       []string    `json:"tags,omitempty"`
+	Hosts      []string    `json:"hosts,omitempty"`
+	Prefixes   []string    `json:"prefixes,omitempty"`
+}
+
+// PurgeCache purges cached content of a zone.
+func (c *Client) PurgeCache(zoneID string, req PurgeRequest) error {
+	_, err := c.API.Raw(c.Context, http.MethodPost, "/zones/"+zoneID+"/purge_cache", req, nil)
+	return err
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Options controls retries, rate limiting and the context shared by every
+// client created in this process.
+type Options struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// MinRetryDelay and MaxRetryDelay bound the exponential backoff.
+	MinRetryDelay time.Duration
+	MaxRetryDelay time.Duration
+	// RateLimit is the client-side requests per second; 0 disables it.
+	RateLimit float64
+	// Context is used for every request, so cancelling it (Ctrl-C or a
+	// --timeout) aborts in-flight calls.
+	Context context.Context
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxRetries:    4,
+		MinRetryDelay: 500 * time.Millisecond,
+		MaxRetryDelay: 30 * time.Second,
+		RateLimit:     4,
+		Context:       context.Background(),
+	}
+}
+
+var (
+	optionsMu sync.Mutex
+	options   = DefaultOptions()
+	limiter   = newLimiter(options.RateLimit)
+)
+
+// Configure replaces the process-wide client options.
+func Configure(opts Options) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+	options = opts
+	limiter = newLimiter(opts.RateLimit)
+}
+
+func currentOptions() (Options, *rate.Limiter) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	return options, limiter
+}
+
+func newLimiter(rps float64) *rate.Limiter {
+	if rps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	burst := int(rps)
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// retryTransport retries rate-limited and failed requests with exponential
+// backoff and full jitter, honouring Retry-After, and waits on a limiter that
+// is shared by all clients so concurrent goroutines stay under the limit.
+type retryTransport struct {
+	base    http.RoundTripper
+	opts    Options
+	limiter *rate.Limiter
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		// A body that cannot be replayed is only sent once.
+		if attempt >= t.opts.MaxRetries || !retryable(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether a request should be retried. Rate limits and
+// gateway errors mean the request was not processed, so they are retried
+// for every method; other failures only for idempotent methods.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		if resp.StatusCode < 500 {
+			return false
+		}
+	}
+	return req.Method != http.MethodPost
+}
+
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > t.opts.MaxRetryDelay {
+				return t.opts.MaxRetryDelay
+			}
+			return d
+		}
+	}
+
+	ceiling := t.opts.MinRetryDelay << uint(attempt)
+	if ceiling > t.opts.MaxRetryDelay || ceiling <= 0 {
+		ceiling = t.opts.MaxRetryDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		d := time.Until(when)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServer answers each request with the status and headers that respond
+// returns for its attempt number, counting from 1.
+func testServer(t *testing.T, respond func(attempt int, w http.ResponseWriter)) (*httptest.Server, func() int) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		n := atomic.AddInt32(&attempts, 1)
+		respond(int(n), w)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() int { return int(atomic.LoadInt32(&attempts)) }
+}
+
+func testClient(opts Options) *http.Client {
+	return &http.Client{Transport: &retryTransport{
+		base:    http.DefaultTransport,
+		opts:    opts,
+		limiter: newLimiter(0),
+	}}
+}
+
+func fastRetries(n int) Options {
+	return Options{MaxRetries: n, MinRetryDelay: time.Millisecond, MaxRetryDelay: 10 * time.Millisecond}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	srv, attempts := testServer(t, func(attempt int, w http.ResponseWriter) {
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+
+	// The backoff alone would retry within a millisecond.
+	opts := fastRetries(2)
+	opts.MaxRetryDelay = 5 * time.Second
+	start := time.Now()
+	resp, err := testClient(opts).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts() != 2 {
+		t.Errorf("got status %d after %d attempts, want 200 after 2", resp.StatusCode, attempts())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, before Retry-After", elapsed)
+	}
+}
+
+func TestRetryTransportServiceUnavailable(t *testing.T) {
+	srv, attempts := testServer(t, func(attempt int, w http.ResponseWriter) {
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	resp, err := testClient(fastRetries(4)).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts() != 3 {
+		t.Errorf("got status %d after %d attempts, want 200 after 3", resp.StatusCode, attempts())
+	}
+}
+
+func TestRetryTransportPostNotRetried(t *testing.T) {
+	srv, attempts := testServer(t, func(attempt int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	resp, err := testClient(fastRetries(4)).Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || attempts() != 1 {
+		t.Errorf("got status %d after %d attempts, want 500 after 1", resp.StatusCode, attempts())
+	}
+}
+
+func TestRetryTransportBudgetExhausted(t *testing.T) {
+	srv, attempts := testServer(t, func(attempt int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintf(w, "attempt %d", attempt)
+	})
+
+	// A body that can be replayed is resent on every attempt.
+	resp, err := testClient(fastRetries(2)).Post(srv.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway || attempts() != 3 {
+		t.Errorf("got status %d after %d attempts, want 502 after 3", resp.StatusCode, attempts())
+	}
+	if string(body) != "attempt 3" {
+		t.Errorf("got body %q, want the last response", body)
+	}
+}
+
+func TestRetryTransportBudgetExhaustedOnError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	resp, err := testClient(fastRetries(2)).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("request to a closed server succeeded")
+	}
+	if !strings.Contains(err.Error(), "connect") {
+		t.Errorf("got error %v, want the connection error", err)
+	}
+}
+
+func TestRetryTransportCancelledDuringBackoff(t *testing.T) {
+	srv, attempts := testServer(t, func(attempt int, w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	opts := fastRetries(4)
+	opts.MaxRetryDelay = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+
+	start := time.Now()
+	_, err := testClient(opts).Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want the context's", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation took %v to stop the backoff", elapsed)
+	}
+	if attempts() != 1 {
+		t.Errorf("got %d attempts, want 1", attempts())
+	}
+}
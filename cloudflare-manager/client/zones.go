@@ -0,0 +1,198 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// ZoneCacheTTL is how long a cached zone name to ID mapping is trusted
+// before the API is asked again.
+const ZoneCacheTTL = 24 * time.Hour
+
+var zoneIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// zoneCacheMu serialises cache file updates within the process.
+var zoneCacheMu sync.Mutex
+
+type cachedZone struct {
+	ID     string    `json:"id"`
+	Cached time.Time `json:"cached"`
+}
+
+// zoneCache maps zone names to IDs for one account.
+type zoneCache struct {
+	Zones map[string]cachedZone `json:"zones"`
+}
+
+// ZoneID resolves a zone ID, a zone name or a name inside a zone (e.g.
+// api.example.com) to a zone ID. IDs are returned without calling the API;
+// names are looked up in the on-disk cache, then with a name-filtered zone
+// query for the name and each parent domain, limited to the client's account
+// when it has an account ID.
+func (c *Client) ZoneID(identifier string) (string, error) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(identifier)), ".")
+	if IsZoneID(name) {
+		return name, nil
+	}
+
+	candidates := zoneCandidates(name)
+
+	cache := c.loadZoneCache()
+	for _, candidate := range candidates {
+		if z, ok := cache.Zones[candidate]; ok && time.Since(z.Cached) < ZoneCacheTTL {
+			return z.ID, nil
+		}
+	}
+
+	for _, candidate := range candidates {
+		res, err := c.API.ListZonesContext(c.Context, cloudflare.WithZoneFilters(candidate, c.Account.AccountID, ""))
+		if err != nil {
+			return "", fmt.Errorf("failed to look up zone %s: %w", candidate, err)
+		}
+		for _, zone := range res.Result {
+			if zone.Name == candidate {
+				c.updateZoneCache(func(cache *zoneCache) {
+					cache.Zones[zone.Name] = cachedZone{ID: zone.ID, Cached: time.Now()}
+				})
+				return zone.ID, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("zone not found: %s", identifier)
+}
+
+// IsZoneID reports whether s is a zone ID rather than a name.
+func IsZoneID(s string) bool {
+	return zoneIDPattern.MatchString(s)
+}
+
+// zoneCandidates returns name followed by its parent domains, stopping at
+// two labels.
+func zoneCandidates(name string) []string {
+	candidates := []string{name}
+	labels := strings.Split(name, ".")
+	for i := 1; len(labels)-i >= 2; i++ {
+		candidates = append(candidates, strings.Join(labels[i:], "."))
+	}
+	return candidates
+}
+
+// CacheZones replaces the zone cache with a complete zone list.
+func (c *Client) CacheZones(zones []cloudflare.Zone) {
+	now := time.Now()
+	c.updateZoneCache(func(cache *zoneCache) {
+		cache.Zones = map[string]cachedZone{}
+		for _, zone := range zones {
+			cache.Zones[zone.Name] = cachedZone{ID: zone.ID, Cached: now}
+		}
+	})
+}
+
+// RememberZones adds zones to the cache, keeping the entries already there.
+func (c *Client) RememberZones(zones []cloudflare.Zone) {
+	now := time.Now()
+	c.updateZoneCache(func(cache *zoneCache) {
+		for _, zone := range zones {
+			cache.Zones[zone.Name] = cachedZone{ID: zone.ID, Cached: now}
+		}
+	})
+}
+
+// RefreshZoneCache lists every zone of the client's account and rewrites the
+// cache with them.
+func (c *Client) RefreshZoneCache() ([]cloudflare.Zone, error) {
+	res, err := c.API.ListZonesContext(c.Context, cloudflare.WithZoneFilters("", c.Account.AccountID, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+	c.CacheZones(res.Result)
+	return res.Result, nil
+}
+
+// ForgetZone drops a zone from the cache, e.g. after it is deleted.
+func (c *Client) ForgetZone(id string) {
+	c.updateZoneCache(func(cache *zoneCache) {
+		for name, z := range cache.Zones {
+			if z.ID == id {
+				delete(cache.Zones, name)
+			}
+		}
+	})
+}
+
+// zoneCachePath is unique per account, endpoint and credential, so accounts
+// (including ones built from environment variables) never share entries.
+func (c *Client) zoneCachePath() (string, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		c.Account.Name, c.Account.AccountID, c.Account.BaseURL, c.Account.Credential(),
+	}, "\x00")))
+	return filepath.Join(dir, "zones-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadZoneCache returns the cached zones. A missing or unreadable cache is
+// treated as empty.
+func (c *Client) loadZoneCache() *zoneCache {
+	cache := &zoneCache{Zones: map[string]cachedZone{}}
+	path, err := c.zoneCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Zones == nil {
+		cache.Zones = map[string]cachedZone{}
+	}
+	return cache
+}
+
+// updateZoneCache applies fn to the cache and writes it back. Failing to
+// write only costs a lookup next time, so errors are ignored.
+func (c *Client) updateZoneCache(fn func(*zoneCache)) {
+	zoneCacheMu.Lock()
+	defer zoneCacheMu.Unlock()
+
+	path, err := c.zoneCachePath()
+	if err != nil {
+		return
+	}
+	cache := c.loadZoneCache()
+	fn(cache)
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	// Write and rename so concurrent invocations never read a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "zones-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -5,6 +5,7 @@ import (
 
     "github.com/cloudflare-manager/client"
     "github.com/cloudflare-manager/config"
+    "github.com/cloudflare-manager/secrets"
     "github.com/cloudflare-manager/utils"
     "github.com/cloudflare/cloudflare-go"
     "github.com/spf13/cobra"
@@ -23,10 +24,19 @@ var accountAddCmd = &cobra.Command{
     RunE: func(cmd *cobra.Command, args []string) error {
         name := args[0]
         apiToken, _ := cmd.Flags().GetString("token")
+        apiKey, _ := cmd.Flags().GetString("api-key")
         email, _ := cmd.Flags().GetString("email")
+        accountID, _ := cmd.Flags().GetString("account-id")
+        baseURL, _ := cmd.Flags().GetString("base-url")
 
-        if apiToken == "" {
-            return fmt.Errorf("API token is required")
+        if apiToken == "" && apiKey == "" {
+            return fmt.Errorf("an API token (--token) or Global API Key (--api-key) is required")
+        }
+        if apiToken != "" && apiKey != "" {
+            return fmt.Errorf("use either --token or --api-key, not both")
+        }
+        if apiKey != "" && email == "" {
+            return fmt.Errorf("--email is required with --api-key")
         }
 
         cfg, err := config.Load()
@@ -36,20 +46,57 @@ var accountAddCmd = &cobra.Command{
 
         account := config.Account{
             Name:     name,
+            AuthType: config.AuthTypeToken,
             APIToken: apiToken,
             Email:    email,
+            BaseURL:  baseURL,
+        }
+        if apiKey != "" {
+            account.AuthType = config.AuthTypeAPIKey
+            account.APIToken = ""
+            account.APIKey = apiKey
         }
 
         c, err := client.New(&account)
         if err != nil {
-            return fmt.Errorf("failed to verify API token: %w", err)
+            return fmt.Errorf("failed to verify credentials: %w", err)
         }
 
-        accountID, err := c.GetAccountID()
+        accessible, err := c.ListAccounts()
         if err != nil {
-            return fmt.Errorf("failed to get account ID: %w", err)
+            return fmt.Errorf("failed to verify credentials: %w", err)
+        }
+        if len(accessible) == 0 {
+            return fmt.Errorf("credentials cannot access any account")
         }
-        account.AccountID = accountID
+
+        var chosen *cloudflare.Account
+        if accountID != "" {
+            for i := range accessible {
+                if accessible[i].ID == accountID {
+                    chosen = &accessible[i]
+                }
+            }
+            if chosen == nil {
+                return fmt.Errorf("account ID %s is not accessible with these credentials (see 'account accounts')", accountID)
+            }
+        } else if len(accessible) == 1 {
+            chosen = &accessible[0]
+        } else if utils.IsInteractive() {
+            var options []string
+            for _, acc := range accessible {
+                options = append(options, fmt.Sprintf("%s  %s", acc.ID, acc.Name))
+            }
+            i, err := utils.Choose("These credentials can access several accounts:", options)
+            if err != nil {
+                return err
+            }
+            chosen = &accessible[i]
+        } else {
+            return &client.AmbiguousAccountError{Accounts: accessible}
+        }
+        account.AccountID = chosen.ID
+        accountID = chosen.ID
 
         if err := cfg.AddAccount(account); err != nil {
             return err
@@ -69,13 +116,17 @@ var accountListCmd = &cobra.Command{
             return err
         }
 
-        if len(cfg.Accounts) == 0 {
-            fmt.Println("No accounts configured. Use 'account add' to add an account.")
-            return nil
+        type accountView struct {
+            Name      string `json:"name"`
+            Email     string `json:"email,omitempty"`
+            AccountID string `json:"account_id,omitempty"`
+            AuthType  string `json:"auth_type"`
+            Current   bool   `json:"current"`
         }
 
-        headers := []string{"CURRENT", "NAME", "EMAIL", "ACCOUNT_ID"}
+        headers := []string{"CURRENT", "NAME", "EMAIL", "AUTH", "ACCOUNT_ID"}
         var rows [][]string
+        var accounts []accountView
 
         for _, acc := range cfg.Accounts {
             current := " "
@@ -86,12 +137,28 @@ var accountListCmd = &cobra.Command{
                 current,
                 acc.Name,
                 acc.Email,
+                acc.AuthDescription(),
                 acc.AccountID,
             })
+            authType := acc.AuthType
+            if authType == "" {
+                authType = config.AuthTypeToken
+            }
+            accounts = append(accounts, accountView{
+                Name:      acc.Name,
+                Email:     acc.Email,
+                AccountID: acc.AccountID,
+                AuthType:  authType,
+                Current:   acc.Name == cfg.CurrentAccount,
+            })
         }
 
-        utils.PrintTable(headers, rows)
-        return nil
+        return utils.Render(utils.View{
+            Data:    accounts,
+            Headers: headers,
+            Rows:    rows,
+            Empty:   "No accounts configured. Use 'account add' to add an account.",
+        })
     },
 }
 
@@ -144,34 +211,124 @@ var accountInfoCmd = &cobra.Command{
             return err
         }
 
-        accounts, _, err := c.API.Accounts(c.Context, cloudflare.AccountsListParams{})
+        accountID, err := c.GetAccountID()
+        if err != nil {
+            return err
+        }
+
+        acc, _, err := c.API.Account(c.Context, accountID)
         if err != nil {
             return fmt.Errorf("failed to get account info: %w", err)
         }
+        twoFactor := acc.Settings != nil && acc.Settings.EnforceTwoFactor
+
+        return utils.Render(utils.View{
+            Data:    acc,
+            Headers: []string{"NAME", "ID", "TYPE", "ENFORCE_TWO_FACTOR"},
+            Rows:    [][]string{{acc.Name, acc.ID, acc.Type, fmt.Sprintf("%t", twoFactor)}},
+            Text: func() {
+                fmt.Printf("Account Information:\n")
+                fmt.Printf("  Name:    %s\n", acc.Name)
+                fmt.Printf("  ID:      %s\n", acc.ID)
+                fmt.Printf("  Type:    %s\n", acc.Type)
+                fmt.Printf("  2FA:     %s\n", utils.BoolToString(twoFactor))
+            },
+        })
+    },
+}
 
-        if len(accounts) == 0 {
-            return fmt.Errorf("no account found")
+var accountAccountsCmd = &cobra.Command{
+    Use:   "accounts",
+    Short: "List every Cloudflare account the current credentials can access",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        c, err := client.NewFromConfig()
+        if err != nil {
+            return err
         }
 
-        acc := accounts[0]
-        fmt.Printf("Account Information:\n")
-        fmt.Printf("  Name:    %s\n", acc.Name)
-        fmt.Printf("  ID:      %s\n", acc.ID)
-        fmt.Printf("  Type:    %s\n", acc.Type)
-        fmt.Printf("  Status:  %s\n", acc.Settings.EnforceTwoFactor)
+        accounts, err := c.ListAccounts()
+        if err != nil {
+            return err
+        }
+
+        headers := []string{"SELECTED", "ID", "NAME", "TYPE"}
+        var rows [][]string
+
+        for _, acc := range accounts {
+            selected := " "
+            if acc.ID == c.Account.AccountID {
+                selected = "*"
+            }
+            rows = append(rows, []string{
+                selected,
+                acc.ID,
+                acc.Name,
+                acc.Type,
+            })
+        }
+
+        return utils.Render(utils.View{
+            Data:    accounts,
+            Headers: headers,
+            Rows:    rows,
+            Empty:   "No accessible accounts found.",
+        })
+    },
+}
+
+var accountMigrateSecretsCmd = &cobra.Command{
+    Use:   "migrate-secrets",
+    Short: "Move API tokens out of the config file",
+    Long: `Move plaintext API tokens from the config file into a secrets backend and
+replace them with references. Accounts added later are stored in the same
+backend. Tokens already in another backend are moved too, and deleted there
+once the config file is saved.
+
+Backends:
+  keyring  OS keyring (Secret Service, macOS Keychain, Windows Credential Manager)
+  vault    passphrase-encrypted file next to the config file; unlock it with
+           $CFM_VAULT_PASSPHRASE or at the interactive prompt`,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        backend, _ := cmd.Flags().GetString("backend")
+
+        cfg, err := config.Load()
+        if err != nil {
+            return err
+        }
+
+        migrated, err := cfg.MigrateSecrets(backend)
+        if err != nil {
+            return fmt.Errorf("failed to migrate secrets: %w", err)
+        }
 
+        if len(migrated) == 0 {
+            fmt.Printf("No plaintext tokens to migrate. Backend set to '%s'.\n", backend)
+            return nil
+        }
+        for _, name := range migrated {
+            fmt.Printf("✓ Moved token for '%s' to %s\n", name, backend)
+        }
+        if backend == secrets.BackendVault {
+            fmt.Printf("\nVault: %s\n", config.VaultPath())
+        }
         return nil
     },
 }
 
 func init() {
-    accountAddCmd.Flags().StringP("token", "t", "", "Cloudflare API token (required)")
-    accountAddCmd.Flags().StringP("email", "e", "", "Email address (optional)")
-    accountAddCmd.MarkFlagRequired("token")
+    accountAddCmd.Flags().StringP("token", "t", "", "Cloudflare API token")
+    accountAddCmd.Flags().StringP("api-key", "k", "", "Cloudflare Global API Key (requires --email)")
+    accountAddCmd.Flags().StringP("email", "e", "", "Email address (required with --api-key)")
+    accountAddCmd.Flags().String("account-id", "", "Cloudflare account ID to use when the credentials can access several")
+    accountAddCmd.Flags().String("base-url", "", "API endpoint to use instead of https://api.cloudflare.com/client/v4")
+
+    accountMigrateSecretsCmd.Flags().String("backend", secrets.BackendKeyring, "Secrets backend: keyring or vault")
 
     AccountCmd.AddCommand(accountAddCmd)
     AccountCmd.AddCommand(accountListCmd)
     AccountCmd.AddCommand(accountSwitchCmd)
     AccountCmd.AddCommand(accountRemoveCmd)
     AccountCmd.AddCommand(accountInfoCmd)
+    AccountCmd.AddCommand(accountAccountsCmd)
+    AccountCmd.AddCommand(accountMigrateSecretsCmd)
 }
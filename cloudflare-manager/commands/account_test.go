@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/internal/fakecf"
+	"github.com/cloudflare-manager/secrets"
+	"github.com/zalando/go-keyring"
+)
+
+func TestAccountCommands(t *testing.T) {
+	e := newTestEnv(t)
+
+	out := e.mustRun("account", "add", "second", "--token", fakecf.Token, "--base-url", e.api.URL())
+	assertContains(t, out, "Account 'second' added successfully", e.account.ID)
+
+	out = e.mustRun("account", "list")
+	assertContains(t, out, "test", "second")
+
+	e.mustRun("account", "switch", "second")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CurrentAccount != "second" {
+		t.Errorf("current account = %q, want second", cfg.CurrentAccount)
+	}
+
+	out = e.mustRun("account", "info")
+	assertContains(t, out, "Account Information:", "Test Account", e.account.ID)
+
+	out = e.mustRun("account", "accounts")
+	assertContains(t, out, e.account.ID, "Test Account")
+
+	e.mustRun("account", "remove", "second")
+	out = e.mustRun("account", "list", "--template", "{{.Name}}")
+	if out != "test\n" {
+		t.Errorf("account list after remove = %q, want only test", out)
+	}
+
+	msg := e.mustFail("account", "add", "bad", "--token", "wrong-token", "--base-url", e.api.URL())
+	assertContains(t, msg, "failed to verify credentials")
+}
+
+func TestAccountAddRequiresAccountIDForSeveralAccounts(t *testing.T) {
+	e := newTestEnv(t)
+	other := e.api.AddAccount("Other Account")
+
+	msg := e.mustFail("account", "add", "multi", "--token", fakecf.Token, "--base-url", e.api.URL())
+	assertContains(t, msg, other.ID)
+
+	out := e.mustRun("account", "add", "multi", "--token", fakecf.Token, "--base-url", e.api.URL(), "--account-id", other.ID)
+	assertContains(t, out, other.ID)
+}
+
+func TestAccountVerify(t *testing.T) {
+	e := newTestEnv(t)
+
+	out := e.mustRun("account", "verify")
+	assertContains(t, out, "Status:      active", "probed with read-only requests")
+
+	e.api.SetTokenStatus("disabled")
+	e.mustFail("account", "verify")
+}
+
+func TestBaseURLFromEnvironment(t *testing.T) {
+	e := newTestEnv(t)
+	e.api.AddZone(e.account.ID, "example.com")
+
+	t.Setenv(config.EnvAPIToken, fakecf.Token)
+	t.Setenv(config.EnvAccountID, e.account.ID)
+	t.Setenv(config.EnvBaseURL, e.api.URL())
+
+	out := e.mustRun("zone", "list")
+	assertContains(t, out, "example.com")
+}
+
+func TestAccountMigrateSecrets(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv(secrets.EnvVaultPassphrase, "correct horse")
+	e := newTestEnv(t)
+	e.api.AddZone(e.account.ID, "example.com")
+
+	out := e.mustRun("account", "migrate-secrets", "--backend", "keyring")
+	assertContains(t, out, "Moved token for 'test' to keyring")
+	if token, err := keyring.Get("cloudflare-manager", "test"); err != nil || token != fakecf.Token {
+		t.Fatalf("keyring token = %q, %v", token, err)
+	}
+	assertContains(t, e.mustRun("zone", "list"), "example.com")
+
+	// Moving to the vault removes the keyring entry.
+	out = e.mustRun("account", "migrate-secrets", "--backend", "vault")
+	assertContains(t, out, "Moved token for 'test' to vault")
+	if _, err := keyring.Get("cloudflare-manager", "test"); err != keyring.ErrNotFound {
+		t.Errorf("keyring entry left after migrating to the vault: %v", err)
+	}
+	assertContains(t, e.mustRun("zone", "list"), "example.com")
+
+	// Tokens are only resolved for the account in use, so a broken
+	// reference does not get in the way of other accounts.
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Accounts = append(cfg.Accounts, config.Account{Name: "stale", TokenRef: "keyring:stale", BaseURL: e.api.URL()})
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, e.mustRun("zone", "list"), "example.com")
+	msg := e.mustFail("--account", "stale", "zone", "list")
+	assertContains(t, msg, "failed to load API token for stale")
+}
@@ -0,0 +1,378 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+// tokenCapabilities maps the products cfm manages to the permission group
+// names that grant read and edit access to them.
+var tokenCapabilities = []struct {
+	Name  string
+	Read  string
+	Write string
+}{
+	{"Zone", "Zone Read", "Zone Write"},
+	{"DNS", "DNS Read", "DNS Write"},
+	{"Workers", "Workers Scripts Read", "Workers Scripts Write"},
+	{"Pages", "Pages Read", "Pages Write"},
+	{"KV", "Workers KV Storage Read", "Workers KV Storage Write"},
+	{"R2", "Workers R2 Storage Read", "Workers R2 Storage Write"},
+}
+
+const (
+	accessEdit    = "edit"
+	accessRead    = "read"
+	accessNone    = "none"
+	accessUnknown = "unknown"
+)
+
+type tokenReport struct {
+	Account      string                        `json:"account"`
+	AuthType     string                        `json:"auth_type"`
+	TokenID      string                        `json:"token_id,omitempty"`
+	Status       string                        `json:"status"`
+	NotBefore    *time.Time                    `json:"not_before,omitempty"`
+	ExpiresOn    *time.Time                    `json:"expires_on,omitempty"`
+	Policies     []cloudflare.APITokenPolicies `json:"policies,omitempty"`
+	Capabilities map[string]string             `json:"capabilities"`
+	Probed       bool                          `json:"probed"`
+	Warnings     []string                      `json:"warnings,omitempty"`
+	Error        string                        `json:"error,omitempty"`
+}
+
+func (r tokenReport) ok() bool {
+	return r.Error == "" && r.Status == "active"
+}
+
+var accountVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify API tokens and audit their permissions",
+	Long: `Verify the current account's credentials with the token-verify endpoint and
+show the token's status, validity window and permissions.
+
+Permissions are read from the token's policies when the token may read its own
+details; otherwise each product is probed with a read-only request, which can
+confirm read access but not edit access.
+
+With --all every configured account is checked. The command fails if any
+token is invalid or inactive; tokens expiring within --warn-days are flagged.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		warnDays, _ := cmd.Flags().GetInt("warn-days")
+
+		var accounts []config.Account
+		if all {
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			accounts = cfg.Accounts
+			if len(accounts) == 0 {
+				return fmt.Errorf("no accounts configured")
+			}
+		} else {
+			account, err := config.ActiveAccount()
+			if err != nil {
+				return err
+			}
+			accounts = []config.Account{*account}
+		}
+
+		var reports []tokenReport
+		failed := 0
+		for i := range accounts {
+			report := verifyAccount(&accounts[i], time.Duration(warnDays)*24*time.Hour)
+			if !report.ok() {
+				failed++
+			}
+			reports = append(reports, report)
+		}
+
+		if len(reports) == 1 && !all {
+			if err := renderTokenReport(reports[0]); err != nil {
+				return err
+			}
+		} else if err := renderTokenReports(reports); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d accounts failed verification", failed, len(reports))
+		}
+		return nil
+	},
+}
+
+func verifyAccount(account *config.Account, warnWithin time.Duration) tokenReport {
+	report := tokenReport{
+		Account:      account.Name,
+		AuthType:     account.AuthDescription(),
+		Capabilities: map[string]string{},
+	}
+
+	c, err := client.New(account)
+	if err != nil {
+		report.Status = "error"
+		report.Error = err.Error()
+		return report
+	}
+
+	if account.UsesAPIKey() {
+		if _, err := c.API.UserDetails(c.Context); err != nil {
+			report.Status = "invalid"
+			report.Error = err.Error()
+			return report
+		}
+		report.Status = "active"
+		for _, capability := range tokenCapabilities {
+			report.Capabilities[capability.Name] = accessEdit
+		}
+		report.Warnings = append(report.Warnings, "Global API Key has full access; prefer a scoped API token")
+		return report
+	}
+
+	verify, err := c.API.VerifyAPIToken(c.Context)
+	if err != nil {
+		report.Status = "invalid"
+		report.Error = err.Error()
+		return report
+	}
+
+	report.TokenID = verify.ID
+	report.Status = verify.Status
+	if !verify.NotBefore.IsZero() {
+		notBefore := verify.NotBefore
+		report.NotBefore = &notBefore
+	}
+	if !verify.ExpiresOn.IsZero() {
+		expiresOn := verify.ExpiresOn
+		report.ExpiresOn = &expiresOn
+	}
+
+	now := time.Now()
+	if report.Status != "active" {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("token status is %s", report.Status))
+	}
+	if report.NotBefore != nil && report.NotBefore.After(now) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("token not valid before %s", report.NotBefore.Format(time.RFC3339)))
+	}
+	if report.ExpiresOn != nil {
+		if report.ExpiresOn.Before(now) {
+			report.Warnings = append(report.Warnings, "token has expired")
+		} else if report.ExpiresOn.Sub(now) < warnWithin {
+			days := int(report.ExpiresOn.Sub(now).Hours() / 24)
+			report.Warnings = append(report.Warnings, fmt.Sprintf("token expires in %d days", days))
+		}
+	}
+
+	token, err := c.API.GetAPIToken(c.Context, verify.ID)
+	if err == nil {
+		report.Policies = token.Policies
+		for _, capability := range tokenCapabilities {
+			report.Capabilities[capability.Name] = policyAccess(token.Policies, capability.Read, capability.Write)
+		}
+	} else {
+		report.Probed = true
+		probeCapabilities(c, report.Capabilities)
+	}
+
+	for _, capability := range tokenCapabilities {
+		if report.Capabilities[capability.Name] != accessEdit && report.Capabilities[capability.Name] != accessUnknown {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("cannot edit %s", capability.Name))
+		}
+	}
+
+	return report
+}
+
+// policyAccess evaluates allow and deny policies for one product. Resource
+// scoping is not taken into account.
+func policyAccess(policies []cloudflare.APITokenPolicies, read, write string) string {
+	allowed := map[string]bool{}
+	denied := map[string]bool{}
+	for _, policy := range policies {
+		for _, group := range policy.PermissionGroups {
+			if policy.Effect == "deny" {
+				denied[group.Name] = true
+			} else {
+				allowed[group.Name] = true
+			}
+		}
+	}
+
+	switch {
+	case allowed[write] && !denied[write]:
+		return accessEdit
+	case (allowed[read] || allowed[write]) && !denied[read]:
+		return accessRead
+	}
+	return accessNone
+}
+
+// probeCapabilities is used when the token cannot read its own policies. A
+// successful read-only call proves read access; edit access stays unknown.
+func probeCapabilities(c *client.Client, capabilities map[string]string) {
+	result := func(err error) string {
+		if err == nil {
+			return accessRead
+		}
+		var authz *cloudflare.AuthorizationError
+		var authn *cloudflare.AuthenticationError
+		if errors.As(err, &authz) || errors.As(err, &authn) {
+			return accessNone
+		}
+		return accessUnknown
+	}
+
+	zones, err := c.API.ListZonesContext(c.Context, cloudflare.WithPagination(cloudflare.PaginationOptions{Page: 1, PerPage: 5}))
+	capabilities["Zone"] = result(err)
+	if err == nil && len(zones.Result) > 0 {
+		_, _, err = c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zones.Result[0].ID), cloudflare.ListDNSRecordsParams{
+			ResultInfo: cloudflare.ResultInfo{Page: 1, PerPage: 5},
+		})
+		capabilities["DNS"] = result(err)
+	} else {
+		capabilities["DNS"] = accessUnknown
+	}
+
+	accountID, err := c.GetAccountID()
+	if err != nil {
+		for _, name := range []string{"Workers", "Pages", "KV", "R2"} {
+			capabilities[name] = accessUnknown
+		}
+		return
+	}
+	rc := cloudflare.AccountIdentifier(accountID)
+
+	_, _, err = c.API.ListWorkers(c.Context, rc, cloudflare.ListWorkersParams{})
+	capabilities["Workers"] = result(err)
+	_, _, err = c.API.ListPagesProjects(c.Context, rc, cloudflare.ListPagesProjectsParams{
+		PaginationOptions: cloudflare.PaginationOptions{Page: 1, PerPage: 5},
+	})
+	capabilities["Pages"] = result(err)
+	_, _, err = c.API.ListWorkersKVNamespaces(c.Context, rc, cloudflare.ListWorkersKVNamespacesParams{
+		ResultInfo: cloudflare.ResultInfo{Page: 1, PerPage: 5},
+	})
+	capabilities["KV"] = result(err)
+	_, err = c.API.ListR2Buckets(c.Context, rc, cloudflare.ListR2BucketsParams{})
+	capabilities["R2"] = result(err)
+}
+
+func renderTokenReport(r tokenReport) error {
+	return utils.Render(utils.View{
+		Data:    r,
+		Headers: tokenReportHeaders(),
+		Rows:    [][]string{tokenReportRow(r)},
+		Text: func() {
+			fmt.Printf("Credential Verification:\n")
+			fmt.Printf("  Account:     %s\n", r.Account)
+			fmt.Printf("  Auth:        %s\n", r.AuthType)
+			if r.TokenID != "" {
+				fmt.Printf("  Token ID:    %s\n", r.TokenID)
+			}
+			fmt.Printf("  Status:      %s\n", r.Status)
+			fmt.Printf("  Not Before:  %s\n", formatOptionalTime(r.NotBefore))
+			fmt.Printf("  Expires On:  %s\n", formatOptionalTime(r.ExpiresOn))
+			if r.Error != "" {
+				fmt.Printf("  Error:       %s\n", r.Error)
+				return
+			}
+
+			if len(r.Policies) > 0 {
+				fmt.Printf("\nPolicies:\n")
+				for _, policy := range r.Policies {
+					var groups []string
+					for _, group := range policy.PermissionGroups {
+						groups = append(groups, group.Name)
+					}
+					fmt.Printf("  [%s] %s\n", policy.Effect, strings.Join(groups, ", "))
+					resources := make([]string, 0, len(policy.Resources))
+					for resource, scope := range policy.Resources {
+						resources = append(resources, fmt.Sprintf("%s = %v", resource, scope))
+					}
+					sort.Strings(resources)
+					for _, resource := range resources {
+						fmt.Printf("      %s\n", resource)
+					}
+				}
+			}
+
+			fmt.Printf("\nCapabilities:")
+			if r.Probed {
+				fmt.Printf(" (probed with read-only requests; token cannot read its own policies)")
+			}
+			fmt.Println()
+			for _, capability := range tokenCapabilities {
+				fmt.Printf("  %-8s %s\n", capability.Name+":", r.Capabilities[capability.Name])
+			}
+
+			if len(r.Warnings) > 0 {
+				fmt.Printf("\nWarnings:\n")
+				for _, warning := range r.Warnings {
+					fmt.Printf("  ⚠  %s\n", warning)
+				}
+			}
+		},
+	})
+}
+
+func renderTokenReports(reports []tokenReport) error {
+	var rows [][]string
+	for _, r := range reports {
+		rows = append(rows, tokenReportRow(r))
+	}
+	return utils.Render(utils.View{
+		Data:     reports,
+		Headers:  tokenReportHeaders(),
+		Rows:     rows,
+		MaxWidth: map[string]int{"WARNINGS": 50},
+	})
+}
+
+func tokenReportHeaders() []string {
+	headers := []string{"ACCOUNT", "AUTH", "STATUS", "EXPIRES"}
+	for _, capability := range tokenCapabilities {
+		headers = append(headers, strings.ToUpper(capability.Name))
+	}
+	return append(headers, "WARNINGS")
+}
+
+func tokenReportRow(r tokenReport) []string {
+	row := []string{r.Account, r.AuthType, r.Status, formatOptionalTime(r.ExpiresOn)}
+	for _, capability := range tokenCapabilities {
+		access := r.Capabilities[capability.Name]
+		if access == "" {
+			access = "-"
+		}
+		row = append(row, access)
+	}
+	notes := r.Warnings
+	if r.Error != "" {
+		notes = append([]string{r.Error}, notes...)
+	}
+	return append(row, strings.Join(notes, "; "))
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func init() {
+	accountVerifyCmd.Flags().Bool("all", false, "Verify every configured account")
+	accountVerifyCmd.Flags().Int("warn-days", 14, "Flag tokens that expire within this many days")
+
+	AccountCmd.AddCommand(accountVerifyCmd)
+}
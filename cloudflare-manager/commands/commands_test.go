@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/internal/fakecf"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// testEnv runs cfm commands in-process against a fake Cloudflare API, with a
+// throwaway config holding one account whose base_url points at the fake.
+type testEnv struct {
+	t          *testing.T
+	api        *fakecf.Server
+	account    cloudflare.Account
+	configPath string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	// Keep the developer's real credentials and config out of the tests.
+	for _, env := range []string{config.EnvConfig, config.EnvAccount, config.EnvAPIToken, config.EnvAPIKey, config.EnvEmail, config.EnvAccountID, config.EnvBaseURL} {
+		t.Setenv(env, "")
+	}
+	t.Setenv(config.EnvCacheDir, t.TempDir())
+	t.Setenv(config.EnvStateDir, t.TempDir())
+
+	api := fakecf.New(t)
+	e := &testEnv{
+		t:          t,
+		api:        api,
+		account:    api.AddAccount("Test Account"),
+		configPath: filepath.Join(t.TempDir(), "config.yaml"),
+	}
+
+	config.SetConfigPath(e.configPath)
+	cfg := &config.Config{
+		CurrentAccount: "test",
+		Accounts: []config.Account{{
+			Name:      "test",
+			AuthType:  config.AuthTypeToken,
+			APIToken:  fakecf.Token,
+			AccountID: e.account.ID,
+			BaseURL:   api.URL(),
+		}},
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return e
+}
+
+// addAccount creates another fake account and saves it in the config under
+// name, using the same token and API as the default one.
+func (e *testEnv) addAccount(name string) cloudflare.Account {
+	e.t.Helper()
+	acct := e.api.AddAccount(name)
+	cfg, err := config.Load()
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	cfg.Accounts = append(cfg.Accounts, config.Account{
+		Name:      name,
+		AuthType:  config.AuthTypeToken,
+		APIToken:  fakecf.Token,
+		AccountID: acct.ID,
+		BaseURL:   e.api.URL(),
+	})
+	if err := cfg.Save(); err != nil {
+		e.t.Fatalf("failed to write config: %v", err)
+	}
+	return acct
+}
+
+// run executes cfm with args and returns what it printed to stdout.
+func (e *testEnv) run(args ...string) (string, error) {
+	e.t.Helper()
+	resetFlags(RootCmd)
+
+	args = append([]string{"--config", e.configPath, "--rate-limit", "0", "--max-retries", "0"}, args...)
+	RootCmd.SetArgs(args)
+	RootCmd.SetOut(io.Discard)
+	RootCmd.SetErr(io.Discard)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+
+	err = Execute(context.Background())
+
+	w.Close()
+	os.Stdout = stdout
+	return <-done, err
+}
+
+// mustRun is run for commands that are expected to succeed.
+func (e *testEnv) mustRun(args ...string) string {
+	e.t.Helper()
+	out, err := e.run(args...)
+	if err != nil {
+		e.t.Fatalf("cfm %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// mustFail is run for commands that are expected to fail and returns the
+// error message.
+func (e *testEnv) mustFail(args ...string) string {
+	e.t.Helper()
+	out, err := e.run(args...)
+	if err == nil {
+		e.t.Fatalf("cfm %s succeeded, want an error\n%s", strings.Join(args, " "), out)
+	}
+	return err.Error()
+}
+
+// resetFlags restores every flag to its default, since cobra keeps flag
+// values on the command tree between executions.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if _, ok := f.Value.(pflag.SliceValue); ok {
+			// Slice values remember that they were set and append from then
+			// on, even after Replace, so they get a fresh value instead.
+			var def []string
+			if v := strings.Trim(f.DefValue, "[]"); v != "" {
+				def = strings.Split(v, ",")
+			}
+			fresh := pflag.NewFlagSet(f.Name, pflag.ContinueOnError)
+			switch f.Value.Type() {
+			case "stringSlice":
+				fresh.StringSlice(f.Name, def, f.Usage)
+			case "stringArray":
+				fresh.StringArray(f.Name, def, f.Usage)
+			default:
+				panic("resetFlags: unsupported flag type " + f.Value.Type())
+			}
+			f.Value = fresh.Lookup(f.Name).Value
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
+
+func assertContains(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output does not contain %q:\n%s", w, out)
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setStdin makes content the standard input until the test finishes.
+func setStdin(t *testing.T, content string) {
+	t.Helper()
+	f, err := os.Open(writeFile(t, "stdin", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
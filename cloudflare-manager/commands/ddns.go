@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/ddns"
+	"github.com/spf13/cobra"
+)
+
+var DDNSCmd = &cobra.Command{
+	Use:   "ddns",
+	Short: "Dynamic DNS",
+	Long:  "Keep A/AAAA records pointed at this machine's public IP addresses",
+}
+
+var ddnsRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the dynamic DNS updater",
+	Long: `Detect the public IPv4 and/or IPv6 address and update the A/AAAA records
+listed in the ddns config file whenever they differ. Runs until interrupted,
+checking every interval; failed passes are retried with jittered backoff.
+Logs are written to stderr.
+
+Example ddns.yaml:
+
+  interval: 5m
+  ipv4:
+    urls: [https://api.ipify.org, https://ipv4.icanhazip.com]
+  ipv6:
+    interface: eth0          # or: command: "ip -6 addr show ..."
+  records:
+    - zone: example.com
+      name: home
+      ttl: 120
+      proxied: false`,
+	Example: `  cfm ddns run -f ddns.yaml
+  cfm ddns run -f ddns.yaml --once --log-format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		once, _ := cmd.Flags().GetBool("once")
+		logFormat, _ := cmd.Flags().GetString("log-format")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		cfg, err := ddns.LoadConfig(file)
+		if err != nil {
+			return fmt.Errorf("failed to load ddns config: %w", err)
+		}
+
+		level := slog.LevelInfo
+		if verbose {
+			level = slog.LevelDebug
+		}
+		handlerOpts := &slog.HandlerOptions{Level: level}
+		var handler slog.Handler
+		switch logFormat {
+		case "text":
+			handler = slog.NewTextHandler(cmd.ErrOrStderr(), handlerOpts)
+		case "json":
+			handler = slog.NewJSONHandler(cmd.ErrOrStderr(), handlerOpts)
+		default:
+			return fmt.Errorf("unknown log format %q (use text or json)", logFormat)
+		}
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		updater := ddns.New(c, cfg, slog.New(handler))
+		if once {
+			return updater.Sync(cmd.Context())
+		}
+		return updater.Run(cmd.Context())
+	},
+}
+
+func init() {
+	ddnsRunCmd.Flags().StringP("file", "f", "ddns.yaml", "ddns config file")
+	ddnsRunCmd.Flags().Bool("once", false, "Run a single update pass and exit")
+	ddnsRunCmd.Flags().String("log-format", "text", "Log format: text or json")
+	ddnsRunCmd.Flags().BoolP("verbose", "v", false, "Also log unchanged addresses and records")
+
+	DDNSCmd.AddCommand(ddnsRunCmd)
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDDNSRun(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "home", Content: "192.0.2.1", TTL: 300})
+
+	var address atomic.Value
+	address.Store("198.51.100.7")
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := address.Load().(string)
+		if ip == "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, ip)
+	}))
+	defer echo.Close()
+
+	file := writeFile(t, "ddns.yaml", fmt.Sprintf(`
+interval: 1m
+ipv4:
+  urls: [%s]
+ipv6:
+  command: echo 2001:db8::7
+records:
+  - zone: example.com
+    name: home
+  - zone: example.com
+    name: vpn.example.com.
+    ttl: 120
+`, echo.URL))
+
+	e.mustRun("ddns", "run", "-f", file, "--once")
+
+	records := recordsByName(e.api.DNSRecords(zone.ID))
+	for key, want := range map[string]string{
+		"A home.example.com":    "198.51.100.7",
+		"AAAA home.example.com": "2001:db8::7",
+		"A vpn.example.com":     "198.51.100.7",
+		"AAAA vpn.example.com":  "2001:db8::7",
+	} {
+		if records[key].Content != want {
+			t.Errorf("%s = %q, want %q", key, records[key].Content, want)
+		}
+	}
+	if records["A home.example.com"].TTL != 300 {
+		t.Errorf("home TTL = %d, want the existing 300 kept", records["A home.example.com"].TTL)
+	}
+	if records["A vpn.example.com"].TTL != 120 {
+		t.Errorf("vpn TTL = %d, want 120", records["A vpn.example.com"].TTL)
+	}
+
+	// Records that already match are left alone.
+	before := len(e.api.Requests())
+	e.mustRun("ddns", "run", "-f", file, "--once")
+	for _, req := range e.api.Requests()[before:] {
+		if req[:4] != "GET " {
+			t.Errorf("unexpected write when nothing changed: %s", req)
+		}
+	}
+
+	address.Store("")
+	msg := e.mustFail("ddns", "run", "-f", file, "--once")
+	assertContains(t, msg, "failed to detect ipv4 address", "503")
+}
+
+func TestDDNSConfigErrors(t *testing.T) {
+	e := newTestEnv(t)
+
+	msg := e.mustFail("ddns", "run", "-f", writeFile(t, "ddns.yaml", "ipv4: {}\n"), "--once")
+	assertContains(t, msg, "no records configured")
+
+	msg = e.mustFail("ddns", "run", "-f", writeFile(t, "ddns.yaml", `
+ipv4:
+  interface: eth0
+  command: echo 192.0.2.1
+records:
+  - zone: example.com
+    name: home
+`), "--once")
+	assertContains(t, msg, "ipv4: set only one of urls, interface or command")
+
+	msg = e.mustFail("ddns", "run", "-f", writeFile(t, "ddns.yaml", `
+ipv4:
+  command: echo 2001:db8::1
+records:
+  - zone: example.com
+    name: home
+`), "--once")
+	assertContains(t, msg, "2001:db8::1 is not an ipv4 address")
+}
+
+// recordsByName keys records by "TYPE name".
+func recordsByName(records []cloudflare.DNSRecord) map[string]cloudflare.DNSRecord {
+	byName := map[string]cloudflare.DNSRecord{}
+	for _, rec := range records {
+		byName[rec.Type+" "+rec.Name] = rec
+	}
+	return byName
+}
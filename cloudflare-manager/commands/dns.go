@@ -2,10 +2,13 @@ package commands
 
 import (
     "fmt"
+    "os"
     "strings"
 
     "github.com/cloudflare-manager/client"
     "github.com/cloudflare-manager/utils"
+    "github.com/cloudflare-manager/validate"
+    "github.com/cloudflare-manager/zonefile"
     "github.com/cloudflare/cloudflare-go"
     "github.com/spf13/cobra"
 )
@@ -44,11 +47,6 @@ var dnsListCmd = &cobra.Command{
             return fmt.Errorf("failed to list DNS records: %w", err)
         }
 
-        if len(records) == 0 {
-            fmt.Println("No DNS records found.")
-            return nil
-        }
-
         headers := []string{"TYPE", "NAME", "CONTENT", "TTL", "PROXIED", "ID"}
         var rows [][]string
 
@@ -57,35 +55,114 @@ var dnsListCmd = &cobra.Command{
             if record.Proxied != nil && *record.Proxied {
                 proxied = "✓"
             }
+            if !utils.IsTableOutput() {
+                proxied = fmt.Sprintf("%t", record.Proxied != nil && *record.Proxied)
+            }
+
+            content := record.Content
+            if zonefile.Structured(record.Type) {
+                content = recordContent(record.Type, record.Content, record.Priority, record.Data)
+            }
 
             rows = append(rows, []string{
                 record.Type,
                 record.Name,
-                utils.Truncate(record.Content, 40),
+                content,
                 fmt.Sprintf("%d", record.TTL),
                 proxied,
-                utils.Truncate(record.ID, 12),
+                record.ID,
             })
         }
 
-        utils.PrintTable(headers, rows)
-        return nil
+        return utils.Render(utils.View{
+            Data:     records,
+            Headers:  headers,
+            Rows:     rows,
+            MaxWidth: map[string]int{"CONTENT": 40, "ID": 12},
+            Empty:    "No DNS records found.",
+        })
     },
 }
 
 var dnsCreateCmd = &cobra.Command{
     Use:   "create [zone-id or domain] [type] [name] [content]",
     Short: "Create a DNS record",
-    Args:  cobra.ExactArgs(4),
+    Long: `Create a DNS record.
+
+SRV, CAA, TLSA, HTTPS, SVCB, URI and LOC records are structured: give their
+value in zone file format as the content, or field by field with --data
+key=value (which overrides fields of the content). The fields are:
+
+  SRV    priority weight port target  (or --data service=_sip --data proto=_tcp)
+  CAA    flags tag value
+  TLSA   usage selector matching_type certificate
+  HTTPS  priority target [value]
+  SVCB   priority target [value]
+  URI    priority weight target
+  LOC    lat_degrees lat_minutes lat_seconds lat_direction long_degrees
+         long_minutes long_seconds long_direction altitude size
+         precision_horz precision_vert`,
+    Example: `  cfm dns create example.com A www 192.0.2.1 --proxied --comment "web frontend" --tag env:prod
+  cfm dns create example.com MX @ mail.example.com --priority 10
+  cfm dns create example.com SRV _sip._tcp "10 5 5060 sip.example.com"
+  cfm dns create example.com SRV @ --data service=_sip --data proto=_tcp --data weight=5 --data port=5060 --data target=sip.example.com
+  cfm dns create example.com CAA @ '0 issue "letsencrypt.org"'
+  cfm dns create example.com HTTPS @ '1 . alpn="h3,h2" ipv4hint="192.0.2.1"'
+  cfm dns create example.com TLSA _443._tcp.www "3 1 1 0123abcd"
+  cfm dns create example.com LOC @ "51 30 12.748 N 0 7 39.611 W 0m"
+  cfm dns create example.com URI _ftp._tcp '10 1 "ftp://ftp.example.com/public"'`,
+    Args: cobra.RangeArgs(3, 4),
     RunE: func(cmd *cobra.Command, args []string) error {
         zoneIdentifier := args[0]
         recordType := strings.ToUpper(args[1])
         name := args[2]
-        content := args[3]
+        content := ""
+        if len(args) > 3 {
+            content = args[3]
+        }
 
         ttl, _ := cmd.Flags().GetInt("ttl")
         proxied, _ := cmd.Flags().GetBool("proxied")
         priority, _ := cmd.Flags().GetInt("priority")
+        comment, _ := cmd.Flags().GetString("comment")
+        tags, _ := cmd.Flags().GetStringSlice("tag")
+        pairs, _ := cmd.Flags().GetStringArray("data")
+
+        params := cloudflare.CreateDNSRecordParams{
+            Type:    recordType,
+            Name:    name,
+            Content: content,
+            TTL:     ttl,
+            Proxied: &proxied,
+            Comment: comment,
+            Tags:    tags,
+        }
+
+        if zonefile.Structured(recordType) {
+            var defaultPriority *uint16
+            if recordType == "SRV" || recordType == "URI" || cmd.Flags().Changed("priority") {
+                p := uint16(priority)
+                defaultPriority = &p
+            }
+            data, recordName, err := recordData(recordType, name, content, pairs, nil, defaultPriority)
+            if err != nil {
+                return err
+            }
+            params.Name = recordName
+            params.Content = ""
+            params.Data, params.Priority = zonefile.SplitPriority(recordType, data)
+        } else {
+            if len(pairs) > 0 {
+                return fmt.Errorf("%s records take their value as content, not --data", recordType)
+            }
+            if content == "" {
+                return fmt.Errorf("%s record needs content", recordType)
+            }
+            if recordType == "MX" {
+                p := uint16(priority)
+                params.Priority = &p
+            }
+        }
 
         c, err := client.NewFromConfig()
         if err != nil {
@@ -97,17 +174,12 @@ var dnsCreateCmd = &cobra.Command{
             return err
         }
 
-        params := cloudflare.CreateDNSRecordParams{
-            Type:    recordType,
-            Name:    name,
-            Content: content,
-            TTL:     ttl,
-            Proxied: &proxied,
+        if err := checkRecordChange(cmd, c, zoneID, validate.FromParams(params), ""); err != nil {
+            return err
         }
 
-        if recordType == "MX" || recordType == "SRV" {
-            p := uint16(priority)
-            params.Priority = &p
+        if err := autoSnapshot(cmd, c, zoneID); err != nil {
+            return err
         }
 
         record, err := c.API.CreateDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), params)
@@ -119,7 +191,7 @@ var dnsCreateCmd = &cobra.Command{
         fmt.Printf("  ID:      %s\n", record.ID)
         fmt.Printf("  Type:    %s\n", record.Type)
         fmt.Printf("  Name:    %s\n", record.Name)
-        fmt.Printf("  Content: %s\n", record.Content)
+        fmt.Printf("  Content: %s\n", recordContent(record.Type, record.Content, record.Priority, record.Data))
         return nil
     },
 }
@@ -127,14 +199,26 @@ var dnsCreateCmd = &cobra.Command{
 var dnsUpdateCmd = &cobra.Command{
     Use:   "update [zone-id or domain] [record-id] [content]",
     Short: "Update a DNS record",
-    Args:  cobra.ExactArgs(3),
+    Long: `Update a DNS record's content, TTL and proxy status.
+
+The content may be left out to change only other fields, such as --comment
+or --tag. Structured records (SRV, CAA, TLSA, HTTPS, SVCB, URI, LOC) take new
+content in zone file format and/or individual fields with --data key=value;
+fields not given keep their current values. The TTL and proxy status are
+kept too unless --ttl or --proxied is given.`,
+    Example: `  cfm dns update example.com <record-id> 192.0.2.2 --ttl 300
+  cfm dns update example.com <record-id> --data port=5061
+  cfm dns update example.com <record-id> --comment "managed by ops" --tag team:ops`,
+    Args: cobra.RangeArgs(2, 3),
     RunE: func(cmd *cobra.Command, args []string) error {
         zoneIdentifier := args[0]
         recordID := args[1]
-        content := args[2]
+        content := ""
+        if len(args) > 2 {
+            content = args[2]
+        }
 
-        ttl, _ := cmd.Flags().GetInt("ttl")
-        proxied, _ := cmd.Flags().GetBool("proxied")
+        pairs, _ := cmd.Flags().GetStringArray("data")
 
         c, err := client.NewFromConfig()
         if err != nil {
@@ -151,13 +235,67 @@ var dnsUpdateCmd = &cobra.Command{
             return fmt.Errorf("failed to get DNS record: %w", err)
         }
 
+        ttl := record.TTL
+        if cmd.Flags().Changed("ttl") {
+            ttl, _ = cmd.Flags().GetInt("ttl")
+        }
+        proxied := record.Proxied != nil && *record.Proxied
+        if cmd.Flags().Changed("proxied") {
+            proxied, _ = cmd.Flags().GetBool("proxied")
+        }
+
         params := cloudflare.UpdateDNSRecordParams{
-            ID:      recordID,
-            Type:    record.Type,
-            Name:    record.Name,
-            Content: content,
-            TTL:     ttl,
-            Proxied: &proxied,
+            ID:       recordID,
+            Type:     record.Type,
+            Name:     record.Name,
+            Content:  record.Content,
+            TTL:      ttl,
+            Proxied:  &proxied,
+            Priority: record.Priority,
+            Tags:     record.Tags,
+        }
+        if content != "" {
+            params.Content = content
+        }
+        if cmd.Flags().Changed("comment") {
+            comment, _ := cmd.Flags().GetString("comment")
+            params.Comment = &comment
+        }
+        if cmd.Flags().Changed("tag") {
+            params.Tags, _ = cmd.Flags().GetStringSlice("tag")
+        }
+
+        updated := validate.FromDNSRecord(record)
+        if zonefile.Structured(record.Type) {
+            current, _ := record.Data.(map[string]interface{})
+            if current != nil && record.Priority != nil {
+                if _, ok := current["priority"]; !ok {
+                    current["priority"] = int(*record.Priority)
+                }
+            }
+            data, _, err := recordData(record.Type, record.Name, content, pairs, current, record.Priority)
+            if err != nil {
+                return err
+            }
+            params.Content = ""
+            params.Data, params.Priority = zonefile.SplitPriority(record.Type, data)
+            updated.Content = ""
+            updated.Data = data
+        } else {
+            if len(pairs) > 0 {
+                return fmt.Errorf("%s records take their value as content, not --data", record.Type)
+            }
+            updated.Content = params.Content
+            updated.Data = nil
+        }
+        updated.TTL = ttl
+        updated.Proxied = proxied
+        if err := checkRecordChange(cmd, c, zoneID, updated, record.ID); err != nil {
+            return err
+        }
+
+        if err := autoSnapshot(cmd, c, zoneID); err != nil {
+            return err
         }
 
         _, err = c.API.UpdateDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), params)
@@ -188,6 +326,10 @@ var dnsDeleteCmd = &cobra.Command{
             return err
         }
 
+        if err := autoSnapshot(cmd, c, zoneID); err != nil {
+            return err
+        }
+
         if err := c.API.DeleteDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), recordID); err != nil {
             return fmt.Errorf("failed to delete DNS record: %w", err)
         }
@@ -200,10 +342,138 @@ var dnsDeleteCmd = &cobra.Command{
 var dnsImportCmd = &cobra.Command{
     Use:   "import [zone-id or domain] [bind-file]",
     Short: "Import DNS records from BIND file",
-    Args:  cobra.ExactArgs(2),
+    Long: `Import DNS records from an RFC 1035 zone file.
+
+$ORIGIN, $TTL, relative names, multi-line records in parentheses and quoted
+TXT chunks are supported. SOA records and NS records at the zone apex are
+skipped. With --dry-run the file is parsed against the zone name (or --origin)
+and no records are sent to the API; given a zone ID, the zone is looked up for
+its name.`,
+    Args: cobra.ExactArgs(2),
     RunE: func(cmd *cobra.Command, args []string) error {
-        fmt.Printf("⚠  DNS import is not fully implemented in this version\n")
-        fmt.Printf("   Please use the Cloudflare Dashboard to import DNS records\n")
+        zoneIdentifier := args[0]
+        bindFile := args[1]
+        dryRun, _ := cmd.Flags().GetBool("dry-run")
+        origin, _ := cmd.Flags().GetString("origin")
+
+        f, err := os.Open(bindFile)
+        if err != nil {
+            return fmt.Errorf("failed to open zone file: %w", err)
+        }
+        defer f.Close()
+
+        // A dry run only needs the API to find the name of a zone given by ID.
+        var c *client.Client
+        var zoneID string
+        if !dryRun || (origin == "" && client.IsZoneID(strings.ToLower(zoneIdentifier))) {
+            c, err = client.NewFromConfig()
+            if err != nil {
+                return err
+            }
+
+            zoneID, err = getZoneID(c, zoneIdentifier)
+            if err != nil {
+                return err
+            }
+
+            if origin == "" {
+                zone, err := c.API.ZoneDetails(c.Context, zoneID)
+                if err != nil {
+                    return fmt.Errorf("failed to get zone info: %w", err)
+                }
+                origin = zone.Name
+            }
+        } else if origin == "" {
+            origin = zoneIdentifier
+        }
+
+        records, err := zonefile.Parse(f, origin)
+        if err != nil {
+            return fmt.Errorf("failed to parse zone file: %w", err)
+        }
+
+        apex := strings.TrimSuffix(origin, ".")
+
+        if !skipValidation(cmd) {
+            var changed, existing []validate.Record
+            for _, rec := range records {
+                if rec.Type == "SOA" || (rec.Type == "NS" && strings.EqualFold(rec.Name, apex)) {
+                    continue
+                }
+                if params, err := rec.Params(); err == nil {
+                    changed = append(changed, validate.FromParams(params))
+                }
+            }
+            if !dryRun {
+                live, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+                if err != nil {
+                    return fmt.Errorf("failed to list DNS records: %w", err)
+                }
+                for _, rec := range live {
+                    existing = append(existing, validate.FromDNSRecord(rec))
+                }
+            }
+            if err := preflight(validate.CheckChange(apex, changed, existing)); err != nil {
+                return err
+            }
+        }
+        if !dryRun {
+            if err := autoSnapshot(cmd, c, zoneID); err != nil {
+                return err
+            }
+        }
+        created, skipped, failed := 0, 0, 0
+        headers := []string{"TYPE", "NAME", "CONTENT", "TTL"}
+        var rows [][]string
+
+        for _, rec := range records {
+            if !dryRun && c.Context.Err() != nil {
+                return fmt.Errorf("import stopped after %d created: %w", created, c.Context.Err())
+            }
+            if rec.Type == "SOA" || (rec.Type == "NS" && strings.EqualFold(rec.Name, apex)) {
+                skipped++
+                continue
+            }
+
+            params, err := rec.Params()
+            if err != nil {
+                fmt.Printf("✗ %v\n", err)
+                failed++
+                continue
+            }
+
+            if dryRun {
+                rows = append(rows, []string{
+                    params.Type,
+                    params.Name,
+                    utils.Truncate(recordContent(params.Type, params.Content, params.Priority, params.Data), 60),
+                    fmt.Sprintf("%d", params.TTL),
+                })
+                created++
+                continue
+            }
+
+            if _, err := c.API.CreateDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), params); err != nil {
+                fmt.Printf("✗ %s %s (line %d): %v\n", params.Type, params.Name, rec.Line, err)
+                failed++
+                continue
+            }
+            created++
+        }
+
+        if dryRun {
+            if len(rows) > 0 {
+                utils.PrintTable(headers, rows)
+                fmt.Println()
+            }
+            fmt.Printf("Dry run: %d would be created, %d skipped, %d failed\n", created, skipped, failed)
+        } else {
+            fmt.Printf("✓ Import finished: %d created, %d skipped, %d failed\n", created, skipped, failed)
+        }
+
+        if failed > 0 {
+            return fmt.Errorf("%d records could not be imported", failed)
+        }
         return nil
     },
 }
@@ -235,15 +505,40 @@ var dnsExportCmd = &cobra.Command{
     },
 }
 
+// recordContent renders a record's value for display, in zone file format
+// for structured types such as SRV and CAA.
+func recordContent(rrtype, content string, priority *uint16, data interface{}) string {
+    if m, ok := data.(map[string]interface{}); ok && len(m) > 0 && (content == "" || zonefile.Structured(rrtype)) {
+        content = zonefile.FormatData(rrtype, m)
+    }
+    if priority != nil {
+        return fmt.Sprintf("%d %s", *priority, content)
+    }
+    return content
+}
+
 func init() {
     dnsListCmd.Flags().StringP("type", "t", "", "Filter by record type (A, AAAA, CNAME, MX, etc.)")
 
     dnsCreateCmd.Flags().Int("ttl", 1, "TTL in seconds (1 = automatic)")
     dnsCreateCmd.Flags().Bool("proxied", false, "Enable Cloudflare proxy")
-    dnsCreateCmd.Flags().Int("priority", 10, "Priority (for MX/SRV records)")
+    dnsCreateCmd.Flags().Int("priority", 10, "Priority (for MX/SRV/URI records)")
+
+    dnsUpdateCmd.Flags().Int("ttl", 0, "TTL in seconds, 1 for automatic; the current TTL is kept when not given")
+    dnsUpdateCmd.Flags().Bool("proxied", false, "Enable (or with --proxied=false disable) Cloudflare proxy; the current setting is kept when not given")
+
+    for _, cmd := range []*cobra.Command{dnsCreateCmd, dnsUpdateCmd} {
+        cmd.Flags().StringArray("data", nil, "Field of a structured record as key=value (repeatable), e.g. --data port=443")
+        cmd.Flags().String("comment", "", "Record comment")
+        cmd.Flags().StringSlice("tag", nil, "Record tag as name:value (repeatable)")
+    }
+
+    dnsImportCmd.Flags().Bool("dry-run", false, "Show records that would be created without calling the API")
+    dnsImportCmd.Flags().String("origin", "", "Origin for relative names (defaults to the zone name)")
 
-    dnsUpdateCmd.Flags().Int("ttl", 1, "TTL in seconds")
-    dnsUpdateCmd.Flags().Bool("proxied", false, "Enable Cloudflare proxy")
+    for _, cmd := range []*cobra.Command{dnsCreateCmd, dnsUpdateCmd, dnsImportCmd} {
+        cmd.Flags().Bool("skip-validation", false, "Send the change even if pre-flight validation finds errors")
+    }
 
     DNSCmd.AddCommand(dnsListCmd)
     DNSCmd.AddCommand(dnsCreateCmd)
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare-manager/validate"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+var dnsPlanCmd = &cobra.Command{
+	Use:   "plan [zone-id or domain]",
+	Short: "Show changes needed to match a desired-state file",
+	Long: `Compare a YAML or JSON desired-state file with the live DNS records of a zone.
+
+Records are matched on type, name and content. The zone can be given as an
+argument or with a top-level "zone:" key in the file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prune, _ := cmd.Flags().GetBool("prune")
+
+		_, _, plan, err := buildDNSPlan(cmd, args, prune)
+		if err != nil {
+			return err
+		}
+
+		plan.Print(os.Stdout)
+		return nil
+	},
+}
+
+var dnsApplyCmd = &cobra.Command{
+	Use:   "apply [zone-id or domain]",
+	Short: "Reconcile a zone with a desired-state file",
+	Long: `Create, update and (with --prune) delete DNS records so that the zone
+matches a YAML or JSON desired-state file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prune, _ := cmd.Flags().GetBool("prune")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		c, zoneID, plan, err := buildDNSPlan(cmd, args, prune)
+		if err != nil {
+			return err
+		}
+
+		plan.Print(os.Stdout)
+		if plan.Empty() {
+			fmt.Println("\nNo changes. Zone is up to date.")
+			return nil
+		}
+
+		fmt.Println()
+		if !yes && !utils.Confirm("Apply these changes?") {
+			fmt.Println("Apply cancelled.")
+			return nil
+		}
+
+		if err := autoSnapshot(cmd, c, zoneID); err != nil {
+			return err
+		}
+
+		res := dnssync.Apply(c.Context, c.API, zoneID, plan)
+		for _, err := range res.Errors {
+			fmt.Printf("✗ %v\n", err)
+		}
+		fmt.Printf("✓ Apply finished: %d created, %d updated, %d deleted, %d failed\n", res.Created, res.Updated, res.Deleted, res.Failed)
+		if res.Failed > 0 {
+			return fmt.Errorf("%d changes failed", res.Failed)
+		}
+		return nil
+	},
+}
+
+func buildDNSPlan(cmd *cobra.Command, args []string, prune bool) (*client.Client, string, dnssync.Plan, error) {
+	file, _ := cmd.Flags().GetString("file")
+	if file == "" {
+		return nil, "", dnssync.Plan{}, fmt.Errorf("a desired-state file is required (--file)")
+	}
+
+	desired, err := dnssync.Load(file)
+	if err != nil {
+		return nil, "", dnssync.Plan{}, err
+	}
+
+	zoneIdentifier := desired.Zone
+	if len(args) > 0 {
+		zoneIdentifier = args[0]
+	}
+	if zoneIdentifier == "" {
+		return nil, "", dnssync.Plan{}, fmt.Errorf("no zone given: pass it as an argument or set 'zone' in %s", file)
+	}
+
+	c, err := client.NewFromConfig()
+	if err != nil {
+		return nil, "", dnssync.Plan{}, err
+	}
+
+	zoneID, err := getZoneID(c, zoneIdentifier)
+	if err != nil {
+		return nil, "", dnssync.Plan{}, err
+	}
+
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return nil, "", dnssync.Plan{}, fmt.Errorf("failed to get zone info: %w", err)
+	}
+
+	current, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+	if err != nil {
+		return nil, "", dnssync.Plan{}, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	if !skipValidation(cmd) {
+		if err := preflight(checkDesiredState(zone.Name, desired.Records, current, prune)); err != nil {
+			return nil, "", dnssync.Plan{}, err
+		}
+	}
+
+	return c, zoneID, dnssync.Diff(zone.Name, desired.Records, current, prune), nil
+}
+
+// checkDesiredState validates the zone as it will be after applying desired:
+// the desired records plus, without prune, the live records the file does
+// not mention.
+func checkDesiredState(zone string, desired []dnssync.Record, current []cloudflare.DNSRecord, prune bool) []validate.Issue {
+	keys := map[string]bool{}
+	var changed []validate.Record
+	for _, r := range desired {
+		rec := validate.FromSyncRecord(zone, r)
+		changed = append(changed, rec)
+		keys[dnssync.Key(rec.Type, rec.Name, dnssync.Content(dnssync.Normalize(zone, r)))] = true
+	}
+
+	var kept []validate.Record
+	if !prune {
+		for _, cur := range current {
+			if !keys[dnssync.Key(cur.Type, cur.Name, cur.Content)] {
+				kept = append(kept, validate.FromDNSRecord(cur))
+			}
+		}
+	}
+	return validate.CheckChange(zone, changed, kept)
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{dnsPlanCmd, dnsApplyCmd} {
+		cmd.Flags().StringP("file", "f", "", "Desired-state file (YAML or JSON)")
+		cmd.Flags().Bool("prune", false, "Delete live records that are not in the file")
+		cmd.Flags().Bool("skip-validation", false, "Skip pre-flight validation of the desired records")
+		cmd.MarkFlagRequired("file")
+	}
+	dnsApplyCmd.Flags().BoolP("yes", "y", false, "Apply without asking for confirmation")
+
+	DNSCmd.AddCommand(dnsPlanCmd)
+	DNSCmd.AddCommand(dnsApplyCmd)
+}
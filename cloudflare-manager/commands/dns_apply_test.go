@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSPlanAndApply(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.1", TTL: 300})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "old", Content: "stale"})
+
+	file := writeFile(t, "dns.yaml", `zone: example.com
+records:
+  - type: A
+    name: www
+    content: 192.0.2.1
+    ttl: 600
+  - type: CNAME
+    name: blog
+    content: example.github.io
+`)
+
+	out := e.mustRun("dns", "plan", "-f", file, "--prune")
+	assertContains(t, out, "Plan: 1 to add, 1 to change, 1 to destroy.")
+	if n := len(e.api.DNSRecords(zone.ID)); n != 2 {
+		t.Fatalf("plan changed the zone: got %d records, want 2", n)
+	}
+
+	out = e.mustRun("dns", "apply", "-f", file, "--prune", "--yes")
+	assertContains(t, out, "Apply finished: 1 created, 1 updated, 1 deleted, 0 failed")
+
+	out = e.mustRun("dns", "apply", "example.com", "-f", file, "--prune", "--yes")
+	assertContains(t, out, "No changes. Zone is up to date.")
+}
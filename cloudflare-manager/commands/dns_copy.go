@@ -0,0 +1,315 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+// Conflict policies for dns copy.
+const (
+	conflictFail      = "fail"
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+)
+
+var dnsCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy DNS records from one zone or account to another",
+	Long: `Read every DNS record of the source zone and recreate it in the target
+zone, keeping TTL, proxy status, comments and tags. Zones are given as
+[account:]zone; without an account the current one is used.
+
+When the zones differ, names under the source zone are moved under the
+target zone, and so are targets of CNAME, MX, NS, PTR, SRV, HTTPS and SVCB
+records. Further --rename old=new rules rewrite other domain suffixes and
+are applied first. NS records at the source apex are not copied.
+
+A record conflicts when the target already has records with the same type
+and name but different content. --on-conflict decides what happens:
+  fail       stop before making any change (default)
+  skip       leave the target's records of that type and name alone
+  overwrite  replace them with the source records`,
+	Example: `  cfm dns copy --from old-account:example.com --to new-account:example.com --dry-run
+  cfm dns copy --from example.com --to example.org --on-conflict skip
+  cfm dns copy --from a:example.com --to b:example.net --rename cdn.example.net=cdn.example.org`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		renames, _ := cmd.Flags().GetStringArray("rename")
+		policy, _ := cmd.Flags().GetString("on-conflict")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if policy != conflictFail && policy != conflictSkip && policy != conflictOverwrite {
+			return fmt.Errorf("unknown conflict policy %q (use fail, skip or overwrite)", policy)
+		}
+
+		src, err := openCopyZone(from)
+		if err != nil {
+			return fmt.Errorf("--from: %w", err)
+		}
+		dst, err := openCopyZone(to)
+		if err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+		if src.zone.ID == dst.zone.ID {
+			return fmt.Errorf("source and target are the same zone")
+		}
+
+		rules, err := parseRenameRules(renames)
+		if err != nil {
+			return err
+		}
+		if src.zone.Name != dst.zone.Name {
+			rules = append(rules, renameRule{from: src.zone.Name, to: dst.zone.Name})
+		}
+
+		var desired []dnssync.Record
+		for _, rec := range src.records {
+			if rec.Type == "NS" && strings.EqualFold(rec.Name, src.zone.Name) {
+				continue
+			}
+			r := renameRecord(dnssync.FromDNSRecord(rec), rules)
+			if r.Name != dst.zone.Name && !strings.HasSuffix(r.Name, "."+dst.zone.Name) {
+				return fmt.Errorf("%s %s would become %s, which is outside %s; add a --rename rule", rec.Type, rec.Name, r.Name, dst.zone.Name)
+			}
+			desired = append(desired, r)
+		}
+
+		// Group the target's records by type and name, and find the groups
+		// whose content differs from what is being copied.
+		wanted := map[string]map[string]bool{}
+		for _, r := range desired {
+			group := copyGroup(r.Type, r.Name)
+			if wanted[group] == nil {
+				wanted[group] = map[string]bool{}
+			}
+			wanted[group][dnssync.Key(r.Type, r.Name, dnssync.Content(r))] = true
+		}
+		conflicts := map[string]bool{}
+		var current, kept []cloudflare.DNSRecord
+		for _, rec := range dst.records {
+			group := copyGroup(rec.Type, rec.Name)
+			if wanted[group] == nil {
+				kept = append(kept, rec)
+				continue
+			}
+			if !wanted[group][dnssync.Key(rec.Type, rec.Name, rec.Content)] {
+				conflicts[group] = true
+			}
+			current = append(current, rec)
+		}
+
+		var conflictList []string
+		for group := range conflicts {
+			conflictList = append(conflictList, group)
+		}
+		sort.Strings(conflictList)
+
+		switch {
+		case len(conflicts) > 0 && policy == conflictFail:
+			return fmt.Errorf("%d records already exist in %s with different content:\n  %s\nuse --on-conflict skip or overwrite", len(conflicts), dst, strings.Join(conflictList, "\n  "))
+		case policy == conflictSkip:
+			var filtered []dnssync.Record
+			for _, r := range desired {
+				if !conflicts[copyGroup(r.Type, r.Name)] {
+					filtered = append(filtered, r)
+				}
+			}
+			desired = filtered
+			var filteredCurrent []cloudflare.DNSRecord
+			for _, rec := range current {
+				if conflicts[copyGroup(rec.Type, rec.Name)] {
+					kept = append(kept, rec)
+				} else {
+					filteredCurrent = append(filteredCurrent, rec)
+				}
+			}
+			current = filteredCurrent
+		}
+
+		if !skipValidation(cmd) {
+			if err := preflight(checkDesiredState(dst.zone.Name, desired, kept, false)); err != nil {
+				return err
+			}
+		}
+
+		// Only overwrite deletes: with the other policies current holds just
+		// records whose content is being copied.
+		plan := dnssync.Diff(dst.zone.Name, desired, current, policy == conflictOverwrite)
+
+		fmt.Printf("Copy %s → %s: %d source records\n\n", src, dst, len(desired))
+		if policy == conflictSkip {
+			for _, group := range conflictList {
+				fmt.Printf("  ! %s  exists with different content, skipped\n", group)
+			}
+		}
+		plan.Print(os.Stdout)
+		if dryRun || plan.Empty() {
+			return nil
+		}
+
+		fmt.Println()
+		if !yes && !utils.Confirm(fmt.Sprintf("Copy these records to %s?", dst)) {
+			fmt.Println("Copy cancelled.")
+			return nil
+		}
+
+		if err := autoSnapshot(cmd, dst.client, dst.zone.ID); err != nil {
+			return err
+		}
+
+		res := dnssync.Apply(dst.client.Context, dst.client.API, dst.zone.ID, plan)
+		for _, err := range res.Errors {
+			fmt.Printf("✗ %v\n", err)
+		}
+		fmt.Printf("✓ Copy finished: %d created, %d updated, %d deleted, %d failed\n", res.Created, res.Updated, res.Deleted, res.Failed)
+		if res.Failed > 0 {
+			return fmt.Errorf("%d changes failed", res.Failed)
+		}
+		return nil
+	},
+}
+
+// copyZone is one side of dns copy.
+type copyZone struct {
+	account string
+	client  *client.Client
+	zone    cloudflare.Zone
+	records []cloudflare.DNSRecord
+}
+
+func (z *copyZone) String() string {
+	return z.account + ":" + z.zone.Name
+}
+
+// openCopyZone resolves "[account:]zone" and lists the zone's records.
+func openCopyZone(ref string) (*copyZone, error) {
+	accountName, zoneIdentifier, ok := strings.Cut(ref, ":")
+	if !ok {
+		accountName, zoneIdentifier = "", ref
+	}
+	if zoneIdentifier == "" {
+		return nil, fmt.Errorf("no zone given in %q", ref)
+	}
+
+	var account *config.Account
+	var err error
+	if accountName == "" {
+		account, err = config.ActiveAccount()
+	} else {
+		var cfg *config.Config
+		if cfg, err = config.Load(); err == nil {
+			account, err = cfg.GetAccount(accountName)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(account)
+	if err != nil {
+		return nil, err
+	}
+	zoneID, err := getZoneID(c, zoneIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone info: %w", err)
+	}
+	records, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+	return &copyZone{account: account.Name, client: c, zone: zone, records: records}, nil
+}
+
+// renameRule moves names under from to the same position under to.
+type renameRule struct {
+	from, to string
+}
+
+func parseRenameRules(specs []string) ([]renameRule, error) {
+	var rules []renameRule
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(spec, "=")
+		from = strings.ToLower(strings.Trim(from, "."))
+		to = strings.ToLower(strings.Trim(to, "."))
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid rename rule %q (expected old.example.com=new.example.com)", spec)
+		}
+		rules = append(rules, renameRule{from: from, to: to})
+	}
+	return rules, nil
+}
+
+// rename applies the first matching rule to name, keeping a trailing dot.
+func rename(name string, rules []renameRule) string {
+	base, fqdn := strings.CutSuffix(strings.ToLower(name), ".")
+	dot := ""
+	if fqdn {
+		dot = "."
+	}
+	for _, r := range rules {
+		if base == r.from {
+			return r.to + dot
+		}
+		if prefix, ok := strings.CutSuffix(base, "."+r.from); ok {
+			return prefix + "." + r.to + dot
+		}
+	}
+	return name
+}
+
+// renameRecord renames a record and, for types whose value is a hostname,
+// its target.
+func renameRecord(r dnssync.Record, rules []renameRule) dnssync.Record {
+	r.Name = rename(r.Name, rules)
+	switch r.Type {
+	case "CNAME", "NS", "PTR", "MX":
+		r.Content = rename(r.Content, rules)
+	case "SRV", "HTTPS", "SVCB":
+		if target, ok := r.Data["target"].(string); ok {
+			if renamed := rename(target, rules); renamed != target {
+				data := make(map[string]interface{}, len(r.Data))
+				for k, v := range r.Data {
+					data[k] = v
+				}
+				data["target"] = renamed
+				r.Data = data
+				r.Content = ""
+			}
+		}
+	}
+	return r
+}
+
+func copyGroup(rrtype, name string) string {
+	return strings.ToUpper(rrtype) + " " + strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+func init() {
+	dnsCopyCmd.Flags().String("from", "", "Source zone as [account:]zone")
+	dnsCopyCmd.Flags().String("to", "", "Target zone as [account:]zone")
+	dnsCopyCmd.Flags().StringArray("rename", nil, "Rewrite a domain suffix in names and targets, e.g. old.example.com=new.example.org (repeatable)")
+	dnsCopyCmd.Flags().String("on-conflict", conflictFail, "What to do with existing records that differ: fail, skip or overwrite")
+	dnsCopyCmd.Flags().Bool("dry-run", false, "Only report what would be copied")
+	dnsCopyCmd.Flags().BoolP("yes", "y", false, "Copy without asking for confirmation")
+	dnsCopyCmd.Flags().Bool("skip-validation", false, "Copy even if the records fail validation")
+	dnsCopyCmd.MarkFlagRequired("from")
+	dnsCopyCmd.MarkFlagRequired("to")
+
+	DNSCmd.AddCommand(dnsCopyCmd)
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSCopy(t *testing.T) {
+	e := newTestEnv(t)
+	other := e.addAccount("new")
+	src := e.api.AddZone(e.account.ID, "example.com")
+	same := e.api.AddZone(other.ID, "example.com")
+	renamed := e.api.AddZone(other.ID, "example.org")
+
+	proxied := true
+	priority := uint16(10)
+	e.api.AddDNSRecord(src.ID, cloudflare.DNSRecord{Type: "NS", Name: "@", Content: "ada.ns.cloudflare.com"})
+	e.api.AddDNSRecord(src.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.1", Proxied: &proxied, Comment: "web", Tags: []string{"team:web"}})
+	e.api.AddDNSRecord(src.ID, cloudflare.DNSRecord{Type: "CNAME", Name: "blog", Content: "www.example.com", TTL: 300})
+	e.api.AddDNSRecord(src.ID, cloudflare.DNSRecord{Type: "MX", Name: "@", Content: "mail.example.com", Priority: &priority, TTL: 3600})
+	e.api.AddDNSRecord(src.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "v=spf1 include:_spf.example.net -all"})
+	e.api.AddDNSRecord(same.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "198.51.100.1"})
+	e.api.AddDNSRecord(same.ID, cloudflare.DNSRecord{Type: "A", Name: "keep", Content: "198.51.100.2"})
+
+	// Same zone name in another account: www conflicts.
+	msg := e.mustFail("dns", "copy", "--from", "example.com", "--to", "new:example.com", "--yes")
+	assertContains(t, msg, "1 records already exist in new:example.com with different content", "A www.example.com", "--on-conflict")
+
+	out := e.mustRun("dns", "copy", "--from", "example.com", "--to", "new:example.com", "--on-conflict", "skip", "--dry-run")
+	assertContains(t, out, "Copy test:example.com → new:example.com: 3 source records", "! A www.example.com  exists with different content, skipped", "Plan: 3 to add, 0 to change, 0 to destroy.")
+	if strings.Contains(out, "NS ") {
+		t.Errorf("apex NS records copied:\n%s", out)
+	}
+	if len(e.api.DNSRecords(same.ID)) != 2 {
+		t.Fatal("--dry-run changed the target zone")
+	}
+
+	out = e.mustRun("dns", "copy", "--from", "test:example.com", "--to", "new:example.com", "--on-conflict", "overwrite", "--yes")
+	assertContains(t, out, "✓ Copy finished: 4 created, 0 updated, 1 deleted, 0 failed")
+	records := recordsByName(e.api.DNSRecords(same.ID))
+	if www := records["A www.example.com"]; www.Content != "192.0.2.1" || www.Proxied == nil || !*www.Proxied || www.Comment != "web" || strings.Join(www.Tags, ",") != "team:web" {
+		t.Errorf("www copied as %+v", www)
+	}
+	if _, ok := records["A keep.example.com"]; !ok {
+		t.Error("overwrite deleted a record the source does not have")
+	}
+
+	// Copying again changes nothing.
+	out = e.mustRun("dns", "copy", "--from", "example.com", "--to", "new:example.com", "--yes")
+	assertContains(t, out, "Plan: 0 to add, 0 to change, 0 to destroy.")
+
+	// To another domain, names and in-zone targets move along.
+	out = e.mustRun("dns", "copy", "--from", "example.com", "--to", "new:example.org", "--rename", "_spf.example.net=_spf.example.org", "--yes")
+	assertContains(t, out, "✓ Copy finished: 4 created")
+	records = recordsByName(e.api.DNSRecords(renamed.ID))
+	if got := records["CNAME blog.example.org"]; got.Content != "www.example.org" || got.TTL != 300 {
+		t.Errorf("CNAME copied as %+v", got)
+	}
+	if got := records["MX example.org"]; got.Content != "mail.example.org" || got.Priority == nil || *got.Priority != 10 {
+		t.Errorf("MX copied as %+v", got)
+	}
+	if got := records["TXT example.org"]; got.Content != "v=spf1 include:_spf.example.net -all" {
+		t.Errorf("TXT content rewritten: %+v", got)
+	}
+
+	msg = e.mustFail("dns", "copy", "--from", "example.com", "--to", "example.com")
+	assertContains(t, msg, "same zone")
+	msg = e.mustFail("dns", "copy", "--from", "example.com", "--to", "missing:example.com")
+	assertContains(t, msg, "account missing not found")
+}
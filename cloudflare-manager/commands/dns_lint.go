@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare-manager/validate"
+	"github.com/cloudflare-manager/zonefile"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+var dnsLintCmd = &cobra.Command{
+	Use:   "lint [file or zone]",
+	Short: "Check DNS records for mistakes",
+	Long: `Check a BIND zone file, a desired-state YAML/JSON file or the live records
+of a zone for problems: record syntax per type (A, AAAA, CNAME, MX, TXT, SRV,
+CAA, HTTPS, SVCB), CNAMEs that share a name with other records, TTLs out of
+range, proxied records of types that cannot be proxied, SPF policies with
+more than 10 DNS lookups, and duplicate records.
+
+The same checks run before dns create, update, import, plan and apply;
+pass --skip-validation to those commands to bypass them.
+
+Exits with an error when errors are found, or with --strict when warnings
+are found.`,
+	Example: `  cfm dns lint example.com.zone --origin example.com
+  cfm dns lint dns.yaml
+  cfm dns lint example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		origin, _ := cmd.Flags().GetString("origin")
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		var zone string
+		var records []validate.Record
+		var err error
+		if info, statErr := os.Stat(args[0]); statErr == nil && !info.IsDir() {
+			zone, records, err = lintFileRecords(args[0], origin)
+		} else {
+			zone, records, err = lintZoneRecords(args[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		issues := validate.CheckZone(zone, records)
+		errors, warnings := 0, 0
+		var rows [][]string
+		for _, i := range issues {
+			if i.Severity == validate.Error {
+				errors++
+			} else {
+				warnings++
+			}
+			rows = append(rows, []string{string(i.Severity), i.Record, i.Message})
+		}
+
+		if err := utils.Render(utils.View{
+			Data:    issues,
+			Headers: []string{"SEVERITY", "RECORD", "MESSAGE"},
+			Rows:    rows,
+			Empty:   fmt.Sprintf("✓ %d records checked, no problems found", len(records)),
+			Footer:  fmt.Sprintf("\n%d records checked: %d errors, %d warnings", len(records), errors, warnings),
+		}); err != nil {
+			return err
+		}
+
+		if errors > 0 || (strict && warnings > 0) {
+			return fmt.Errorf("lint found %d errors and %d warnings", errors, warnings)
+		}
+		return nil
+	},
+}
+
+// lintFileRecords reads a desired-state file (.yaml, .yml, .json) or a BIND
+// zone file.
+func lintFileRecords(path, origin string) (string, []validate.Record, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		desired, err := dnssync.Load(path)
+		if err != nil {
+			return "", nil, err
+		}
+		zone := origin
+		if zone == "" {
+			zone = desired.Zone
+		}
+		if zone == "" {
+			return "", nil, fmt.Errorf("no zone given: set 'zone' in %s or pass --origin", path)
+		}
+		var records []validate.Record
+		for _, r := range desired.Records {
+			records = append(records, validate.FromSyncRecord(zone, r))
+		}
+		return zone, records, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open zone file: %w", err)
+	}
+	defer f.Close()
+
+	parsed, err := zonefile.Parse(f, origin)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse zone file: %w", err)
+	}
+
+	zone := strings.TrimSuffix(origin, ".")
+	var records []validate.Record
+	for _, rec := range parsed {
+		if rec.Type == "SOA" {
+			if zone == "" {
+				zone = rec.Name
+			}
+			continue
+		}
+		records = append(records, zoneFileRecord(rec))
+	}
+	return zone, records, nil
+}
+
+// zoneFileRecord converts a parsed zone file record. Types the importer
+// cannot convert are checked from their presentation format.
+func zoneFileRecord(rec zonefile.Record) validate.Record {
+	params, err := rec.Params()
+	if err != nil {
+		return validate.Record{Type: rec.Type, Name: rec.Name, Content: strings.Join(rec.Data, " "), TTL: rec.TTL}
+	}
+	return validate.FromParams(params)
+}
+
+func lintZoneRecords(zoneIdentifier string) (string, []validate.Record, error) {
+	c, err := client.NewFromConfig()
+	if err != nil {
+		return "", nil, err
+	}
+
+	zoneID, err := getZoneID(c, zoneIdentifier)
+	if err != nil {
+		return "", nil, err
+	}
+
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get zone details: %w", err)
+	}
+
+	live, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	records := make([]validate.Record, 0, len(live))
+	for _, rec := range live {
+		records = append(records, validate.FromDNSRecord(rec))
+	}
+	return zone.Name, records, nil
+}
+
+// skipValidation reports whether --skip-validation was passed.
+func skipValidation(cmd *cobra.Command) bool {
+	skip, _ := cmd.Flags().GetBool("skip-validation")
+	return skip
+}
+
+// preflight prints validation warnings and turns validation errors into an
+// error that stops the change.
+func preflight(issues []validate.Issue) error {
+	for _, i := range issues {
+		if i.Severity == validate.Warning {
+			fmt.Printf("⚠ %s\n", i)
+		}
+	}
+	if err := validate.Err(issues); err != nil {
+		return fmt.Errorf("%w\nuse --skip-validation to send the change anyway", err)
+	}
+	return nil
+}
+
+// checkRecordChange validates a record about to be created, or to replace
+// the record with ID replaces, against the other records at its name.
+func checkRecordChange(cmd *cobra.Command, c *client.Client, zoneID string, rec validate.Record, replaces string) error {
+	if skipValidation(cmd) {
+		return nil
+	}
+
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return fmt.Errorf("failed to get zone details: %w", err)
+	}
+	rec.Name = dnssync.ExpandName(zone.Name, rec.Name)
+
+	current, err := findDNSRecords(c, zoneID, rec.Name, "", "")
+	if err != nil {
+		return err
+	}
+	var others []validate.Record
+	for _, cur := range current {
+		if cur.ID != replaces {
+			others = append(others, validate.FromDNSRecord(cur))
+		}
+	}
+	return preflight(validate.CheckChange(zone.Name, []validate.Record{rec}, others))
+}
+
+func init() {
+	dnsLintCmd.Flags().String("origin", "", "Zone name for relative names in the file")
+	dnsLintCmd.Flags().Bool("strict", false, "Also fail when warnings are found")
+
+	DNSCmd.AddCommand(dnsLintCmd)
+}
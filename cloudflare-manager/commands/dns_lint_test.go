@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSLintZoneFile(t *testing.T) {
+	e := newTestEnv(t)
+
+	bind := writeFile(t, "example.com.zone", `$ORIGIN example.com.
+$TTL 1h
+@        IN SOA ns1.example.net. admin.example.com. 1 7200 3600 1209600 3600
+@        IN A     192.0.2.1
+@        IN CNAME other.example.net.
+mail     IN MX    10 192.0.2.25.
+mx2      IN MX    20 192.0.2.26
+www      IN A     192.0.2.300
+www      IN A     192.0.2.4
+www      IN A     192.0.2.4
+short 10 IN A     192.0.2.5
+@        IN CAA   0 issu3-r "letsencrypt.org"
+@        IN CAA   0 iodef "ftp://example.com"
+_sip.example.com. IN SRV 10 5 5060 sip.example.com.
+@        IN TXT   "v=spf1 include:a._spf.example.com include:b._spf.example.com mx a ip4:192.0.2.0/33 -all"
+a._spf   IN TXT   "v=spf1 a mx exists:x.example.net include:_spf.google.com ~all"
+b._spf   IN TXT   "v=spf1 a:one.example.com a:two.example.com a:three.example.com ~all"
+svc      IN HTTPS 0 svc.example.net. alpn=h2
+svc2     IN HTTPS 1 . alpn=h2,h3 ipv4hint=2001:db8::1 foo=bar
+`)
+
+	out, err := e.run("dns", "lint", bind)
+	if err == nil {
+		t.Fatalf("lint passed a broken zone file\n%s", out)
+	}
+	assertContains(t, out,
+		"CNAME at the zone apex cannot coexist with other records at the same name (A, CAA, TXT)",
+		"MX target must be a hostname, not an IP address (192.0.2.25)",
+		"MX target 192.0.2.26.example.com looks like an IP address made relative to the zone",
+		`"192.0.2.300" is not an IPv4 address`,
+		`duplicate record with content "192.0.2.4"`,
+		"TTL 10 is out of range",
+		`invalid CAA tag "issu3-r"`,
+		`CAA iodef value "ftp://example.com" must be a mailto:, http: or https: URL`,
+		"SRV name must have the form _service._proto.name",
+		`invalid SPF ip4 network "192.0.2.0/33"`,
+		"SPF policy needs at least 11 DNS lookups, more than the limit of 10",
+		"HTTPS alias mode (priority 0) records cannot have parameters",
+		`ipv4hint contains "2001:db8::1"`,
+		`unknown HTTPS parameter "foo"`,
+	)
+	if strings.Contains(out, "TXT b._spf.example.com") || strings.Contains(out, "TXT a._spf.example.com") {
+		t.Errorf("valid SPF policy reported:\n%s", out)
+	}
+
+	clean := writeFile(t, "clean.zone", `$ORIGIN example.com.
+@    3600 IN A   192.0.2.1
+@    3600 IN MX  10 mail
+@    3600 IN TXT "v=spf1 mx -all"
+@    3600 IN CAA 0 issue "letsencrypt.org"
+mail 3600 IN A   192.0.2.25
+_sip._tcp 3600 IN SRV 10 5 5060 sip.example.com.
+`)
+	out = e.mustRun("dns", "lint", clean)
+	assertContains(t, out, "6 records checked, no problems found")
+}
+
+func TestDNSLintStateFileAndZone(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	proxied := true
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "10.0.0.1", Proxied: &proxied})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "v=spf1 -all"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "v=spf1 mx -all"})
+
+	msg := e.mustFail("dns", "lint", "example.com")
+	assertContains(t, msg, "1 errors and 1 warnings")
+	out, _ := e.run("dns", "lint", "example.com", "-o", "json")
+	assertContains(t, out, `"severity": "warning"`, "proxied record points at private address 10.0.0.1", "2 SPF records published at the same name")
+
+	state := writeFile(t, "dns.yaml", `zone: example.com
+records:
+  - {type: MX, name: "@", content: mail.example.com, proxied: true}
+  - {type: A, name: www, content: 192.0.2.1, ttl: 45}
+`)
+	out, err := e.run("dns", "lint", state, "--strict")
+	if err == nil {
+		t.Fatalf("lint --strict passed\n%s", out)
+	}
+	assertContains(t, out, "MX records cannot be proxied", "TTL 45 is below 60 seconds")
+
+	// plan and apply run the same checks against the resulting zone.
+	msg = e.mustFail("dns", "plan", "-f", state)
+	assertContains(t, msg, "MX example.com: MX records cannot be proxied", "--skip-validation")
+	out = e.mustRun("dns", "plan", "-f", state, "--skip-validation")
+	assertContains(t, out, "Plan: 2 to add")
+
+	conflict := writeFile(t, "conflict.yaml", `zone: example.com
+records:
+  - {type: CNAME, name: www, content: example.net}
+`)
+	msg = e.mustFail("dns", "apply", "-f", conflict, "--yes")
+	assertContains(t, msg, "CNAME www.example.com: CNAME cannot coexist with other records at the same name (A)")
+	e.mustRun("dns", "apply", "-f", conflict, "--prune", "--yes")
+}
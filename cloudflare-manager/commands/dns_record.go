@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare-manager/zonefile"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+var dnsSetCmd = &cobra.Command{
+	Use:   "set [zone-id or domain] [name] [type] [content]",
+	Short: "Create or update a DNS record by name",
+	Long: `Make sure a record with the given name, type and content exists.
+
+If a record with the same name and type exists it is updated in place; if
+none exists one is created. When several records share the name and type
+(round-robin A records, multiple TXT values) pick the one to replace with
+--match-content. Names are relative to the zone unless they end in a dot.`,
+	Example: `  cfm dns set example.com www A 192.0.2.10 --proxied
+  cfm dns set example.com @ TXT "v=spf1 -all" --match-content "v=spf1 ~all"`,
+	Args: cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		recordType := strings.ToUpper(args[2])
+		content := args[3]
+		matchContent, _ := cmd.Flags().GetString("match-content")
+
+		c, zoneID, name, err := dnsRecordTarget(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		records, err := findDNSRecords(c, zoneID, name, recordType, "")
+		if err != nil {
+			return err
+		}
+
+		var target *cloudflare.DNSRecord
+		for i := range records {
+			if records[i].Content == content {
+				target = &records[i]
+			}
+		}
+		if target == nil && matchContent != "" {
+			var matched []cloudflare.DNSRecord
+			for _, rec := range records {
+				if rec.Content == matchContent {
+					matched = append(matched, rec)
+				}
+			}
+			if len(matched) == 0 {
+				return ambiguousRecordsError(fmt.Sprintf("no %s record for %s has content %q", recordType, name, matchContent), records)
+			}
+			target = &matched[0]
+		} else if target == nil && len(records) == 1 {
+			target = &records[0]
+		} else if target == nil && len(records) > 1 {
+			return ambiguousRecordsError(fmt.Sprintf("%d %s records exist for %s; use --match-content to choose one", len(records), recordType, name), records)
+		}
+
+		if target == nil {
+			if err := autoSnapshot(cmd, c, zoneID); err != nil {
+				return err
+			}
+
+			params := cloudflare.CreateDNSRecordParams{
+				Type:    recordType,
+				Name:    name,
+				Content: content,
+			}
+			params.TTL, _ = cmd.Flags().GetInt("ttl")
+			if cmd.Flags().Changed("proxied") {
+				proxied, _ := cmd.Flags().GetBool("proxied")
+				params.Proxied = &proxied
+			}
+			if recordType == "MX" || recordType == "SRV" {
+				priority, _ := cmd.Flags().GetUint16("priority")
+				params.Priority = &priority
+			}
+
+			record, err := c.API.CreateDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), params)
+			if err != nil {
+				return fmt.Errorf("failed to create DNS record: %w", err)
+			}
+			fmt.Printf("✓ Created %s %s → %s\n", record.Type, record.Name, record.Content)
+			return nil
+		}
+
+		params := cloudflare.UpdateDNSRecordParams{
+			ID:      target.ID,
+			Type:    target.Type,
+			Name:    target.Name,
+			Content: content,
+			Tags:    target.Tags,
+		}
+		changed := target.Content != content
+		if cmd.Flags().Changed("ttl") {
+			params.TTL, _ = cmd.Flags().GetInt("ttl")
+			changed = changed || params.TTL != target.TTL
+		}
+		if cmd.Flags().Changed("proxied") {
+			proxied, _ := cmd.Flags().GetBool("proxied")
+			params.Proxied = &proxied
+			changed = changed || target.Proxied == nil || *target.Proxied != proxied
+		}
+		if cmd.Flags().Changed("priority") {
+			priority, _ := cmd.Flags().GetUint16("priority")
+			params.Priority = &priority
+			changed = changed || target.Priority == nil || *target.Priority != priority
+		}
+
+		if !changed {
+			fmt.Printf("✓ %s %s → %s is already up to date\n", target.Type, target.Name, target.Content)
+			return nil
+		}
+
+		if err := autoSnapshot(cmd, c, zoneID); err != nil {
+			return err
+		}
+
+		record, err := c.API.UpdateDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), params)
+		if err != nil {
+			return fmt.Errorf("failed to update DNS record: %w", err)
+		}
+		if target.Content != content {
+			fmt.Printf("✓ Updated %s %s: %s → %s\n", record.Type, record.Name, target.Content, record.Content)
+		} else {
+			fmt.Printf("✓ Updated %s %s → %s\n", record.Type, record.Name, record.Content)
+		}
+		return nil
+	},
+}
+
+var dnsRmCmd = &cobra.Command{
+	Use:   "rm [zone-id or domain] [name]",
+	Short: "Delete DNS records by name",
+	Long: `Delete the record with the given name, optionally narrowed with --type and
+--match-content. If more than one record matches nothing is deleted unless
+--all is given.`,
+	Example: `  cfm dns rm example.com www --type A
+  cfm dns rm example.com @ --type TXT --match-content "v=spf1 ~all"`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		recordType, _ := cmd.Flags().GetString("type")
+		matchContent, _ := cmd.Flags().GetString("match-content")
+		all, _ := cmd.Flags().GetBool("all")
+
+		c, zoneID, name, err := dnsRecordTarget(args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		records, err := findDNSRecords(c, zoneID, name, strings.ToUpper(recordType), matchContent)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no matching DNS records for %s", name)
+		}
+		if len(records) > 1 && !all {
+			return ambiguousRecordsError(fmt.Sprintf("%d records match %s; narrow with --type or --match-content, or pass --all", len(records), name), records)
+		}
+
+		if err := autoSnapshot(cmd, c, zoneID); err != nil {
+			return err
+		}
+
+		for _, rec := range records {
+			if err := c.API.DeleteDNSRecord(c.Context, cloudflare.ZoneIdentifier(zoneID), rec.ID); err != nil {
+				return fmt.Errorf("failed to delete %s %s: %w", rec.Type, rec.Name, err)
+			}
+			fmt.Printf("✓ Deleted %s %s → %s\n", rec.Type, rec.Name, recordContent(rec.Type, rec.Content, rec.Priority, rec.Data))
+		}
+		return nil
+	},
+}
+
+// dnsRecordTarget resolves the zone and expands a record name relative to
+// it.
+func dnsRecordTarget(zoneIdentifier, name string) (*client.Client, string, string, error) {
+	c, err := client.NewFromConfig()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	zoneID, err := getZoneID(c, zoneIdentifier)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get zone details: %w", err)
+	}
+
+	return c, zoneID, dnssync.ExpandName(zone.Name, name), nil
+}
+
+// findDNSRecords lists the records with an exact name, narrowed by type and
+// content when they are not empty.
+func findDNSRecords(c *client.Client, zoneID, name, recordType, content string) ([]cloudflare.DNSRecord, error) {
+	records, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{
+		Name:    name,
+		Type:    recordType,
+		Content: content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+	return records, nil
+}
+
+// recordData builds the data object of a structured record (SRV, CAA, ...)
+// from zone file format content and --data key=value pairs, applied on top
+// of current. SRV records may name their service and protocol with --data
+// service=_sip --data proto=_tcp, which are prefixed to name. priority
+// fills in a missing priority field.
+func recordData(recordType, name, content string, pairs []string, current map[string]interface{}, priority *uint16) (map[string]interface{}, string, error) {
+	data := map[string]interface{}{}
+	for k, v := range current {
+		data[k] = v
+	}
+	if content != "" {
+		fields, err := zonefile.Fields(content)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s content: %w", recordType, err)
+		}
+		parsed, err := zonefile.ParseData(recordType, fields)
+		if err != nil {
+			return nil, "", err
+		}
+		for k, v := range parsed {
+			data[k] = v
+		}
+	}
+
+	var service, proto string
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid --data %q: expected key=value", pair)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch {
+		case recordType == "SRV" && key == "service":
+			service = value
+		case recordType == "SRV" && key == "proto":
+			proto = value
+		default:
+			if err := zonefile.SetDataField(recordType, data, key, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if service != "" || proto != "" {
+		if service == "" || proto == "" {
+			return nil, "", fmt.Errorf("SRV records need both --data service=... and --data proto=...")
+		}
+		prefix := "_" + strings.TrimPrefix(service, "_") + "._" + strings.TrimPrefix(proto, "_")
+		if name == "" || name == "@" {
+			name = prefix
+		} else {
+			name = prefix + "." + name
+		}
+	}
+
+	if _, ok := data["priority"]; !ok && priority != nil {
+		data["priority"] = int(*priority)
+	}
+	if missing := zonefile.MissingDataFields(recordType, data); len(missing) > 0 {
+		return nil, "", fmt.Errorf("%s record is missing %s: give them in the content or with --data key=value", recordType, strings.Join(missing, ", "))
+	}
+	return data, name, nil
+}
+
+// ambiguousRecordsError lists the candidate records under msg so the user
+// can pick one.
+func ambiguousRecordsError(msg string, records []cloudflare.DNSRecord) error {
+	var b strings.Builder
+	b.WriteString(msg)
+	if len(records) > 0 {
+		b.WriteString(":")
+	}
+	for _, rec := range records {
+		fmt.Fprintf(&b, "\n  %-6s %s → %s  (id %s)", rec.Type, rec.Name, recordContent(rec.Type, rec.Content, rec.Priority, rec.Data), rec.ID)
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+func init() {
+	dnsSetCmd.Flags().Int("ttl", 1, "TTL in seconds (1 = automatic)")
+	dnsSetCmd.Flags().Bool("proxied", false, "Enable Cloudflare proxy")
+	dnsSetCmd.Flags().Uint16("priority", 10, "Priority (for MX/SRV records)")
+	dnsSetCmd.Flags().String("match-content", "", "Replace the record with this content when several share the name and type")
+
+	dnsRmCmd.Flags().StringP("type", "t", "", "Only delete records of this type")
+	dnsRmCmd.Flags().String("match-content", "", "Only delete records with this exact content")
+	dnsRmCmd.Flags().Bool("all", false, "Delete every matching record")
+
+	DNSCmd.AddCommand(dnsSetCmd)
+	DNSCmd.AddCommand(dnsRmCmd)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSSet(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	out := e.mustRun("dns", "set", "example.com", "www", "A", "192.0.2.1")
+	assertContains(t, out, "Created A www.example.com → 192.0.2.1")
+
+	out = e.mustRun("dns", "set", "example.com", "www.example.com", "a", "192.0.2.2", "--ttl", "300")
+	assertContains(t, out, "Updated A www.example.com: 192.0.2.1 → 192.0.2.2")
+
+	out = e.mustRun("dns", "set", "example.com", "www", "A", "192.0.2.2", "--ttl", "300")
+	assertContains(t, out, "already up to date")
+
+	records := e.api.DNSRecords(zone.ID)
+	if len(records) != 1 || records[0].Content != "192.0.2.2" || records[0].TTL != 300 {
+		t.Fatalf("records = %+v, want one A 192.0.2.2 with TTL 300", records)
+	}
+
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.3"})
+	msg := e.mustFail("dns", "set", "example.com", "www", "A", "192.0.2.4")
+	assertContains(t, msg, "2 A records exist for www.example.com", "192.0.2.2", "192.0.2.3")
+
+	e.mustRun("dns", "set", "example.com", "www", "A", "192.0.2.4", "--match-content", "192.0.2.3")
+	var contents []string
+	for _, rec := range e.api.DNSRecords(zone.ID) {
+		contents = append(contents, rec.Content)
+	}
+	if len(contents) != 2 || contents[0] != "192.0.2.2" || contents[1] != "192.0.2.4" {
+		t.Errorf("contents after --match-content = %v, want [192.0.2.2 192.0.2.4]", contents)
+	}
+
+	msg = e.mustFail("dns", "set", "example.com", "www", "A", "192.0.2.5", "--match-content", "198.51.100.1")
+	assertContains(t, msg, `no A record for www.example.com has content "198.51.100.1"`)
+}
+
+func TestDNSRm(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "v=spf1 -all"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "google-site-verification=abc"})
+
+	msg := e.mustFail("dns", "rm", "example.com", "@")
+	assertContains(t, msg, "2 records match example.com", "v=spf1 -all", "google-site-verification=abc")
+
+	out := e.mustRun("dns", "rm", "example.com", "@", "--type", "txt", "--match-content", "v=spf1 -all")
+	assertContains(t, out, "Deleted TXT example.com → v=spf1 -all")
+
+	e.mustRun("dns", "rm", "example.com", "www", "--type", "A")
+	if n := len(e.api.DNSRecords(zone.ID)); n != 1 {
+		t.Errorf("got %d records, want 1", n)
+	}
+
+	msg = e.mustFail("dns", "rm", "example.com", "www")
+	assertContains(t, msg, "no matching DNS records for www.example.com")
+}
@@ -0,0 +1,391 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare-manager/validate"
+	"github.com/cloudflare-manager/zonefile"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+// searchMatch is a record found by dns search.
+type searchMatch struct {
+	Account string               `json:"account"`
+	Zone    string               `json:"zone"`
+	ZoneID  string               `json:"zone_id"`
+	Record  cloudflare.DNSRecord `json:"record"`
+
+	client *client.Client
+}
+
+// searchFilter holds the dns search criteria. Empty fields match anything;
+// Name, Content and Comment are glob patterns.
+type searchFilter struct {
+	Zone    string
+	Name    string
+	Content string
+	Comment string
+	Types   []string
+	Proxied *bool
+}
+
+func (f searchFilter) empty() bool {
+	return f.Name == "" && f.Content == "" && f.Comment == "" && len(f.Types) == 0 && f.Proxied == nil
+}
+
+// params pushes the exact parts of the filter to the API so fewer records
+// are listed; match still applies the whole filter.
+func (f searchFilter) params() cloudflare.ListDNSRecordsParams {
+	var params cloudflare.ListDNSRecordsParams
+	if len(f.Types) == 1 {
+		params.Type = f.Types[0]
+	}
+	if f.Name != "" && !isGlob(f.Name) {
+		params.Name = strings.ToLower(f.Name)
+	}
+	if f.Content != "" && !isGlob(f.Content) {
+		params.Content = f.Content
+	}
+	params.Proxied = f.Proxied
+	return params
+}
+
+func (f searchFilter) match(rec cloudflare.DNSRecord) bool {
+	if len(f.Types) > 0 && !contains(f.Types, rec.Type) {
+		return false
+	}
+	if f.Name != "" && !globMatch(strings.ToLower(f.Name), strings.ToLower(rec.Name)) {
+		return false
+	}
+	if f.Content != "" && !globMatch(f.Content, rec.Content) {
+		return false
+	}
+	if f.Comment != "" && !globMatch(f.Comment, rec.Comment) {
+		return false
+	}
+	if f.Proxied != nil && (rec.Proxied != nil && *rec.Proxied) != *f.Proxied {
+		return false
+	}
+	return true
+}
+
+var dnsSearchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Find DNS records across zones and accounts",
+	Long: `Search the DNS records of every zone the account can access, or of every
+configured account with --all-accounts. Zones are searched concurrently.
+
+--name, --content, --comment and --zone take glob patterns (*, ?, [...]);
+name and zone matching is case-insensitive. All given filters must match.
+
+With --replace-content the content of every match is rewritten after a
+confirmation showing each change. Structured records (SRV, CAA, ...) cannot
+be rewritten this way.`,
+	Example: `  cfm dns search --content 192.0.2.10 --all-accounts
+  cfm dns search --name '*.staging.*' --type A,AAAA --proxied=false
+  cfm dns search --content 192.0.2.10 --replace-content 198.51.100.20`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		allAccounts, _ := cmd.Flags().GetBool("all-accounts")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		replace, _ := cmd.Flags().GetString("replace-content")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		filter := searchFilter{}
+		filter.Zone, _ = cmd.Flags().GetString("zone")
+		filter.Name, _ = cmd.Flags().GetString("name")
+		filter.Content, _ = cmd.Flags().GetString("content")
+		filter.Comment, _ = cmd.Flags().GetString("comment")
+		types, _ := cmd.Flags().GetStringSlice("type")
+		for _, t := range types {
+			filter.Types = append(filter.Types, strings.ToUpper(t))
+		}
+		if cmd.Flags().Changed("proxied") {
+			proxied, _ := cmd.Flags().GetBool("proxied")
+			filter.Proxied = &proxied
+		}
+		if filter.empty() {
+			return fmt.Errorf("give at least one of --name, --content, --comment, --type or --proxied")
+		}
+		for _, pattern := range []string{filter.Zone, filter.Name, filter.Content, filter.Comment} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+		if parallel < 1 {
+			parallel = 1
+		}
+
+		accounts, err := searchAccounts(allAccounts)
+		if err != nil {
+			return err
+		}
+
+		matches, errs := searchDNS(accounts, filter, parallel)
+		for _, err := range errs {
+			fmt.Printf("✗ %v\n", err)
+		}
+
+		if replace != "" {
+			if err := replaceContent(cmd, matches, replace, parallel, yes); err != nil {
+				return err
+			}
+		} else if err := renderSearchMatches(matches, len(accounts) > 1); err != nil {
+			return err
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("%d zones or accounts could not be searched", len(errs))
+		}
+		return nil
+	},
+}
+
+func searchAccounts(all bool) ([]config.Account, error) {
+	if !all {
+		account, err := config.ActiveAccount()
+		if err != nil {
+			return nil, err
+		}
+		return []config.Account{*account}, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Accounts) == 0 {
+		return nil, fmt.Errorf("no accounts configured")
+	}
+	return cfg.Accounts, nil
+}
+
+// searchDNS lists the zones of each account and searches them with at most
+// parallel requests in flight. A zone reachable from several accounts is
+// searched once.
+func searchDNS(accounts []config.Account, filter searchFilter, parallel int) ([]searchMatch, []error) {
+	type job struct {
+		client  *client.Client
+		account string
+		zone    cloudflare.Zone
+	}
+
+	var jobs []job
+	var errs []error
+	seen := map[string]bool{}
+	for i := range accounts {
+		account := &accounts[i]
+		c, err := client.New(account)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", account.Name, err))
+			continue
+		}
+		res, err := c.API.ListZonesContext(c.Context, cloudflare.WithZoneFilters("", account.AccountID, ""))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to list zones: %w", account.Name, err))
+			continue
+		}
+		for _, zone := range res.Result {
+			if seen[zone.ID] || (filter.Zone != "" && !globMatch(strings.ToLower(filter.Zone), zone.Name)) {
+				continue
+			}
+			seen[zone.ID] = true
+			jobs = append(jobs, job{client: c, account: account.Name, zone: zone})
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		matches []searchMatch
+		sem     = make(chan struct{}, parallel)
+	)
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			records, _, err := j.client.API.ListDNSRecords(j.client.Context, cloudflare.ZoneIdentifier(j.zone.ID), filter.params())
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: failed to list DNS records: %w", j.zone.Name, err))
+				return
+			}
+			for _, rec := range records {
+				if filter.match(rec) {
+					matches = append(matches, searchMatch{Account: j.account, Zone: j.zone.Name, ZoneID: j.zone.ID, Record: rec, client: j.client})
+				}
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Account != b.Account {
+			return a.Account < b.Account
+		}
+		if a.Zone != b.Zone {
+			return a.Zone < b.Zone
+		}
+		if a.Record.Name != b.Record.Name {
+			return a.Record.Name < b.Record.Name
+		}
+		return a.Record.Type < b.Record.Type
+	})
+	return matches, errs
+}
+
+func renderSearchMatches(matches []searchMatch, showAccount bool) error {
+	headers := []string{"ZONE", "TYPE", "NAME", "CONTENT", "PROXIED", "ID"}
+	if showAccount {
+		headers = append([]string{"ACCOUNT"}, headers...)
+	}
+	var rows [][]string
+	for _, m := range matches {
+		proxied := fmt.Sprintf("%t", m.Record.Proxied != nil && *m.Record.Proxied)
+		row := []string{m.Zone, m.Record.Type, m.Record.Name, recordContent(m.Record.Type, m.Record.Content, m.Record.Priority, m.Record.Data), proxied, m.Record.ID}
+		if showAccount {
+			row = append([]string{m.Account}, row...)
+		}
+		rows = append(rows, row)
+	}
+
+	return utils.Render(utils.View{
+		Data:     matches,
+		Headers:  headers,
+		Rows:     rows,
+		MaxWidth: map[string]int{"CONTENT": 40, "ID": 12},
+		Empty:    "No matching DNS records found.",
+		Footer:   fmt.Sprintf("\n%d matching records", len(matches)),
+	})
+}
+
+// replaceContent rewrites the content of every match after showing the
+// changes and asking for confirmation.
+func replaceContent(cmd *cobra.Command, matches []searchMatch, content string, parallel int, yes bool) error {
+	if len(matches) == 0 {
+		fmt.Println("No matching DNS records found.")
+		return nil
+	}
+
+	var issues []validate.Issue
+	for _, m := range matches {
+		if zonefile.Structured(m.Record.Type) {
+			return fmt.Errorf("%s %s is a structured record; --replace-content only rewrites records with plain content", m.Record.Type, m.Record.Name)
+		}
+		rec := validate.FromDNSRecord(m.Record)
+		rec.Content = content
+		issues = append(issues, validate.Check(rec)...)
+	}
+	if !skipValidation(cmd) {
+		if err := preflight(issues); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range matches {
+		fmt.Printf("  ~ %-6s %s  %s → %s  (%s)\n", m.Record.Type, m.Record.Name, m.Record.Content, content, m.Zone)
+	}
+	fmt.Printf("\n%d records will be updated.\n", len(matches))
+	if !yes && !utils.Confirm("Replace the content of these records?") {
+		fmt.Println("Replace cancelled.")
+		return nil
+	}
+
+	snapshotted := map[string]bool{}
+	for _, m := range matches {
+		if !snapshotted[m.ZoneID] {
+			snapshotted[m.ZoneID] = true
+			if err := autoSnapshot(cmd, m.client, m.ZoneID); err != nil {
+				return err
+			}
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed int
+		sem    = make(chan struct{}, parallel)
+	)
+	for _, m := range matches {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(m searchMatch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			_, err := m.client.API.UpdateDNSRecord(m.client.Context, cloudflare.ZoneIdentifier(m.ZoneID), cloudflare.UpdateDNSRecordParams{
+				ID:       m.Record.ID,
+				Type:     m.Record.Type,
+				Name:     m.Record.Name,
+				Content:  content,
+				TTL:      m.Record.TTL,
+				Proxied:  m.Record.Proxied,
+				Priority: m.Record.Priority,
+				Tags:     m.Record.Tags,
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				fmt.Printf("✗ %s %s (%s): %v\n", m.Record.Type, m.Record.Name, m.Zone, err)
+			}
+		}(m)
+	}
+	wg.Wait()
+
+	fmt.Printf("✓ Updated %d records, %d failed\n", len(matches)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d records could not be updated", failed)
+	}
+	return nil
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[\\")
+}
+
+// globMatch matches with path.Match semantics, except that * also spans
+// dots and slashes.
+func globMatch(pattern, s string) bool {
+	ok, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(s, "/", "\x00"))
+	return ok
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	dnsSearchCmd.Flags().String("name", "", "Record name glob, e.g. '*.example.com'")
+	dnsSearchCmd.Flags().String("content", "", "Record content glob, e.g. 192.0.2.10 or '192.0.2.*'")
+	dnsSearchCmd.Flags().String("comment", "", "Record comment glob")
+	dnsSearchCmd.Flags().StringSliceP("type", "t", nil, "Record types, e.g. A,AAAA")
+	dnsSearchCmd.Flags().Bool("proxied", false, "Only proxied records (--proxied=false for DNS-only records)")
+	dnsSearchCmd.Flags().String("zone", "", "Only search zones whose name matches this glob")
+	dnsSearchCmd.Flags().Bool("all-accounts", false, "Search every configured account")
+	dnsSearchCmd.Flags().Int("parallel", 4, "Zones searched at the same time")
+	dnsSearchCmd.Flags().String("replace-content", "", "Replace the content of every match with this value")
+	dnsSearchCmd.Flags().BoolP("yes", "y", false, "Replace without asking for confirmation")
+	dnsSearchCmd.Flags().Bool("skip-validation", false, "Replace even if the new content fails validation")
+
+	DNSCmd.AddCommand(dnsSearchCmd)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSSearch(t *testing.T) {
+	e := newTestEnv(t)
+	other := e.addAccount("other")
+
+	proxied := true
+	one := e.api.AddZone(e.account.ID, "example.com")
+	two := e.api.AddZone(e.account.ID, "example.net")
+	three := e.api.AddZone(other.ID, "example.org")
+	e.api.AddDNSRecord(one.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.10", Proxied: &proxied, Comment: "old web server"})
+	e.api.AddDNSRecord(one.ID, cloudflare.DNSRecord{Type: "A", Name: "api", Content: "192.0.2.11"})
+	e.api.AddDNSRecord(two.ID, cloudflare.DNSRecord{Type: "A", Name: "@", Content: "192.0.2.10", TTL: 300})
+	e.api.AddDNSRecord(two.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "192.0.2.10"})
+	e.api.AddDNSRecord(three.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.10"})
+
+	msg := e.mustFail("dns", "search")
+	assertContains(t, msg, "give at least one of")
+
+	out := e.mustRun("dns", "search", "--content", "192.0.2.10", "--type", "a", "-o", "csv")
+	assertContains(t, out, "example.com,A,www.example.com,192.0.2.10,true", "example.net,A,example.net,192.0.2.10,false")
+	if strings.Contains(out, "example.org") || strings.Contains(out, "TXT") {
+		t.Errorf("search matched records outside the filter:\n%s", out)
+	}
+
+	out = e.mustRun("dns", "search", "--name", "WWW.*", "--all-accounts", "-o", "csv")
+	assertContains(t, out, "test,example.com,A,www.example.com", "other,example.org,A,www.example.org")
+
+	out = e.mustRun("dns", "search", "--content", "192.0.2.1?", "--proxied=false", "--zone", "*.com", "-o", "csv")
+	assertContains(t, out, "api.example.com")
+	if strings.Contains(out, "www.example.com") {
+		t.Errorf("--proxied=false matched a proxied record:\n%s", out)
+	}
+
+	out = e.mustRun("dns", "search", "--comment", "*web*", "-o", "csv")
+	assertContains(t, out, "www.example.com")
+	if strings.Count(out, "\n") != 2 {
+		t.Errorf("--comment matched more than one record:\n%s", out)
+	}
+
+	msg = e.mustFail("dns", "search", "--content", "192.0.2.10", "--type", "A", "--replace-content", "not-an-ip", "--yes")
+	assertContains(t, msg, "not an IPv4 address")
+
+	out = e.mustRun("dns", "search", "--content", "192.0.2.10", "--type", "A", "--all-accounts", "--replace-content", "198.51.100.20", "--yes")
+	assertContains(t, out, "~ A      www.example.com  192.0.2.10 → 198.51.100.20", "✓ Updated 3 records, 0 failed")
+
+	records := recordsByName(e.api.DNSRecords(two.ID))
+	if got := records["A example.net"]; got.Content != "198.51.100.20" || got.TTL != 300 {
+		t.Errorf("replaced record = %+v, want new content with TTL kept", got)
+	}
+	if got := records["TXT example.net"]; got.Content != "192.0.2.10" {
+		t.Errorf("TXT record was rewritten: %+v", got)
+	}
+	if got := recordsByName(e.api.DNSRecords(one.ID))["A www.example.com"]; got.Proxied == nil || !*got.Proxied || got.Comment != "old web server" {
+		t.Errorf("replace lost record settings: %+v", got)
+	}
+}
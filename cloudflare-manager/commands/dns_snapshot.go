@@ -0,0 +1,325 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare-manager/snapshot"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+var dnsSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [zone-id or domain]...",
+	Short: "Save a snapshot of a zone's DNS records",
+	Long: `Save every DNS record of the given zones, with all their fields, as a
+timestamped JSON file in the local state directory ($CFM_STATE_DIR, or
+~/.local/state/cloudflare-manager). Snapshots can be listed with dns history,
+compared with dns diff and restored with dns restore.
+
+Mutating dns commands take a snapshot first when given --auto-snapshot or
+when auto_snapshot: true is set in the config file.`,
+	Example: `  cfm dns snapshot example.com --note "before migration"
+  cfm dns snapshot --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		note, _ := cmd.Flags().GetString("note")
+		if all == (len(args) > 0) {
+			return fmt.Errorf("give one or more zones, or --all")
+		}
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		var zoneIDs []string
+		if all {
+			res, err := c.API.ListZonesContext(c.Context, cloudflare.WithZoneFilters("", c.Account.AccountID, ""))
+			if err != nil {
+				return fmt.Errorf("failed to list zones: %w", err)
+			}
+			for _, z := range res.Result {
+				zoneIDs = append(zoneIDs, z.ID)
+			}
+		} else {
+			for _, arg := range args {
+				zoneID, err := getZoneID(c, arg)
+				if err != nil {
+					return err
+				}
+				zoneIDs = append(zoneIDs, zoneID)
+			}
+		}
+
+		for _, zoneID := range zoneIDs {
+			s, err := takeSnapshot(c, zoneID, note)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ Saved snapshot %s (%d records)\n", s.ID, len(s.Records))
+		}
+		return nil
+	},
+}
+
+// historyEntry is a snapshot without its records, as listed by dns history.
+type historyEntry struct {
+	ID      string    `json:"id"`
+	Zone    string    `json:"zone"`
+	ZoneID  string    `json:"zone_id"`
+	Account string    `json:"account,omitempty"`
+	Taken   time.Time `json:"taken"`
+	Note    string    `json:"note,omitempty"`
+	Records int       `json:"records"`
+}
+
+var dnsHistoryCmd = &cobra.Command{
+	Use:   "history [domain]",
+	Short: "List saved DNS snapshots",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		zone := ""
+		if len(args) > 0 {
+			zone = strings.ToLower(strings.TrimSuffix(args[0], "."))
+		}
+
+		store, err := snapshotStore()
+		if err != nil {
+			return err
+		}
+		snaps, err := store.List(zone)
+		if err != nil {
+			return err
+		}
+
+		entries := make([]historyEntry, 0, len(snaps))
+		var rows [][]string
+		for _, s := range snaps {
+			entries = append(entries, historyEntry{ID: s.ID, Zone: s.Zone, ZoneID: s.ZoneID, Account: s.Account, Taken: s.Taken, Note: s.Note, Records: len(s.Records)})
+			rows = append(rows, []string{s.ID, s.Taken.Local().Format("2006-01-02 15:04:05"), fmt.Sprintf("%d", len(s.Records)), s.Note})
+		}
+
+		return utils.Render(utils.View{
+			Data:     entries,
+			Headers:  []string{"ID", "TAKEN", "RECORDS", "NOTE"},
+			Rows:     rows,
+			MaxWidth: map[string]int{"NOTE": 40},
+			Empty:    "No snapshots found.",
+		})
+	},
+}
+
+var dnsDiffCmd = &cobra.Command{
+	Use:   "diff [snapshot] [snapshot or live]",
+	Short: "Compare a snapshot with another snapshot or the live zone",
+	Long: `Show the records added, changed and removed between two snapshots, or
+between a snapshot and the zone's current records when the second argument
+is "live". Snapshots are given by ID (as shown by dns history), by zone name
+for the latest snapshot of that zone, or by file path.`,
+	Example: `  cfm dns diff example.com@20240301T120000Z live
+  cfm dns diff example.com@20240301T120000Z example.com@20240302T090000Z`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := snapshotStore()
+		if err != nil {
+			return err
+		}
+		from, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		var to *snapshot.Snapshot
+		if args[1] == "live" {
+			c, err := client.NewFromConfig()
+			if err != nil {
+				return err
+			}
+			zoneID, err := getZoneID(c, from.Zone)
+			if err != nil {
+				return err
+			}
+			records, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+			if err != nil {
+				return fmt.Errorf("failed to list DNS records: %w", err)
+			}
+			to = &snapshot.Snapshot{ID: "live", Zone: from.Zone, Records: records}
+		} else if to, err = store.Load(args[1]); err != nil {
+			return err
+		}
+		if to.Zone != from.Zone {
+			fmt.Printf("Note: comparing different zones (%s and %s)\n", from.Zone, to.Zone)
+		}
+
+		plan := snapshotPlan(from.Zone, to, from.Records)
+		fmt.Printf("--- %s\n+++ %s\n", from.ID, to.ID)
+		if plan.Empty() {
+			fmt.Println("No differences.")
+			return nil
+		}
+		plan.PrintChanges(os.Stdout)
+		fmt.Printf("\n%d added, %d changed, %d removed\n", plan.Count(dnssync.Create), plan.Count(dnssync.Update), plan.Count(dnssync.Delete))
+		return nil
+	},
+}
+
+var dnsRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshot]",
+	Short: "Reconcile a zone back to a snapshot",
+	Long: `Create, update and delete DNS records so that the zone matches a snapshot
+again, including TTLs, proxy status, comments and tags. A snapshot of the
+current records is saved first so the restore itself can be undone.`,
+	Example: `  cfm dns restore example.com@20240301T120000Z
+  cfm dns restore example.com --yes   # latest snapshot of example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		store, err := snapshotStore()
+		if err != nil {
+			return err
+		}
+		snap, err := store.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+		zoneID, err := getZoneID(c, snap.Zone)
+		if err != nil {
+			return err
+		}
+		current, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+		if err != nil {
+			return fmt.Errorf("failed to list DNS records: %w", err)
+		}
+
+		plan := snapshotPlan(snap.Zone, snap, current)
+		plan.Print(os.Stdout)
+		if plan.Empty() {
+			fmt.Printf("\nNo changes. %s already matches %s.\n", snap.Zone, snap.ID)
+			return nil
+		}
+		if dryRun {
+			return nil
+		}
+
+		fmt.Println()
+		if !yes && !utils.Confirm(fmt.Sprintf("Restore %s to %s?", snap.Zone, snap.ID)) {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+
+		backup, err := takeSnapshot(c, zoneID, "before restore of "+snap.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Saved snapshot %s\n", backup.ID)
+
+		res := dnssync.Apply(c.Context, c.API, zoneID, plan)
+		for _, err := range res.Errors {
+			fmt.Printf("✗ %v\n", err)
+		}
+		fmt.Printf("✓ Restore finished: %d created, %d updated, %d deleted, %d failed\n", res.Created, res.Updated, res.Deleted, res.Failed)
+		if res.Failed > 0 {
+			return fmt.Errorf("%d changes failed", res.Failed)
+		}
+		return nil
+	},
+}
+
+// snapshotPlan returns the changes that turn the current records into the
+// records of target.
+func snapshotPlan(zone string, target *snapshot.Snapshot, current []cloudflare.DNSRecord) dnssync.Plan {
+	desired := make([]dnssync.Record, 0, len(target.Records))
+	for _, rec := range target.Records {
+		desired = append(desired, dnssync.FromDNSRecord(rec))
+	}
+	return dnssync.Diff(zone, desired, current, true)
+}
+
+func snapshotStore() (*snapshot.Store, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find state directory: %w", err)
+	}
+	return snapshot.NewStore(filepath.Join(dir, "snapshots")), nil
+}
+
+// takeSnapshot saves the current DNS records of a zone.
+func takeSnapshot(c *client.Client, zoneID, note string) (*snapshot.Snapshot, error) {
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone info: %w", err)
+	}
+	records, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNS records: %w", err)
+	}
+
+	store, err := snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	s := &snapshot.Snapshot{
+		Zone:    zone.Name,
+		ZoneID:  zone.ID,
+		Account: c.Account.Name,
+		Note:    note,
+		Records: records,
+	}
+	if err := store.Save(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// autoSnapshot saves a snapshot of the zone before a mutating command when
+// --auto-snapshot is given or auto_snapshot is set in the config file.
+func autoSnapshot(cmd *cobra.Command, c *client.Client, zoneID string) error {
+	enabled, _ := cmd.Flags().GetBool("auto-snapshot")
+	if !cmd.Flags().Changed("auto-snapshot") {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		enabled = cfg.AutoSnapshot
+	}
+	if !enabled {
+		return nil
+	}
+
+	s, err := takeSnapshot(c, zoneID, "before dns "+cmd.Name())
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	fmt.Printf("✓ Saved snapshot %s\n", s.ID)
+	return nil
+}
+
+func init() {
+	dnsSnapshotCmd.Flags().Bool("all", false, "Snapshot every zone of the account")
+	dnsSnapshotCmd.Flags().String("note", "", "Note stored with the snapshot")
+
+	dnsRestoreCmd.Flags().BoolP("yes", "y", false, "Restore without asking for confirmation")
+	dnsRestoreCmd.Flags().Bool("dry-run", false, "Only show the changes")
+
+	DNSCmd.PersistentFlags().Bool("auto-snapshot", false, "Save a snapshot of the zone before changing it (default from auto_snapshot in the config file)")
+
+	DNSCmd.AddCommand(dnsSnapshotCmd)
+	DNSCmd.AddCommand(dnsHistoryCmd)
+	DNSCmd.AddCommand(dnsDiffCmd)
+	DNSCmd.AddCommand(dnsRestoreCmd)
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSSnapshotRestore(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	proxied := true
+	priority := uint16(10)
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.1", Proxied: &proxied, TTL: 1, Comment: "web"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "MX", Name: "@", Content: "mail.example.com", Priority: &priority, TTL: 300})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "v=spf1 mx -all", TTL: 300, Tags: []string{"team:mail"}})
+	e.mustRun("dns", "create", "example.com", "CAA", "@", `0 issue "letsencrypt.org"`)
+
+	out := e.mustRun("dns", "snapshot", "example.com", "--note", "baseline")
+	assertContains(t, out, "✓ Saved snapshot example.com@", "(4 records)")
+	id := strings.Fields(out)[3]
+
+	before := recordsByName(e.api.DNSRecords(zone.ID))
+	e.mustRun("dns", "set", "example.com", "www", "A", "192.0.2.2", "--proxied=false")
+	e.mustRun("dns", "rm", "example.com", "@", "--type", "TXT")
+	out = e.mustRun("dns", "create", "example.com", "A", "new", "192.0.2.3", "--auto-snapshot")
+	assertContains(t, out, "✓ Saved snapshot example.com@")
+
+	out = e.mustRun("dns", "history", "example.com", "-o", "csv")
+	assertContains(t, out, id+",", ",baseline", "before dns create")
+
+	out = e.mustRun("dns", "diff", id, "live")
+	assertContains(t, out,
+		"--- "+id, "+++ live",
+		"- A      www.example.com  192.0.2.1",
+		"+ A      www.example.com  192.0.2.2",
+		"- TXT    example.com  v=spf1 mx -all",
+		"+ A      new.example.com  192.0.2.3",
+		"2 added, 0 changed, 2 removed",
+	)
+	out = e.mustRun("dns", "diff", id, "example.com@"+strings.SplitN(id, "@", 2)[1])
+	assertContains(t, out, "No differences.")
+
+	out = e.mustRun("dns", "restore", id, "--dry-run")
+	assertContains(t, out, "Plan: 2 to add, 0 to change, 2 to destroy.")
+	if len(e.api.DNSRecords(zone.ID)) != 4 {
+		t.Fatal("restore --dry-run changed the zone")
+	}
+
+	out = e.mustRun("dns", "restore", id, "--yes")
+	assertContains(t, out, "✓ Saved snapshot example.com@", "✓ Restore finished: 2 created, 0 updated, 2 deleted, 0 failed")
+
+	after := recordsByName(e.api.DNSRecords(zone.ID))
+	if len(after) != len(before) {
+		t.Fatalf("restored zone has %d records, want %d: %v", len(after), len(before), after)
+	}
+	for key, want := range before {
+		got, ok := after[key]
+		if !ok {
+			t.Errorf("%s missing after restore", key)
+			continue
+		}
+		if got.Content != want.Content || got.TTL != want.TTL || *got.Proxied != *want.Proxied || got.Comment != want.Comment || strings.Join(got.Tags, ",") != strings.Join(want.Tags, ",") {
+			t.Errorf("%s restored as %+v, want %+v", key, got, want)
+		}
+	}
+	if got := after["MX example.com"]; got.Priority == nil || *got.Priority != 10 {
+		t.Errorf("MX priority not restored: %+v", got)
+	}
+
+	out = e.mustRun("dns", "diff", id, "live")
+	assertContains(t, out, "No differences.")
+	// The latest snapshot is the one taken before the restore, which undoes it.
+	out = e.mustRun("dns", "restore", "example.com", "--dry-run")
+	assertContains(t, out, "Plan: 2 to add, 0 to change, 2 to destroy.")
+}
+
+func TestDNSSnapshotAll(t *testing.T) {
+	e := newTestEnv(t)
+	e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddZone(e.account.ID, "example.org")
+	other := e.api.AddAccount("other")
+	e.api.AddZone(other.ID, "other.net")
+
+	out := e.mustRun("dns", "snapshot", "--all")
+	assertContains(t, out, "✓ Saved snapshot example.com@", "✓ Saved snapshot example.org@")
+	if strings.Contains(out, "other.net") {
+		t.Errorf("--all snapshotted a zone of another account:\n%s", out)
+	}
+}
+
+func TestDNSAutoSnapshotConfig(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	out := e.mustRun("dns", "rm", "example.com", "www")
+	if strings.Contains(out, "Saved snapshot") {
+		t.Errorf("snapshot taken without auto_snapshot:\n%s", out)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AutoSnapshot = true
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	out = e.mustRun("dns", "create", "example.com", "A", "www", "192.0.2.1")
+	assertContains(t, out, "✓ Saved snapshot example.com@")
+	out = e.mustRun("dns", "create", "example.com", "A", "api", "192.0.2.2", "--auto-snapshot=false")
+	if strings.Contains(out, "Saved snapshot") {
+		t.Errorf("--auto-snapshot=false did not override the config:\n%s", out)
+	}
+
+	out = e.mustRun("dns", "history", "-o", "csv")
+	assertContains(t, out, "before dns create")
+	if n := strings.Count(out, "example.com@"); n != 1 {
+		t.Errorf("history has %d snapshots, want 1:\n%s", n, out)
+	}
+	e.mustFail("dns", "diff", "example.com@20000101T000000Z", "live")
+}
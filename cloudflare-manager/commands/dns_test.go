@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestDNSCommands(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	out := e.mustRun("dns", "create", "example.com", "A", "www", "192.0.2.1", "--proxied")
+	assertContains(t, out, "DNS record created successfully", "www.example.com", "192.0.2.1")
+	e.mustRun("dns", "create", "example.com", "MX", "@", "mail.example.com", "--priority", "5")
+
+	records := e.api.DNSRecords(zone.ID)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Proxied == nil || !*records[0].Proxied {
+		t.Errorf("A record is not proxied: %+v", records[0])
+	}
+	if records[1].Priority == nil || *records[1].Priority != 5 {
+		t.Errorf("MX priority = %v, want 5", records[1].Priority)
+	}
+
+	out = e.mustRun("dns", "list", "example.com", "--type", "MX", "-o", "csv")
+	assertContains(t, out, "TYPE,NAME,CONTENT", "MX,example.com,mail.example.com")
+	if got := e.mustRun("dns", "list", "example.com", "--template", "{{.Name}}"); got != "www.example.com\nexample.com\n" {
+		t.Errorf("dns list --template = %q", got)
+	}
+
+	e.mustRun("dns", "update", "example.com", records[0].ID, "192.0.2.2", "--ttl", "300")
+	updated := e.api.DNSRecords(zone.ID)[0]
+	if updated.Content != "192.0.2.2" || updated.TTL != 300 {
+		t.Errorf("updated record = %s ttl %d, want 192.0.2.2 ttl 300", updated.Content, updated.TTL)
+	}
+
+	// Updates that don't give --ttl or --proxied keep them.
+	e.mustRun("dns", "update", "example.com", records[0].ID, "--comment", "hi")
+	updated = e.api.DNSRecords(zone.ID)[0]
+	proxied := updated.Proxied != nil && *updated.Proxied
+	if updated.Comment != "hi" || updated.TTL != 300 || !proxied {
+		t.Errorf("after a comment-only update: comment %q ttl %d proxied %v, want hi, 300, true", updated.Comment, updated.TTL, proxied)
+	}
+	e.mustRun("dns", "update", "example.com", records[0].ID, "--proxied=false")
+	if updated = e.api.DNSRecords(zone.ID)[0]; updated.Proxied != nil && *updated.Proxied {
+		t.Errorf("record still proxied after --proxied=false")
+	}
+
+	out = e.mustRun("dns", "export", "example.com")
+	assertContains(t, out, "$ORIGIN example.com.", "www.example.com.\t300\tIN\tA\t192.0.2.2")
+
+	e.mustRun("dns", "delete", "example.com", records[1].ID)
+	if n := len(e.api.DNSRecords(zone.ID)); n != 1 {
+		t.Errorf("got %d records after delete, want 1", n)
+	}
+
+	msg := e.mustFail("dns", "create", "example.com", "CNAME", "www", "target.example.net")
+	assertContains(t, msg, "CNAME www.example.com: CNAME cannot coexist with other records at the same name (A)")
+
+	msg = e.mustFail("dns", "create", "example.com", "CNAME", "www", "target.example.net", "--skip-validation")
+	assertContains(t, msg, "failed to create DNS record")
+}
+
+func TestDNSImport(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	bind := writeFile(t, "example.com.zone", `$ORIGIN example.com.
+$TTL 1h
+@     IN SOA ns1.example.net. admin.example.com. 1 7200 3600 1209600 3600
+@     IN NS  ns1.example.net.
+@     IN MX  10 mail
+www   IN A   192.0.2.1
+mail  IN A   192.0.2.25
+txt   IN TXT "v=spf1 -all"
+`)
+
+	out := e.mustRun("dns", "import", "example.com", bind, "--dry-run")
+	assertContains(t, out, "Dry run: 4 would be created, 2 skipped, 0 failed")
+	if n := len(e.api.DNSRecords(zone.ID)); n != 1 {
+		t.Fatalf("dry run created records: got %d, want 1", n)
+	}
+
+	// A dry run against a zone ID takes the origin from the zone's name.
+	relative := writeFile(t, "relative.zone", "$TTL 300\nwww IN A 192.0.2.9\n")
+	out = e.mustRun("dns", "import", zone.ID, relative, "--dry-run")
+	assertContains(t, out, "www.example.com")
+	if strings.Contains(out, zone.ID+"\t") || strings.Contains(out, "www."+zone.ID) {
+		t.Errorf("dry run used the zone ID as the origin:\n%s", out)
+	}
+
+	// www already exists, so pre-flight validation stops the import...
+	msg := e.mustFail("dns", "import", "example.com", bind)
+	assertContains(t, msg, "A www.example.com: duplicate record")
+	if n := len(e.api.DNSRecords(zone.ID)); n != 1 {
+		t.Fatalf("failed validation created records: got %d, want 1", n)
+	}
+
+	// ...and without it the API rejects just that record.
+	out, err := e.run("dns", "import", "example.com", bind, "--skip-validation")
+	if err == nil {
+		t.Fatalf("import succeeded despite a duplicate record\n%s", out)
+	}
+	assertContains(t, out, "3 created, 2 skipped, 1 failed")
+
+	var mx *cloudflare.DNSRecord
+	for _, rec := range e.api.DNSRecords(zone.ID) {
+		if rec.Type == "MX" {
+			rec := rec
+			mx = &rec
+		}
+	}
+	if mx == nil || mx.Content != "mail.example.com" || mx.TTL != 3600 {
+		t.Errorf("imported MX = %+v, want mail.example.com with TTL 3600", mx)
+	}
+}
+
+func TestDNSCreateStructured(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	e.mustRun("dns", "create", "example.com", "SRV", "_sip._tcp", "10 5 5060 sip.example.com.", "--comment", "voip", "--tag", "team:voice")
+	e.mustRun("dns", "create", "example.com", "SRV", "@", "--data", "service=_xmpp", "--data", "proto=tcp",
+		"--data", "weight=0", "--data", "port=5222", "--data", "target=xmpp.example.com", "--priority", "20")
+	e.mustRun("dns", "create", "example.com", "CAA", "@", `0 issue "letsencrypt.org; validationmethods=dns-01"`)
+	e.mustRun("dns", "create", "example.com", "HTTPS", "@", `1 . alpn="h3,h2" ipv4hint="192.0.2.1"`)
+	e.mustRun("dns", "create", "example.com", "TLSA", "_443._tcp.www", "3 1 1 0123ABCD")
+	e.mustRun("dns", "create", "example.com", "LOC", "@", "51 30 12.748 N 0 7 39.611 W 0m")
+	e.mustRun("dns", "create", "example.com", "URI", "_ftp._tcp", `10 1 "ftp://ftp.example.com/public"`)
+
+	records := recordsByName(e.api.DNSRecords(zone.ID))
+	srv := records["SRV _sip._tcp.example.com"]
+	if srv.Priority == nil || *srv.Priority != 10 || srv.Content != "5 5060 sip.example.com" {
+		t.Errorf("SRV = priority %v content %q, want 10 and 5 5060 sip.example.com", srv.Priority, srv.Content)
+	}
+	if srv.Comment != "voip" || len(srv.Tags) != 1 || srv.Tags[0] != "team:voice" {
+		t.Errorf("SRV comment/tags = %q %v", srv.Comment, srv.Tags)
+	}
+	if xmpp := records["SRV _xmpp._tcp.example.com"]; xmpp.Priority == nil || *xmpp.Priority != 20 || xmpp.Content != "0 5222 xmpp.example.com" {
+		t.Errorf("SRV from --data = priority %v content %q", xmpp.Priority, xmpp.Content)
+	}
+	uri := records["URI _ftp._tcp.example.com"]
+	if uri.Priority == nil || *uri.Priority != 10 {
+		t.Errorf("URI priority = %v, want 10", uri.Priority)
+	}
+	if data, _ := uri.Data.(map[string]interface{}); data["priority"] != nil || data["target"] != "ftp://ftp.example.com/public" {
+		t.Errorf("URI data = %v, want weight and target only", uri.Data)
+	}
+
+	out := e.mustRun("dns", "list", "example.com", "-o", "csv")
+	assertContains(t, out,
+		"10 5 5060 sip.example.com",
+		`0 issue ""letsencrypt.org; validationmethods=dns-01"""`,
+		`1 . alpn=""h3,h2"" ipv4hint=""192.0.2.1""`,
+		"3 1 1 0123abcd",
+		"51 30 12.748 N 0 7 39.611 W 0.00m",
+		`10 1 ""ftp://ftp.example.com/public""`,
+	)
+
+	e.mustRun("dns", "update", "example.com", srv.ID, "--data", "port=5061", "--comment", "moved")
+	srv = recordsByName(e.api.DNSRecords(zone.ID))["SRV _sip._tcp.example.com"]
+	if srv.Content != "5 5061 sip.example.com" || srv.Comment != "moved" || len(srv.Tags) != 1 {
+		t.Errorf("updated SRV = %q comment %q tags %v", srv.Content, srv.Comment, srv.Tags)
+	}
+
+	msg := e.mustFail("dns", "create", "example.com", "SRV", "_sip._udp", "--data", "port=5060")
+	assertContains(t, msg, "SRV record is missing weight, target")
+	msg = e.mustFail("dns", "create", "example.com", "CAA", "@", "--data", "flag=0")
+	assertContains(t, msg, `unknown CAA data field "flag" (expected one of flags, tag, value)`)
+	msg = e.mustFail("dns", "create", "example.com", "A", "www", "--data", "ip=192.0.2.1")
+	assertContains(t, msg, "A records take their value as content, not --data")
+	msg = e.mustFail("dns", "create", "example.com", "LOC", "@", "51 30 X 0 7 W 0m")
+	assertContains(t, msg, `LOC lat_direction "X" must be one of N, S`)
+
+	bind := writeFile(t, "svc.zone", `$ORIGIN example.com.
+svc 300 IN HTTPS 1 svc.example.net. alpn="h2" port=8443
+svc 300 IN SVCB  0 pool.example.net.
+`)
+	out = e.mustRun("dns", "import", "example.com", bind, "--dry-run")
+	assertContains(t, out, `1 svc.example.net alpn="h2" port=8443`, "0 pool.example.net", "2 would be created")
+}
@@ -40,11 +40,6 @@ var kvNamespaceListCmd = &cobra.Command{
             return fmt.Errorf("failed to list KV namespaces: %w", err)
         }
 
-        if len(namespaces) == 0 {
-            fmt.Println("No KV namespaces found. Use 'kv namespace create' to create one.")
-            return nil
-        }
-
         headers := []string{"ID", "TITLE"}
         var rows [][]string
 
@@ -55,8 +50,12 @@ var kvNamespaceListCmd = &cobra.Command{
             })
         }
 
-        utils.PrintTable(headers, rows)
-        return nil
+        return utils.Render(utils.View{
+            Data:    namespaces,
+            Headers: headers,
+            Rows:    rows,
+            Empty:   "No KV namespaces found. Use 'kv namespace create' to create one.",
+        })
     },
 }
 
@@ -174,31 +173,15 @@ var kvKeyListCmd = &cobra.Command{
             return err
         }
 
-        rc := cloudflare.AccountIdentifier(accountID)
-        response, err := c.API.ListWorkersKVKeys(c.Context, rc, cloudflare.ListWorkersKVsParams{
-            NamespaceID: namespaceID,
+        _, err = streamList(cmd, "keys", c.KVKeyPages(accountID, namespaceID), utils.View{
+            Headers: []string{"NAME"},
+            Empty:   "No keys found.",
+        }, func(key cloudflare.StorageKey) []string {
+            return []string{key.Name}
+        }, func(n int) string {
+            return fmt.Sprintf("Total: %d keys", n)
         })
-        if err != nil {
-            return fmt.Errorf("failed to list KV keys: %w", err)
-        }
-
-        if len(response.Result) == 0 {
-            fmt.Println("No keys found.")
-            return nil
-        }
-
-        headers := []string{"NAME"}
-        var rows [][]string
-
-        for _, key := range response.Result {
-            rows = append(rows, []string{
-                key.Name,
-            })
-        }
-
-        utils.PrintTable(headers, rows)
-        fmt.Printf("\nTotal: %d keys\n", len(response.Result))
-        return nil
+        return err
     },
 }
 
@@ -308,6 +291,8 @@ func init() {
     kvNamespaceCmd.AddCommand(kvNamespaceDeleteCmd)
     kvNamespaceCmd.AddCommand(kvNamespaceRenameCmd)
 
+    addPaginationFlags(kvKeyListCmd, true)
+
     kvKeyCmd.AddCommand(kvKeyListCmd)
     kvKeyCmd.AddCommand(kvKeyGetCmd)
     kvKeyCmd.AddCommand(kvKeyPutCmd)
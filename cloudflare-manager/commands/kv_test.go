@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestKVCommands(t *testing.T) {
+	e := newTestEnv(t)
+
+	out := e.mustRun("kv", "namespace", "create", "settings")
+	assertContains(t, out, "KV namespace created successfully")
+	namespaces := e.api.KVNamespaces(e.account.ID)
+	if len(namespaces) != 1 {
+		t.Fatalf("got %d namespaces, want 1", len(namespaces))
+	}
+	ns := namespaces[0].ID
+
+	e.mustRun("kv", "namespace", "rename", ns, "config")
+	assertContains(t, e.mustRun("kv", "namespace", "list"), "config", ns)
+
+	e.mustRun("kv", "key", "put", ns, "greeting/en", "hello world")
+	if v, _ := e.api.KV(ns, "greeting/en"); v != "hello world" {
+		t.Errorf("stored value = %q, want hello world", v)
+	}
+	if got := e.mustRun("kv", "key", "get", ns, "greeting/en"); got != "hello world\n" {
+		t.Errorf("kv key get = %q", got)
+	}
+
+	out = e.mustRun("kv", "key", "list", ns)
+	assertContains(t, out, "greeting/en", "Total: 1 keys")
+
+	e.mustRun("kv", "key", "delete", ns, "greeting/en")
+	e.mustFail("kv", "key", "get", ns, "greeting/en")
+
+	e.mustRun("kv", "namespace", "delete", ns)
+	assertContains(t, e.mustRun("kv", "namespace", "list"), "No KV namespaces found.")
+}
+
+func TestKVKeyListPagination(t *testing.T) {
+	e := newTestEnv(t)
+	ns := e.api.AddKVNamespace(e.account.ID, "big").ID
+	for i := 0; i < 25; i++ {
+		e.api.PutKV(ns, fmt.Sprintf("key%02d", i), "v")
+	}
+
+	out := e.mustRun("kv", "key", "list", ns, "--limit", "10")
+	assertContains(t, out, "key00", "key09", "Total: 10 keys", "More keys available: continue with --cursor key09, or use --all.")
+	if strings.Contains(out, "key10") {
+		t.Errorf("--limit 10 listed more than 10 keys:\n%s", out)
+	}
+
+	out = e.mustRun("kv", "key", "list", ns, "--cursor", "key09", "--all")
+	assertContains(t, out, "key10", "key24", "Total: 15 keys")
+	if strings.Contains(out, "key09") || strings.Contains(out, "More keys") {
+		t.Errorf("listing from a cursor:\n%s", out)
+	}
+
+	// The API returns at least 10 keys, so a smaller limit cuts a page and
+	// cannot be continued by cursor.
+	out = e.mustRun("kv", "key", "list", ns, "--limit", "3")
+	assertContains(t, out, "key02", "Total: 3 keys", "More keys available: use --all to list them.")
+
+	out = e.mustRun("kv", "key", "list", ns, "--all", "-o", "json")
+	var keys []cloudflare.StorageKey
+	if err := json.Unmarshal([]byte(out), &keys); err != nil {
+		t.Fatalf("kv key list -o json: %v\n%s", err, out)
+	}
+	if len(keys) != 25 {
+		t.Errorf("kv key list --all returned %d keys, want 25", len(keys))
+	}
+}
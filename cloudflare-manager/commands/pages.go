@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cloudflare-manager/client"
 	"github.com/cloudflare-manager/utils"
@@ -35,11 +36,6 @@ var pagesListCmd = &cobra.Command{
 			return fmt.Errorf("failed to list pages projects: %w", err)
 		}
 
-		if len(projects) == 0 {
-			fmt.Println("No Pages projects found.")
-			return nil
-		}
-
 		headers := []string{"NAME", "SUBDOMAIN", "DOMAINS", "CREATED_ON"}
 		var rows [][]string
 
@@ -53,8 +49,12 @@ var pagesListCmd = &cobra.Command{
 			})
 		}
 
-		utils.PrintTable(headers, rows)
-		return nil
+		return utils.Render(utils.View{
+			Data:    projects,
+			Headers: headers,
+			Rows:    rows,
+			Empty:   "No Pages projects found.",
+		})
 	},
 }
 
@@ -81,20 +81,30 @@ var pagesInfoCmd = &cobra.Command{
 			return fmt.Errorf("failed to get pages project: %w", err)
 		}
 
-		fmt.Printf("Pages Project Information:\n")
-		fmt.Printf("  Name:         %s\n", project.Name)
-		fmt.Printf("  Subdomain:    %s.pages.dev\n", project.SubDomain)
-		fmt.Printf("  Created On:   %s\n", project.CreatedOn.Format("2006-01-02 15:04:05"))
-		fmt.Printf("\nDomains:\n")
-		if len(project.Domains) == 0 {
-			fmt.Printf("  No custom domains configured\n")
-		} else {
-			for _, domain := range project.Domains {
-				fmt.Printf("  - %s\n", domain)
-			}
-		}
-
-		return nil
+		return utils.Render(utils.View{
+			Data:    project,
+			Headers: []string{"NAME", "SUBDOMAIN", "CREATED_ON", "DOMAINS"},
+			Rows: [][]string{{
+				project.Name,
+				project.SubDomain,
+				project.CreatedOn.Format("2006-01-02 15:04:05"),
+				strings.Join(project.Domains, " "),
+			}},
+			Text: func() {
+				fmt.Printf("Pages Project Information:\n")
+				fmt.Printf("  Name:         %s\n", project.Name)
+				fmt.Printf("  Subdomain:    %s\n", project.SubDomain)
+				fmt.Printf("  Created On:   %s\n", project.CreatedOn.Format("2006-01-02 15:04:05"))
+				fmt.Printf("\nDomains:\n")
+				if len(project.Domains) == 0 {
+					fmt.Printf("  No custom domains configured\n")
+				} else {
+					for _, domain := range project.Domains {
+						fmt.Printf("  - %s\n", domain)
+					}
+				}
+			},
+		})
 	},
 }
 
@@ -147,33 +157,19 @@ var pagesDeploymentListCmd = &cobra.Command{
 			return err
 		}
 
-		rc := cloudflare.AccountIdentifier(accountID)
-		deployments, _, err := c.API.ListPagesDeployments(c.Context, rc, cloudflare.ListPagesDeploymentsParams{
-			ProjectName: projectName,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to list pages deployments: %w", err)
-		}
-
-		if len(deployments) == 0 {
-			fmt.Println("No deployments found.")
-			return nil
-		}
-
-		headers := []string{"ID", "ENVIRONMENT", "STATUS", "CREATED_ON"}
-		var rows [][]string
-
-		for _, deployment := range deployments {
-			rows = append(rows, []string{
-				utils.Truncate(deployment.ID, 12),
+		_, err = streamList(cmd, "deployments", c.PagesDeploymentPages(accountID, projectName), utils.View{
+			Headers:  []string{"ID", "ENVIRONMENT", "STATUS", "CREATED_ON"},
+			MaxWidth: map[string]int{"ID": 12},
+			Empty:    "No deployments found.",
+		}, func(deployment cloudflare.PagesProjectDeployment) []string {
+			return []string{
+				deployment.ID,
 				deployment.Environment,
 				deployment.LatestStage.Status,
 				deployment.CreatedOn.Format("2006-01-02 15:04:05"),
-			})
-		}
-
-		utils.PrintTable(headers, rows)
-		return nil
+			}
+		}, nil)
+		return err
 	},
 }
 
@@ -201,18 +197,31 @@ var pagesDeploymentInfoCmd = &cobra.Command{
 			return fmt.Errorf("failed to get deployment info: %w", err)
 		}
 
-		fmt.Printf("Deployment Information:\n")
-		fmt.Printf("  ID:          %s\n", deployment.ID)
-		fmt.Printf("  Environment: %s\n", deployment.Environment)
-		fmt.Printf("  Status:      %s\n", deployment.LatestStage.Status)
-		fmt.Printf("  URL:         %s\n", deployment.URL)
-		fmt.Printf("  Created On:  %s\n", deployment.CreatedOn.Format("2006-01-02 15:04:05"))
-
-		return nil
+		return utils.Render(utils.View{
+			Data:    deployment,
+			Headers: []string{"ID", "ENVIRONMENT", "STATUS", "URL", "CREATED_ON"},
+			Rows: [][]string{{
+				deployment.ID,
+				deployment.Environment,
+				deployment.LatestStage.Status,
+				deployment.URL,
+				deployment.CreatedOn.Format("2006-01-02 15:04:05"),
+			}},
+			Text: func() {
+				fmt.Printf("Deployment Information:\n")
+				fmt.Printf("  ID:          %s\n", deployment.ID)
+				fmt.Printf("  Environment: %s\n", deployment.Environment)
+				fmt.Printf("  Status:      %s\n", deployment.LatestStage.Status)
+				fmt.Printf("  URL:         %s\n", deployment.URL)
+				fmt.Printf("  Created On:  %s\n", deployment.CreatedOn.Format("2006-01-02 15:04:05"))
+			},
+		})
 	},
 }
 
 func init() {
+	addPaginationFlags(pagesDeploymentListCmd, false)
+
 	pagesDeploymentCmd.AddCommand(pagesDeploymentListCmd)
 	pagesDeploymentCmd.AddCommand(pagesDeploymentInfoCmd)
 
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestPagesCommands(t *testing.T) {
+	e := newTestEnv(t)
+	e.api.AddPagesProject(e.account.ID, cloudflare.PagesProject{Name: "site", Domains: []string{"www.example.com"}})
+	deployment := e.api.AddPagesDeployment(e.account.ID, "site", cloudflare.PagesProjectDeployment{
+		LatestStage: cloudflare.PagesProjectDeploymentStage{Name: "deploy", Status: "success"},
+	})
+
+	out := e.mustRun("pages", "list")
+	assertContains(t, out, "site")
+
+	out = e.mustRun("pages", "info", "site")
+	assertContains(t, out, "Pages Project Information:", "Subdomain:    site.pages.dev\n", "www.example.com")
+
+	out = e.mustRun("pages", "deployment", "list", "site")
+	assertContains(t, out, "production", "success")
+
+	out = e.mustRun("pages", "deployment", "info", "site", deployment.ID)
+	assertContains(t, out, "Deployment Information:", "success", deployment.URL)
+
+	e.mustRun("pages", "delete", "site")
+	assertContains(t, e.mustRun("pages", "list"), "No Pages projects found.")
+	e.mustFail("pages", "info", "site")
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/utils"
+	"github.com/spf13/cobra"
+)
+
+// addPaginationFlags adds --limit and --all to a list command, and --cursor
+// for cursor lists or --page for page-numbered ones.
+func addPaginationFlags(cmd *cobra.Command, cursor bool) {
+	cmd.Flags().Int("limit", 0, "List at most this many items, reading as many pages as needed")
+	cmd.Flags().Bool("all", false, "List every item, reading all pages")
+	if cursor {
+		cmd.Flags().String("cursor", "", "Continue a listing from this cursor")
+	} else {
+		cmd.Flags().Int("page", 1, "Start at this page; keep the same --limit when continuing a listing")
+	}
+}
+
+// allByDefault is the annotation set by readAllByDefault.
+const allByDefault = "all-by-default"
+
+// readAllByDefault makes a list command read every item unless --limit,
+// --page or --cursor asks for part of the list.
+func readAllByDefault(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[allByDefault] = "true"
+	cmd.Flags().Lookup("all").Usage = "List every item, reading all pages (the default without --limit or --page)"
+}
+
+// paginationFlags reads the flags added by addPaginationFlags.
+func paginationFlags(cmd *cobra.Command) (client.Pagination, error) {
+	var p client.Pagination
+	p.Limit, _ = cmd.Flags().GetInt("limit")
+	p.All, _ = cmd.Flags().GetBool("all")
+	switch {
+	case p.Limit < 0:
+		return p, fmt.Errorf("--limit must be positive")
+	case p.All && p.Limit > 0:
+		return p, fmt.Errorf("--all and --limit cannot be combined")
+	}
+
+	start := "page"
+	if cmd.Flags().Lookup("cursor") != nil {
+		start = "cursor"
+		p.Cursor, _ = cmd.Flags().GetString("cursor")
+	} else {
+		page, _ := cmd.Flags().GetInt("page")
+		if page < 1 {
+			return p, fmt.Errorf("--page must be at least 1")
+		}
+		if page > 1 {
+			p.Cursor = strconv.Itoa(page)
+		}
+	}
+	if cmd.Annotations[allByDefault] != "" && p.Limit == 0 && !cmd.Flags().Changed(start) {
+		p.All = true
+	}
+	return p, nil
+}
+
+// streamList reads a list with the command's pagination flags and renders
+// it as it arrives. view supplies the headers, widths and empty message;
+// footer, when set, builds the table footer from the number of items. When
+// items are left, how to continue is printed after the table, or to
+// standard error for other formats so the output stays parseable.
+func streamList[T any](cmd *cobra.Command, noun string, fetch client.PageFunc[T], view utils.View, row func(T) []string, footer func(n int) string) (client.Position, error) {
+	p, err := paginationFlags(cmd)
+	if err != nil {
+		return client.Position{}, err
+	}
+
+	stream := utils.NewStream(view)
+	pos, err := client.Paginate(p, fetch, func(item T) error {
+		return stream.Add(item, row(item))
+	})
+	if err != nil {
+		stream.Close("")
+		return pos, fmt.Errorf("failed to list %s: %w", noun, err)
+	}
+
+	var lines []string
+	if footer != nil {
+		lines = append(lines, footer(pos.Count))
+	}
+	hint := moreHint(cmd, noun, p, pos)
+	if hint != "" && utils.IsTableOutput() {
+		lines = append(lines, hint)
+	}
+	text := ""
+	if len(lines) > 0 {
+		text = "\n" + strings.Join(lines, "\n")
+	}
+	if err := stream.Close(text); err != nil {
+		return pos, err
+	}
+	if hint != "" && !utils.IsTableOutput() {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+	return pos, nil
+}
+
+// moreHint tells how to continue a listing that stopped early.
+func moreHint(cmd *cobra.Command, noun string, p client.Pagination, pos client.Position) string {
+	switch {
+	case !pos.More:
+		return ""
+	case pos.Next == "":
+		return fmt.Sprintf("More %s available: use --all to list them.", noun)
+	}
+
+	next := "--cursor " + pos.Next
+	if cmd.Flags().Lookup("cursor") == nil {
+		next = "--page " + pos.Next
+		if p.Limit > 0 {
+			next = fmt.Sprintf("--limit %d %s", p.Limit, next)
+		}
+	}
+	return fmt.Sprintf("More %s available: continue with %s, or use --all.", noun, next)
+}
@@ -29,30 +29,17 @@ var r2ListCmd = &cobra.Command{
 			return err
 		}
 
-		rc := cloudflare.AccountIdentifier(accountID)
-		buckets, err := c.API.ListR2Buckets(c.Context, rc, cloudflare.ListR2BucketsParams{})
-		if err != nil {
-			return fmt.Errorf("failed to list R2 buckets: %w", err)
-		}
-
-		if len(buckets) == 0 {
-			fmt.Println("No R2 buckets found. Use 'r2 create' to create one.")
-			return nil
-		}
-
-		headers := []string{"NAME", "LOCATION", "CREATED_ON"}
-		var rows [][]string
-
-		for _, bucket := range buckets {
-			rows = append(rows, []string{
+		_, err = streamList(cmd, "buckets", c.R2BucketPages(accountID), utils.View{
+			Headers: []string{"NAME", "LOCATION", "CREATED_ON"},
+			Empty:   "No R2 buckets found. Use 'r2 create' to create one.",
+		}, func(bucket cloudflare.R2Bucket) []string {
+			return []string{
 				bucket.Name,
 				bucket.Location,
 				bucket.CreationDate.Format("2006-01-02 15:04:05"),
-			})
-		}
-
-		utils.PrintTable(headers, rows)
-		return nil
+			}
+		}, nil)
+		return err
 	},
 }
 
@@ -141,17 +128,29 @@ var r2InfoCmd = &cobra.Command{
 			return fmt.Errorf("failed to get R2 bucket info: %w", err)
 		}
 
-		fmt.Printf("R2 Bucket Information:\n")
-		fmt.Printf("  Name:         %s\n", bucket.Name)
-		fmt.Printf("  Location:     %s\n", bucket.Location)
-		fmt.Printf("  Created On:   %s\n", bucket.CreationDate.Format("2006-01-02 15:04:05"))
-		return nil
+		return utils.Render(utils.View{
+			Data:    bucket,
+			Headers: []string{"NAME", "LOCATION", "CREATED_ON"},
+			Rows: [][]string{{
+				bucket.Name,
+				bucket.Location,
+				bucket.CreationDate.Format("2006-01-02 15:04:05"),
+			}},
+			Text: func() {
+				fmt.Printf("R2 Bucket Information:\n")
+				fmt.Printf("  Name:         %s\n", bucket.Name)
+				fmt.Printf("  Location:     %s\n", bucket.Location)
+				fmt.Printf("  Created On:   %s\n", bucket.CreationDate.Format("2006-01-02 15:04:05"))
+			},
+		})
 	},
 }
 
 func init() {
 	r2CreateCmd.Flags().String("location", "auto", "Location hint (auto, wnam, enam, weur, eeur, apac)")
 
+	addPaginationFlags(r2ListCmd, true)
+
 	R2Cmd.AddCommand(r2ListCmd)
 	R2Cmd.AddCommand(r2CreateCmd)
 	R2Cmd.AddCommand(r2DeleteCmd)
@@ -0,0 +1,21 @@
+package commands
+
+import "testing"
+
+func TestR2Commands(t *testing.T) {
+	e := newTestEnv(t)
+
+	out := e.mustRun("r2", "create", "assets", "--location", "weur")
+	assertContains(t, out, "R2 bucket created successfully", "WEUR")
+
+	assertContains(t, e.mustRun("r2", "list"), "assets")
+	assertContains(t, e.mustRun("r2", "info", "assets"), "R2 Bucket Information:", "WEUR")
+
+	e.mustFail("r2", "create", "assets")
+
+	e.mustRun("r2", "delete", "assets")
+	if n := len(e.api.R2Buckets(e.account.ID)); n != 0 {
+		t.Errorf("got %d buckets after delete, want 0", n)
+	}
+	e.mustFail("r2", "info", "assets")
+}
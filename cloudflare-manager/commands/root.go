@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/utils"
+	"github.com/spf13/cobra"
+)
+
+// cancelTimeout releases the --timeout context once the command returns.
+var cancelTimeout context.CancelFunc = func() {}
+
+var RootCmd = &cobra.Command{
+	Use:   "cfm",
+	Short: "Cloudflare Multi-Account Manager",
+	Long: `Cloudflare Multi-Account Manager - A powerful CLI tool to manage multiple
+Cloudflare accounts, zones, DNS records, Workers, and Pages projects.
+
+Features:
+  • Multi-account management with easy switching
+  • Zone/domain management
+  • DNS record operations (create, list, update, delete, import, export)
+  • Worker deployment and routing
+  • Pages project management
+  • Cache purging
+  • And more...`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		account, _ := cmd.Flags().GetString("account")
+		config.SelectAccount(account)
+		if path, _ := cmd.Flags().GetString("config"); path != "" {
+			config.SetConfigPath(path)
+		}
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		maxRetries, _ := cmd.Flags().GetInt("max-retries")
+		rateLimit, _ := cmd.Flags().GetFloat64("rate-limit")
+
+		ctx := cmd.Context()
+		if timeout > 0 {
+			ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		}
+		opts := client.DefaultOptions()
+		opts.MaxRetries = maxRetries
+		opts.RateLimit = rateLimit
+		opts.Context = ctx
+		client.Configure(opts)
+
+		output, _ := cmd.Flags().GetString("output")
+		tmpl, _ := cmd.Flags().GetString("template")
+		return utils.SetOutput(output, tmpl)
+	},
+}
+
+// Execute runs the root command with ctx; cancelling ctx aborts in-flight API
+// requests.
+func Execute(ctx context.Context) error {
+	defer func() { cancelTimeout() }()
+	return RootCmd.ExecuteContext(ctx)
+}
+
+func init() {
+	RootCmd.PersistentFlags().String("account", "", "Account to use for this command (overrides the current account and $CFM_ACCOUNT)")
+	RootCmd.PersistentFlags().String("config", "", "Config file path (default ~/.cloudflare-manager.yaml or $CFM_CONFIG)")
+	RootCmd.PersistentFlags().Duration("timeout", 0, "Abort the command after this long, e.g. 30s or 5m (0 = no limit)")
+	RootCmd.PersistentFlags().Int("max-retries", client.DefaultOptions().MaxRetries, "Retries for rate-limited or failed API requests")
+	RootCmd.PersistentFlags().Float64("rate-limit", client.DefaultOptions().RateLimit, "Maximum API requests per second (0 = unlimited)")
+	RootCmd.PersistentFlags().StringP("output", "o", "table", "Output format for list/info commands: table, json, yaml or csv")
+	RootCmd.PersistentFlags().String("template", "", "Go text/template applied to each result, e.g. '{{.ID}} {{.Name}}'")
+
+	RootCmd.AddCommand(AccountCmd)
+	RootCmd.AddCommand(ZoneCmd)
+	RootCmd.AddCommand(DNSCmd)
+	RootCmd.AddCommand(WorkerCmd)
+	RootCmd.AddCommand(PagesCmd)
+	RootCmd.AddCommand(KVCmd)
+	RootCmd.AddCommand(R2Cmd)
+	RootCmd.AddCommand(DDNSCmd)
+}
@@ -111,16 +111,14 @@ var workerRouteListCmd = &cobra.Command{
             return fmt.Errorf("failed to list worker routes: %w", err)
         }
 
-        if len(routes.Routes) == 0 {
-            fmt.Println("No worker routes found.")
-            return nil
-        }
-
         headers := []string{"PATTERN", "WORKER", "ID"}
         var rows [][]string
 
         for _, route := range routes.Routes {
-            worker := "-"
+            worker := route.ScriptName
+            if worker == "" {
+                worker = "-"
+            }
             rows = append(rows, []string{
                 route.Pattern,
                 worker,
@@ -128,8 +126,12 @@ var workerRouteListCmd = &cobra.Command{
             })
         }
 
-        utils.PrintTable(headers, rows)
-        return nil
+        return utils.Render(utils.View{
+            Data:    routes.Routes,
+            Headers: headers,
+            Rows:    rows,
+            Empty:   "No worker routes found.",
+        })
     },
 }
 
@@ -0,0 +1,36 @@
+package commands
+
+import "testing"
+
+func TestWorkerCommands(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	script := writeFile(t, "worker.js", `addEventListener("fetch", e => e.respondWith(new Response("hi")))`)
+	out := e.mustRun("worker", "deploy", "hello", script)
+	assertContains(t, out, "Worker 'hello' deployed successfully")
+	if got, ok := e.api.Worker(e.account.ID, "hello"); !ok || got == "" {
+		t.Fatalf("worker was not uploaded")
+	}
+
+	out = e.mustRun("worker", "route", "create", "example.com", "example.com/api/*", "hello")
+	assertContains(t, out, "Worker route created successfully", "example.com/api/*")
+
+	out = e.mustRun("worker", "route", "list", "example.com")
+	assertContains(t, out, "example.com/api/*", "hello")
+
+	routes := e.api.WorkerRoutes(zone.ID)
+	if len(routes) != 1 {
+		t.Fatalf("got %d routes, want 1", len(routes))
+	}
+	e.mustRun("worker", "route", "delete", "example.com", routes[0].ID)
+	if n := len(e.api.WorkerRoutes(zone.ID)); n != 0 {
+		t.Errorf("got %d routes after delete, want 0", n)
+	}
+
+	e.mustRun("worker", "delete", "hello")
+	if _, ok := e.api.Worker(e.account.ID, "hello"); ok {
+		t.Errorf("worker still exists after delete")
+	}
+	e.mustFail("worker", "delete", "hello")
+}
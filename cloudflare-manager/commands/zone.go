@@ -2,6 +2,7 @@ package commands
 
 import (
     "fmt"
+    "strings"
     "time"
 
     "github.com/cloudflare-manager/client"
@@ -19,43 +20,40 @@ var ZoneCmd = &cobra.Command{
 var zoneListCmd = &cobra.Command{
     Use:   "list",
     Short: "List all zones",
+    Long: `List every zone of the account. --limit stops after that many zones,
+reading as many pages as needed, and --page alone reads a single page.
+Rows are printed as pages arrive.`,
     RunE: func(cmd *cobra.Command, args []string) error {
         c, err := client.NewFromConfig()
         if err != nil {
             return err
         }
 
-        zones, err := c.API.ListZones(c.Context)
-        if err != nil {
-            return fmt.Errorf("failed to list zones: %w", err)
-        }
-
-        if len(zones) == 0 {
-            fmt.Println("No zones found. Use 'zone create' to add a zone.")
-            return nil
-        }
-
-        headers := []string{"NAME", "ID", "STATUS", "NAME_SERVERS"}
-        var rows [][]string
-
-        for _, zone := range zones {
-            nameServers := ""
-            if len(zone.NameServers) > 0 {
-                nameServers = zone.NameServers[0]
-                if len(zone.NameServers) > 1 {
-                    nameServers += " ..."
-                }
-            }
-
-            rows = append(rows, []string{
+        // Only names and IDs are kept, for the zone cache.
+        var listed []cloudflare.Zone
+        pos, err := streamList(cmd, "zones", c.ZonePages(), utils.View{
+            Headers:  []string{"NAME", "ID", "STATUS", "NAME_SERVERS"},
+            MaxWidth: map[string]int{"NAME_SERVERS": 30},
+            Empty:    "No zones found. Use 'zone create' to add a zone.",
+        }, func(zone cloudflare.Zone) []string {
+            listed = append(listed, cloudflare.Zone{ID: zone.ID, Name: zone.Name})
+            return []string{
                 zone.Name,
                 zone.ID,
                 zone.Status,
-                nameServers,
-            })
+                strings.Join(zone.NameServers, " "),
+            }
+        }, nil)
+        if err != nil {
+            return err
         }
 
-        utils.PrintTable(headers, rows)
+        // A complete listing replaces the zone cache, a partial one adds to it.
+        if page, _ := cmd.Flags().GetInt("page"); page == 1 && !pos.More {
+            c.CacheZones(listed)
+        } else {
+            c.RememberZones(listed)
+        }
         return nil
     },
 }
@@ -73,29 +71,42 @@ var zoneCreateCmd = &cobra.Command{
             return err
         }
 
-        accountID, err := c.GetAccountID()
+        zone, err := createZone(c, domain, jumpStart)
         if err != nil {
             return err
         }
 
-        zone, err := c.API.CreateZone(c.Context, domain, jumpStart, cloudflare.Account{ID: accountID}, "full")
-        if err != nil {
-            return fmt.Errorf("failed to create zone: %w", err)
-        }
-
         fmt.Printf("✓ Zone '%s' created successfully\n", domain)
-        fmt.Printf("  Zone ID: %s\n", zone.ID)
-        fmt.Printf("  Status:  %s\n", zone.Status)
-        fmt.Printf("\nNameservers:\n")
-        for _, ns := range zone.NameServers {
-            fmt.Printf("  - %s\n", ns)
-        }
-        fmt.Printf("\nUpdate your domain's nameservers to the ones listed above.\n")
-
+        printNewZone(zone)
         return nil
     },
 }
 
+// createZone creates a zone in the client's account.
+func createZone(c *client.Client, domain string, jumpStart bool) (cloudflare.Zone, error) {
+    accountID, err := c.GetAccountID()
+    if err != nil {
+        return cloudflare.Zone{}, err
+    }
+
+    zone, err := c.API.CreateZone(c.Context, domain, jumpStart, cloudflare.Account{ID: accountID}, "full")
+    if err != nil {
+        return cloudflare.Zone{}, fmt.Errorf("failed to create zone: %w", err)
+    }
+    return zone, nil
+}
+
+// printNewZone prints what is needed to activate a new zone.
+func printNewZone(zone cloudflare.Zone) {
+    fmt.Printf("  Zone ID: %s\n", zone.ID)
+    fmt.Printf("  Status:  %s\n", zone.Status)
+    fmt.Printf("\nNameservers:\n")
+    for _, ns := range zone.NameServers {
+        fmt.Printf("  - %s\n", ns)
+    }
+    fmt.Printf("\nUpdate your domain's nameservers to the ones listed above.\n")
+}
+
 var zoneDeleteCmd = &cobra.Command{
     Use:   "delete [zone-id or domain]",
     Short: "Delete a zone",
@@ -116,6 +127,7 @@ var zoneDeleteCmd = &cobra.Command{
         if _, err := c.API.DeleteZone(c.Context, zoneID); err != nil {
             return fmt.Errorf("failed to delete zone: %w", err)
         }
+        c.ForgetZone(zoneID)
 
         fmt.Printf("✓ Zone deleted successfully\n")
         return nil
@@ -144,84 +156,73 @@ var zoneInfoCmd = &cobra.Command{
             return fmt.Errorf("failed to get zone info: %w", err)
         }
 
-        fmt.Printf("Zone Information:\n")
-        fmt.Printf("  Name:              %s\n", zone.Name)
-        fmt.Printf("  ID:                %s\n", zone.ID)
-        fmt.Printf("  Status:            %s\n", zone.Status)
-        fmt.Printf("  Plan:              %s\n", zone.Plan.Name)
-        fmt.Printf("  Development Mode:  %s\n", utils.BoolToString(zone.DevMode != 0))
-        fmt.Printf("  Created On:        %s\n", zone.CreatedOn.Format(time.RFC3339))
-        fmt.Printf("  Modified On:       %s\n", zone.ModifiedOn.Format(time.RFC3339))
-        fmt.Printf("\nNameservers:\n")
-        for _, ns := range zone.NameServers {
-            fmt.Printf("  - %s\n", ns)
-        }
-
-        return nil
+        return utils.Render(utils.View{
+            Data:    zone,
+            Headers: []string{"NAME", "ID", "STATUS", "PLAN", "DEV_MODE", "CREATED_ON", "MODIFIED_ON", "NAME_SERVERS"},
+            Rows: [][]string{{
+                zone.Name,
+                zone.ID,
+                zone.Status,
+                zone.Plan.Name,
+                fmt.Sprintf("%t", zone.DevMode != 0),
+                zone.CreatedOn.Format(time.RFC3339),
+                zone.ModifiedOn.Format(time.RFC3339),
+                strings.Join(zone.NameServers, " "),
+            }},
+            Text: func() {
+                fmt.Printf("Zone Information:\n")
+                fmt.Printf("  Name:              %s\n", zone.Name)
+                fmt.Printf("  ID:                %s\n", zone.ID)
+                fmt.Printf("  Status:            %s\n", zone.Status)
+                fmt.Printf("  Plan:              %s\n", zone.Plan.Name)
+                fmt.Printf("  Development Mode:  %s\n", utils.BoolToString(zone.DevMode != 0))
+                fmt.Printf("  Created On:        %s\n", zone.CreatedOn.Format(time.RFC3339))
+                fmt.Printf("  Modified On:       %s\n", zone.ModifiedOn.Format(time.RFC3339))
+                fmt.Printf("\nNameservers:\n")
+                for _, ns := range zone.NameServers {
+                    fmt.Printf("  - %s\n", ns)
+                }
+            },
+        })
     },
 }
 
-var zonePurgeCmd = &cobra.Command{
-    Use:   "purge [zone-id or domain]",
-    Short: "Purge cache for a zone",
-    Args:  cobra.ExactArgs(1),
+var zoneRefreshCacheCmd = &cobra.Command{
+    Use:   "refresh-cache",
+    Short: "Refresh the cached zone name to ID mapping",
+    Long: `Zone names are resolved to IDs through a per-account cache that expires
+after 24 hours. Refresh it after zones are added or removed outside cfm.`,
+    Args: cobra.NoArgs,
     RunE: func(cmd *cobra.Command, args []string) error {
-        zoneIdentifier := args[0]
-        everything, _ := cmd.Flags().GetBool("everything")
-        files, _ := cmd.Flags().GetStringSlice("files")
-
         c, err := client.NewFromConfig()
         if err != nil {
             return err
         }
 
-        zoneID, err := getZoneID(c, zoneIdentifier)
+        zones, err := c.RefreshZoneCache()
         if err != nil {
             return err
         }
 
-        var purgeReq cloudflare.PurgeCacheRequest
-        if everything {
-            purgeReq.Everything = true
-        } else if len(files) > 0 {
-            purgeReq.Files = files
-        } else {
-            purgeReq.Everything = true
-        }
-
-        if _, err := c.API.PurgeCache(c.Context, zoneID, purgeReq); err != nil {
-            return fmt.Errorf("failed to purge cache: %w", err)
-        }
-
-        fmt.Printf("✓ Cache purged successfully\n")
+        fmt.Printf("✓ Cached %d zones\n", len(zones))
         return nil
     },
 }
 
+// getZoneID accepts a zone ID, a zone name or a hostname inside a zone.
 func getZoneID(c *client.Client, identifier string) (string, error) {
-    zones, err := c.API.ListZones(c.Context)
-    if err != nil {
-        return "", fmt.Errorf("failed to list zones: %w", err)
-    }
-
-    for _, zone := range zones {
-        if zone.ID == identifier || zone.Name == identifier {
-            return zone.ID, nil
-        }
-    }
-
-    return "", fmt.Errorf("zone not found: %s", identifier)
+    return c.ZoneID(identifier)
 }
 
 func init() {
     zoneCreateCmd.Flags().BoolP("jump-start", "j", true, "Automatically scan for DNS records")
 
-    zonePurgeCmd.Flags().Bool("everything", false, "Purge everything")
-    zonePurgeCmd.Flags().StringSlice("files", []string{}, "Specific files to purge")
+    addPaginationFlags(zoneListCmd, false)
+    readAllByDefault(zoneListCmd)
 
     ZoneCmd.AddCommand(zoneListCmd)
     ZoneCmd.AddCommand(zoneCreateCmd)
     ZoneCmd.AddCommand(zoneDeleteCmd)
     ZoneCmd.AddCommand(zoneInfoCmd)
-    ZoneCmd.AddCommand(zonePurgeCmd)
+    ZoneCmd.AddCommand(zoneRefreshCacheCmd)
 }
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare-manager/audit"
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/config"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+var zoneAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check every zone of every account against a baseline",
+	Long: `Compare every zone of every configured account with a baseline policy and
+report the zones that drift from it. With --account only that account's
+zones are audited. The baseline is a YAML file:
+
+  ssl: [full, strict]        # accepted SSL modes
+  dnssec: true               # DNSSEC must be active
+  settings:                  # zone settings, as in a settings profile
+    always_use_https: on
+    min_tls_version: "1.2"
+  records:
+    spf: true                # one v=spf1 TXT record at the apex
+    dmarc: true              # a v=DMARC1 TXT record at _dmarc
+    caa: true                # CAA records at the apex
+    caa_issuers: [letsencrypt.org]
+
+--junit writes the result as JUnit XML for CI ("-" for standard output).
+The command exits with an error when any zone drifts or cannot be read.`,
+	Example: `  cfm zone audit --baseline baseline.yaml
+  cfm zone audit --baseline baseline.yaml --account prod -o json
+  cfm zone audit --baseline baseline.yaml --junit audit.xml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("baseline")
+		junit, _ := cmd.Flags().GetString("junit")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		if parallel < 1 {
+			parallel = 1
+		}
+
+		baseline, err := audit.Load(file)
+		if err != nil {
+			return err
+		}
+		// With --account only that account is audited.
+		accounts, err := searchAccounts(!cmd.Flags().Changed("account"))
+		if err != nil {
+			return err
+		}
+
+		reports := auditZones(baseline, accounts, parallel)
+
+		if junit != "" {
+			out := os.Stdout
+			if junit != "-" {
+				f, err := os.Create(junit)
+				if err != nil {
+					return fmt.Errorf("failed to create JUnit report: %w", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if err := audit.WriteJUnit(out, reports); err != nil {
+				return fmt.Errorf("failed to write JUnit report: %w", err)
+			}
+		}
+
+		drifted := 0
+		var rows [][]string
+		for _, r := range reports {
+			result, detail := "✓", ""
+			if !r.OK() {
+				drifted++
+				result = "✗"
+				var failed []string
+				for _, res := range r.Failed() {
+					failed = append(failed, res.Check)
+				}
+				detail = strings.Join(failed, ", ")
+				if r.Error != "" {
+					detail = r.Error
+				}
+			}
+			rows = append(rows, []string{r.Account, r.Zone, result, fmt.Sprintf("%d/%d", len(r.Results)-len(r.Failed()), len(r.Results)), detail})
+		}
+
+		if junit != "-" {
+			if err := utils.Render(utils.View{
+				Data:     reports,
+				Headers:  []string{"ACCOUNT", "ZONE", "OK", "PASSED", "FAILED"},
+				Rows:     rows,
+				MaxWidth: map[string]int{"FAILED": 60},
+				Empty:    "No zones found.",
+				Text:     func() { printAuditReports(reports, drifted) },
+			}); err != nil {
+				return err
+			}
+		}
+
+		if drifted > 0 {
+			return fmt.Errorf("%d of %d zones drift from the baseline", drifted, len(reports))
+		}
+		return nil
+	},
+}
+
+// auditZones lists the zones of each account and audits them with at most
+// parallel zones in flight. A zone reachable from several accounts is
+// audited once. Accounts whose zones cannot be listed get a report with
+// the error and no zone name.
+func auditZones(baseline *audit.Baseline, accounts []config.Account, parallel int) []audit.Report {
+	type job struct {
+		client *client.Client
+		report *audit.Report
+	}
+
+	var reports []*audit.Report
+	var jobs []job
+	seen := map[string]bool{}
+	for i := range accounts {
+		account := &accounts[i]
+		c, err := client.New(account)
+		if err != nil {
+			reports = append(reports, &audit.Report{Account: account.Name, Error: err.Error()})
+			continue
+		}
+		res, err := c.API.ListZonesContext(c.Context, cloudflare.WithZoneFilters("", account.AccountID, ""))
+		if err != nil {
+			reports = append(reports, &audit.Report{Account: account.Name, Error: fmt.Sprintf("failed to list zones: %v", err)})
+			continue
+		}
+		for _, zone := range res.Result {
+			if seen[zone.ID] {
+				continue
+			}
+			seen[zone.ID] = true
+			r := &audit.Report{Account: account.Name, Zone: zone.Name, ZoneID: zone.ID}
+			reports = append(reports, r)
+			jobs = append(jobs, job{client: c, report: r})
+		}
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, parallel)
+	)
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			zone, err := auditZone(j.client, baseline, j.report.Zone, j.report.ZoneID)
+			if err != nil {
+				j.report.Error = err.Error()
+				return
+			}
+			j.report.Results = baseline.Check(zone)
+		}(j)
+	}
+	wg.Wait()
+
+	out := make([]audit.Report, 0, len(reports))
+	for _, r := range reports {
+		out = append(out, *r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Account != out[j].Account {
+			return out[i].Account < out[j].Account
+		}
+		return out[i].Zone < out[j].Zone
+	})
+	return out
+}
+
+// auditZone reads what the baseline needs to know about a zone.
+func auditZone(c *client.Client, baseline *audit.Baseline, name, zoneID string) (audit.Zone, error) {
+	zone := audit.Zone{Name: name}
+	var err error
+	if baseline.NeedsSettings() {
+		if zone.Settings, err = zoneSettings(c, zoneID); err != nil {
+			return zone, err
+		}
+	}
+	if baseline.DNSSEC != nil {
+		d, err := c.DNSSEC(zoneID)
+		if err != nil {
+			return zone, fmt.Errorf("failed to get DNSSEC status: %w", err)
+		}
+		zone.DNSSEC = d.Status
+	}
+	if baseline.NeedsRecords() {
+		zone.Records, _, err = c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+		if err != nil {
+			return zone, fmt.Errorf("failed to list DNS records: %w", err)
+		}
+	}
+	return zone, nil
+}
+
+func printAuditReports(reports []audit.Report, drifted int) {
+	for _, r := range reports {
+		name := r.Account + "/" + r.Zone
+		if r.Zone == "" {
+			name = r.Account
+		}
+		switch {
+		case r.Error != "":
+			fmt.Printf("✗ %s: %s\n", name, r.Error)
+		case r.OK():
+			fmt.Printf("✓ %s\n", name)
+		default:
+			fmt.Printf("✗ %s\n", name)
+			for _, res := range r.Failed() {
+				fmt.Printf("    %-24s expected %s, got %s\n", res.Check, res.Expected, res.Actual)
+			}
+		}
+	}
+	fmt.Printf("\n%d of %d zones comply with the baseline\n", len(reports)-drifted, len(reports))
+}
+
+func init() {
+	zoneAuditCmd.Flags().StringP("baseline", "b", "", "Baseline policy (YAML)")
+	zoneAuditCmd.Flags().String("junit", "", "Write a JUnit XML report to this file (\"-\" for standard output)")
+	zoneAuditCmd.Flags().Int("parallel", 4, "Number of zones audited at the same time")
+	zoneAuditCmd.MarkFlagRequired("baseline")
+
+	ZoneCmd.AddCommand(zoneAuditCmd)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestZoneAudit(t *testing.T) {
+	e := newTestEnv(t)
+	other := e.addAccount("prod")
+	good := e.api.AddZone(other.ID, "example.com")
+	bad := e.api.AddZone(e.account.ID, "example.org")
+
+	for _, z := range []cloudflare.Zone{good, bad} {
+		e.api.AddDNSRecord(z.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: `"v=spf1 -all"`})
+	}
+	e.api.AddDNSRecord(good.ID, cloudflare.DNSRecord{Type: "TXT", Name: "_dmarc", Content: "v=DMARC1; p=reject"})
+	e.api.AddDNSRecord(good.ID, cloudflare.DNSRecord{Type: "CAA", Name: "@", Content: `0 issue "letsencrypt.org"`})
+	e.api.AddDNSRecord(bad.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: "v=spf1 include:_spf.example.net -all"})
+	e.api.AddDNSRecord(bad.ID, cloudflare.DNSRecord{Type: "CAA", Name: "@", Content: `0 issue "pki.goog"`})
+	e.mustRun("zone", "settings", "set", "example.com", "ssl=strict", "min_tls_version=1.2", "--account", "prod")
+	e.mustRun("zone", "dnssec", "enable", "example.com", "--account", "prod")
+	e.api.ActivateDNSSECAfter(good.ID, 0)
+	e.mustRun("zone", "settings", "set", "example.org", "ssl=full")
+
+	baseline := writeFile(t, "baseline.yaml", `ssl: [full, strict]
+dnssec: true
+settings:
+  min_tls_version: 1.2
+records:
+  spf: true
+  dmarc: true
+  caa_issuers: [letsencrypt.org]
+`)
+
+	out, err := e.run("zone", "audit", "--baseline", baseline)
+	if err == nil || err.Error() != "1 of 2 zones drift from the baseline" {
+		t.Fatalf("audit error = %v, want drift", err)
+	}
+	assertContains(t, out,
+		"✓ prod/example.com",
+		"✗ test/example.org",
+		"dnssec                   expected active, got disabled",
+		"setting min_tls_version  expected 1.2, got 1.0",
+		"spf                      expected one v=spf1 TXT record at example.org, got 2 records",
+		"dmarc                    expected one v=DMARC1 TXT record at _dmarc.example.org, got missing",
+		"caa                      expected CAA issue records for letsencrypt.org, got no issue record for letsencrypt.org",
+		"1 of 2 zones comply with the baseline",
+	)
+
+	junit := filepath.Join(t.TempDir(), "audit.xml")
+	out, _ = e.run("zone", "audit", "--baseline", baseline, "--junit", junit, "-o", "json")
+	assertContains(t, out, `"zone": "example.org"`, `"check": "ssl"`, `"actual": "full"`)
+	data, err := os.ReadFile(junit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(data),
+		`<testsuites name="cfm zone audit" tests="12" failures="5" errors="0">`,
+		`<testsuite name="prod/example.com" tests="6" failures="0" errors="0">`,
+		`<testcase name="dnssec" classname="test/example.org">`,
+		`<failure message="expected active, got disabled"></failure>`,
+	)
+
+	out = e.mustRun("zone", "audit", "--baseline", baseline, "--account", "prod", "-o", "csv")
+	assertContains(t, out, "ACCOUNT,ZONE,OK,PASSED,FAILED", "prod,example.com,✓,6/6,")
+
+	empty := writeFile(t, "empty.yaml", "records: {}\n")
+	assertContains(t, e.mustFail("zone", "audit", "--baseline", empty), "baseline has no checks")
+	both := writeFile(t, "both.yaml", "ssl: strict\nsettings:\n  ssl: full\n")
+	assertContains(t, e.mustFail("zone", "audit", "--baseline", both), "either as ssl or under settings")
+}
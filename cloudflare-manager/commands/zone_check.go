@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/dnscheck"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+// zoneCheckReport is the result of zone check.
+type zoneCheckReport struct {
+	Zone               string                  `json:"zone"`
+	Status             string                  `json:"status"`
+	Delegation         dnscheck.Delegation     `json:"delegation"`
+	DelegationError    string                  `json:"delegation_error,omitempty"`
+	Servers            []dnscheck.ServerResult `json:"servers"`
+	Skipped            int                     `json:"skipped"`
+	ActivationCheck    bool                    `json:"activation_check"`
+	ActivationCheckErr string                  `json:"activation_check_error,omitempty"`
+}
+
+func (r zoneCheckReport) ok() bool {
+	if r.DelegationError != "" || !r.Delegation.OK() {
+		return false
+	}
+	for _, s := range r.Servers {
+		if !s.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+var zoneCheckCmd = &cobra.Command{
+	Use:   "check [zone-id or domain]",
+	Short: "Check name server delegation and what the name servers serve",
+	Long: `Query the parent zone's servers (e.g. the .com servers) for the zone's NS
+delegation and compare it with the name servers Cloudflare assigned. Then
+ask each assigned name server directly for the zone's records and compare
+the answers with the records in Cloudflare. Proxied records, and types other
+than A, AAAA, CNAME, MX, NS and TXT, are not compared.
+
+When the zone is still pending and the delegation is correct, an activation
+check is requested so Cloudflare activates the zone without waiting.
+
+Recursive lookups use the first resolver in /etc/resolv.conf unless
+--resolver is given.`,
+	Example: `  cfm zone check example.com
+  cfm zone check example.com --resolver 1.1.1.1:53 -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		resolver, _ := cmd.Flags().GetString("resolver")
+		port, _ := cmd.Flags().GetString("dns-port")
+		timeout, _ := cmd.Flags().GetDuration("dns-timeout")
+		noActivation, _ := cmd.Flags().GetBool("no-activation-check")
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		zoneID, err := getZoneID(c, args[0])
+		if err != nil {
+			return err
+		}
+
+		zone, err := c.API.ZoneDetails(c.Context, zoneID)
+		if err != nil {
+			return fmt.Errorf("failed to get zone info: %w", err)
+		}
+
+		records, _, err := c.API.ListDNSRecords(c.Context, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{})
+		if err != nil {
+			return fmt.Errorf("failed to list DNS records: %w", err)
+		}
+
+		checker := dnscheck.New(resolver)
+		checker.Port = port
+		checker.Timeout = timeout
+
+		report := zoneCheckReport{Zone: zone.Name, Status: zone.Status}
+		report.Delegation, err = checker.Delegation(c.Context, zone.Name, zone.NameServers)
+		if err != nil {
+			report.DelegationError = err.Error()
+		}
+
+		expected, skipped := dnscheck.Expected(records)
+		report.Skipped = skipped
+		for _, ns := range zone.NameServers {
+			report.Servers = append(report.Servers, checker.CheckServer(c.Context, ns, expected))
+		}
+
+		if zone.Status == "pending" && report.DelegationError == "" && report.Delegation.OK() && !noActivation {
+			if _, err := c.API.ZoneActivationCheck(c.Context, zoneID); err != nil {
+				report.ActivationCheckErr = err.Error()
+			} else {
+				report.ActivationCheck = true
+			}
+		}
+
+		var rows [][]string
+		for _, s := range report.Servers {
+			rows = append(rows, []string{s.Server, s.Address, fmt.Sprintf("%d", s.Checked), fmt.Sprintf("%d", len(s.Mismatches)), s.Error})
+		}
+		if err := utils.Render(utils.View{
+			Data:    report,
+			Headers: []string{"SERVER", "ADDRESS", "CHECKED", "MISMATCHES", "ERROR"},
+			Rows:    rows,
+			Text:    func() { printZoneCheck(report) },
+		}); err != nil {
+			return err
+		}
+
+		if !report.ok() {
+			return fmt.Errorf("zone check found problems for %s", zone.Name)
+		}
+		return nil
+	},
+}
+
+func printZoneCheck(r zoneCheckReport) {
+	fmt.Printf("Zone %s (status: %s)\n\n", r.Zone, r.Status)
+
+	d := r.Delegation
+	if r.DelegationError != "" {
+		fmt.Printf("Delegation:\n  ✗ %s\n", r.DelegationError)
+	} else {
+		fmt.Printf("Delegation (from the %s servers, %s):\n", d.Parent, d.Server)
+		for _, ns := range d.NameServers {
+			mark := "✓"
+			if contains(d.Extra, ns) {
+				mark = "✗"
+			}
+			fmt.Printf("  %s %s\n", mark, ns)
+		}
+		for _, ns := range d.Missing {
+			fmt.Printf("  ✗ %s is assigned by Cloudflare but not delegated\n", ns)
+		}
+		if !d.OK() {
+			fmt.Printf("  Set the name servers at the registrar to: %s\n", strings.Join(d.Expected, ", "))
+		}
+	}
+
+	fmt.Printf("\nName servers:\n")
+	for _, s := range r.Servers {
+		switch {
+		case s.Error != "":
+			fmt.Printf("  ✗ %s: %s\n", s.Server, s.Error)
+		case len(s.Mismatches) == 0:
+			fmt.Printf("  ✓ %s (%s): %d record sets match\n", s.Server, s.Address, s.Checked)
+		default:
+			fmt.Printf("  ✗ %s (%s): %d of %d record sets differ\n", s.Server, s.Address, len(s.Mismatches), s.Checked)
+			for _, m := range s.Mismatches {
+				served := strings.Join(m.Served, ", ")
+				if served == "" {
+					served = "nothing"
+				}
+				fmt.Printf("      %s %s: expected %s, served %s\n", m.Type, m.Name, strings.Join(m.Expected, ", "), served)
+			}
+		}
+	}
+	if r.Skipped > 0 {
+		fmt.Printf("  (%d proxied or uncompared records not checked)\n", r.Skipped)
+	}
+
+	switch {
+	case r.ActivationCheck:
+		fmt.Printf("\n✓ Activation check requested\n")
+	case r.ActivationCheckErr != "":
+		fmt.Printf("\n✗ Activation check failed: %s\n", r.ActivationCheckErr)
+	}
+}
+
+func init() {
+	zoneCheckCmd.Flags().String("resolver", "", "Recursive resolver as host:port (default: first server in /etc/resolv.conf)")
+	zoneCheckCmd.Flags().String("dns-port", "53", "Port the parent and authoritative name servers are queried on")
+	zoneCheckCmd.Flags().Duration("dns-timeout", dnscheck.DefaultTimeout, "Timeout for each DNS query")
+	zoneCheckCmd.Flags().Bool("no-activation-check", false, "Do not request an activation check for pending zones")
+
+	ZoneCmd.AddCommand(zoneCheckCmd)
+}
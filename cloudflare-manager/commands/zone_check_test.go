@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare-manager/internal/fakecf"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/miekg/dns"
+)
+
+func TestZoneCheck(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.SetZoneStatus(zone.ID, "pending")
+	proxied := true
+	priority := uint16(10)
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "@", Content: "192.0.2.1"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "www", Content: "192.0.2.10", Proxied: &proxied})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "MX", Name: "@", Content: "mail.example.com", Priority: &priority})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "TXT", Name: "@", Content: `"v=spf1 mx -all"`})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "CNAME", Name: "docs", Content: "example.github.io"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "NS", Name: "lab", Content: "ns1.lab-dns.net"})
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "NS", Name: "lab", Content: "ns2.lab-dns.net"})
+
+	ns := fakecf.NewNameServer(t)
+	ns.Add(t,
+		"com. 172800 IN NS a.gtld-servers.net.",
+		"example.com. 172800 IN NS old1.registrar-dns.net.",
+		"example.com. 172800 IN NS old2.registrar-dns.net.",
+		"example.com. 300 IN A 192.0.2.1",
+		"example.com. 300 IN MX 10 mail.example.com.",
+		`example.com. 300 IN TXT "v=spf1 mx " "-all"`,
+		"docs.example.com. 300 IN CNAME example.github.io.",
+	)
+	// lab.example.com is delegated, so its NS records come as a referral.
+	ns.Delegate(t,
+		"lab.example.com. 86400 IN NS ns1.lab-dns.net.",
+		"lab.example.com. 86400 IN NS ns2.lab-dns.net.",
+	)
+	ns.AddHosts(fakecf.NameServers...)
+	check := []string{"zone", "check", "example.com", "--resolver", ns.Addr(), "--dns-port", ns.Port(), "--dns-timeout", "2s"}
+
+	// Still delegated to the old provider.
+	out, err := e.run(check...)
+	if err == nil {
+		t.Fatalf("zone check passed with a wrong delegation:\n%s", out)
+	}
+	assertContains(t, out,
+		"Delegation (from the com servers, a.gtld-servers.net)",
+		"✗ old1.registrar-dns.net",
+		"✗ ada.ns.cloudflare.com is assigned by Cloudflare but not delegated",
+		"✓ ada.ns.cloudflare.com (127.0.0.1): 5 record sets match",
+		"(1 proxied or uncompared records not checked)",
+	)
+	if e.api.ActivationChecks(zone.ID) != 0 {
+		t.Error("activation check requested before the delegation was fixed")
+	}
+
+	ns.Remove("example.com.", dns.TypeNS)
+	for _, host := range fakecf.NameServers {
+		ns.Add(t, "example.com. 172800 IN NS "+host+".")
+	}
+	ns.Remove("example.com.", dns.TypeA)
+	ns.Add(t, "example.com. 300 IN A 192.0.2.99")
+
+	out, err = e.run(check...)
+	if err == nil {
+		t.Fatalf("zone check passed with a stale A record:\n%s", out)
+	}
+	assertContains(t, out,
+		"✓ ada.ns.cloudflare.com\n",
+		"1 of 5 record sets differ",
+		"A example.com: expected 192.0.2.1, served 192.0.2.99",
+		"✓ Activation check requested",
+	)
+	if e.api.ActivationChecks(zone.ID) != 1 {
+		t.Errorf("activation checks = %d, want 1", e.api.ActivationChecks(zone.ID))
+	}
+
+	ns.Remove("example.com.", dns.TypeA)
+	ns.Add(t, "example.com. 300 IN A 192.0.2.1")
+	e.api.SetZoneStatus(zone.ID, "active")
+	out = e.mustRun(append(check, "-o", "json")...)
+	assertContains(t, out, `"name_servers": [`, `"checked": 5`, `"activation_check": false`)
+	if strings.Contains(out, "mismatches") {
+		t.Errorf("mismatches reported for a matching zone:\n%s", out)
+	}
+}
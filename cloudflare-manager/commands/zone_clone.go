@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare-manager/zonesettings"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+// Components zone clone can copy.
+const (
+	cloneSettings = "settings"
+	cloneDNS      = "dns"
+	cloneRulesets = "rulesets"
+	cloneRoutes   = "routes"
+)
+
+var cloneComponents = []string{cloneSettings, cloneDNS, cloneRulesets, cloneRoutes}
+
+// cloneManifest is everything zone clone copies to the new zone.
+type cloneManifest struct {
+	Template   string           `json:"template"`
+	Zone       string           `json:"zone"`
+	Account    string           `json:"account"`
+	Components []string         `json:"components"`
+	Settings   []cloneSetting   `json:"settings,omitempty"`
+	Records    []dnssync.Record `json:"records,omitempty"`
+	Rulesets   []cloneRuleset   `json:"rulesets,omitempty"`
+	Routes     []cloneRoute     `json:"routes,omitempty"`
+}
+
+type cloneSetting struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type cloneRuleset struct {
+	Phase       string                   `json:"phase"`
+	Description string                   `json:"description,omitempty"`
+	Rules       []cloudflare.RulesetRule `json:"rules"`
+}
+
+type cloneRoute struct {
+	Pattern string `json:"pattern"`
+	Script  string `json:"script,omitempty"`
+}
+
+// cloneSkippedSettings are not copied: development mode switches itself
+// off after three hours.
+var cloneSkippedSettings = map[string]bool{"development_mode": true}
+
+var zoneCloneCmd = &cobra.Command{
+	Use:   "clone [[account:]template-zone] [new-domain]",
+	Short: "Create a zone configured like an existing one",
+	Long: `Create a new zone in the current account and copy to it from a template
+zone:
+  settings   every editable zone setting except development mode
+  dns        DNS records, except NS records at the apex
+  rulesets   the zone's entrypoint rulesets, e.g. custom firewall rules,
+             cache rules, redirects and transform rules
+  routes     Worker routes
+
+The template's domain is replaced with the new domain in record names and
+targets, rule expressions and parameters, and route patterns. Further
+--rename old=new rules rewrite other domains and are applied first. Legacy
+page rules are not copied.
+
+--components selects what is copied. --dry-run prints the manifest of what
+would be created without creating anything; use -o json to save it.`,
+	Example: `  cfm zone clone template.com newbrand.com --dry-run
+  cfm zone clone template.com newbrand.com --components settings,rulesets
+  cfm zone clone prod:template.com newbrand.com --account brands --yes`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		components, _ := cmd.Flags().GetStringSlice("components")
+		renames, _ := cmd.Flags().GetStringArray("rename")
+		jumpStart, _ := cmd.Flags().GetBool("jump-start")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		for _, comp := range components {
+			if !contains(cloneComponents, comp) {
+				return fmt.Errorf("unknown component %q (use %s)", comp, strings.Join(cloneComponents, ", "))
+			}
+		}
+		domain := strings.ToLower(strings.TrimSuffix(args[1], "."))
+
+		template, err := openCopyZone(args[0])
+		if err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		if template.zone.Name == domain {
+			return fmt.Errorf("the new domain is the template's domain")
+		}
+		rules, err := parseRenameRules(renames)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, renameRule{from: template.zone.Name, to: domain})
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		m, err := buildCloneManifest(template, domain, c.Account.Name, components, rules)
+		if err != nil {
+			return err
+		}
+		if !skipValidation(cmd) && contains(components, cloneDNS) {
+			if err := preflight(checkDesiredState(domain, m.Records, nil, false)); err != nil {
+				return err
+			}
+		}
+
+		if err := utils.Render(utils.View{
+			Data: m,
+			Text: func() { printCloneManifest(m) },
+		}); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+
+		fmt.Println()
+		if !yes && !utils.Confirm(fmt.Sprintf("Create %s in %s and copy %s from %s?", domain, m.Account, strings.Join(components, ", "), m.Template)) {
+			fmt.Println("Clone cancelled.")
+			return nil
+		}
+
+		zone, err := createZone(c, domain, jumpStart)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Zone '%s' created\n", domain)
+
+		failed := 0
+		for _, comp := range components {
+			msg, err := applyCloneComponent(c, zone, m, comp)
+			if err != nil {
+				fmt.Printf("✗ %s: %v\n", comp, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s\n", msg)
+		}
+
+		fmt.Println()
+		printNewZone(zone)
+		if failed > 0 {
+			return fmt.Errorf("zone %s was created but %d of %d components failed to copy", domain, failed, len(components))
+		}
+		return nil
+	},
+}
+
+// buildCloneManifest reads the selected components of the template and
+// rewrites them for the new domain.
+func buildCloneManifest(template *copyZone, domain, account string, components []string, rules []renameRule) (*cloneManifest, error) {
+	m := &cloneManifest{Template: template.String(), Zone: domain, Account: account, Components: components}
+	tc := template.client
+	rc := cloudflare.ZoneIdentifier(template.zone.ID)
+
+	if contains(components, cloneSettings) {
+		settings, err := zoneSettings(tc, template.zone.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range settings {
+			if s.Editable && !cloneSkippedSettings[s.ID] {
+				m.Settings = append(m.Settings, cloneSetting{Name: s.ID, Value: s.Value})
+			}
+		}
+	}
+
+	if contains(components, cloneDNS) {
+		for _, rec := range template.records {
+			if rec.Type == "NS" && strings.EqualFold(rec.Name, template.zone.Name) {
+				continue
+			}
+			r := renameRecord(dnssync.FromDNSRecord(rec), rules)
+			if r.Name != domain && !strings.HasSuffix(r.Name, "."+domain) {
+				return nil, fmt.Errorf("%s %s would become %s, which is outside %s; add a --rename rule", rec.Type, rec.Name, r.Name, domain)
+			}
+			m.Records = append(m.Records, r)
+		}
+	}
+
+	if contains(components, cloneRulesets) {
+		rulesets, err := tc.API.ListRulesets(tc.Context, rc, cloudflare.ListRulesetsParams{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rulesets: %w", err)
+		}
+		for _, rs := range rulesets {
+			// Managed rulesets and rulesets of other kinds are deployed
+			// from the entrypoints, so only those are copied.
+			if rs.Kind != "zone" {
+				continue
+			}
+			full, err := tc.API.GetRuleset(tc.Context, rc, rs.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get ruleset %s: %w", rs.Phase, err)
+			}
+			rewritten, err := cloneRules(full.Rules, rules)
+			if err != nil {
+				return nil, fmt.Errorf("ruleset %s: %w", rs.Phase, err)
+			}
+			m.Rulesets = append(m.Rulesets, cloneRuleset{Phase: full.Phase, Description: full.Description, Rules: rewritten})
+		}
+	}
+
+	if contains(components, cloneRoutes) {
+		res, err := tc.API.ListWorkerRoutes(tc.Context, rc, cloudflare.ListWorkerRoutesParams{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list worker routes: %w", err)
+		}
+		for _, route := range res.Routes {
+			m.Routes = append(m.Routes, cloneRoute{Pattern: replaceDomains(route.Pattern, rules), Script: route.ScriptName})
+		}
+	}
+	return m, nil
+}
+
+// cloneRules drops the template's rule IDs and versions and rewrites
+// domains anywhere in the rules.
+func cloneRules(rules []cloudflare.RulesetRule, renames []renameRule) ([]cloudflare.RulesetRule, error) {
+	for i := range rules {
+		rules[i].ID = ""
+		rules[i].Version = nil
+		rules[i].LastUpdated = nil
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	var out []cloudflare.RulesetRule
+	if err := json.Unmarshal([]byte(replaceDomains(string(data), renames)), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// replaceDomains replaces each rule's domain, and names under it, where it
+// appears in text as a whole name.
+func replaceDomains(text string, rules []renameRule) string {
+	for _, r := range rules {
+		re := regexp.MustCompile(`(?i)(^|[^a-z0-9-])` + regexp.QuoteMeta(r.from) + `($|[^a-z0-9-])`)
+		text = re.ReplaceAllString(text, "${1}"+r.to+"${2}")
+	}
+	return text
+}
+
+func applyCloneComponent(c *client.Client, zone cloudflare.Zone, m *cloneManifest, component string) (string, error) {
+	rc := cloudflare.ZoneIdentifier(zone.ID)
+	switch component {
+	case cloneSettings:
+		profile := &zonesettings.Profile{Settings: map[string]interface{}{}}
+		for _, s := range m.Settings {
+			profile.Settings[s.Name] = s.Value
+		}
+		current, err := zoneSettings(c, zone.ID)
+		if err != nil {
+			return "", err
+		}
+		changes := profile.Diff(current)
+		if len(changes) > 0 {
+			if _, err := c.API.UpdateZoneSettings(c.Context, zone.ID, zonesettings.Updates(changes)); err != nil {
+				return "", fmt.Errorf("failed to update settings: %w", err)
+			}
+		}
+		return fmt.Sprintf("Copied settings: %d changed from the defaults", len(changes)), nil
+
+	case cloneDNS:
+		current, _, err := c.API.ListDNSRecords(c.Context, rc, cloudflare.ListDNSRecordsParams{})
+		if err != nil {
+			return "", fmt.Errorf("failed to list DNS records: %w", err)
+		}
+		res := dnssync.Apply(c.Context, c.API, zone.ID, dnssync.Diff(zone.Name, m.Records, current, false))
+		for _, err := range res.Errors {
+			fmt.Printf("  ✗ %v\n", err)
+		}
+		if res.Failed > 0 {
+			return "", fmt.Errorf("%d of %d records failed", res.Failed, res.Created+res.Updated+res.Failed)
+		}
+		return fmt.Sprintf("Copied DNS records: %d created, %d updated", res.Created, res.Updated), nil
+
+	case cloneRulesets:
+		for _, rs := range m.Rulesets {
+			if _, err := c.API.UpdateEntrypointRuleset(c.Context, rc, cloudflare.UpdateEntrypointRulesetParams{Phase: rs.Phase, Description: rs.Description, Rules: rs.Rules}); err != nil {
+				return "", fmt.Errorf("failed to create the %s ruleset: %w", rs.Phase, err)
+			}
+		}
+		return fmt.Sprintf("Copied rulesets: %d", len(m.Rulesets)), nil
+
+	case cloneRoutes:
+		for _, route := range m.Routes {
+			if _, err := c.API.CreateWorkerRoute(c.Context, rc, cloudflare.CreateWorkerRouteParams{Pattern: route.Pattern, Script: route.Script}); err != nil {
+				return "", fmt.Errorf("failed to create route %s: %w", route.Pattern, err)
+			}
+		}
+		return fmt.Sprintf("Copied worker routes: %d", len(m.Routes)), nil
+	}
+	return "", fmt.Errorf("unknown component")
+}
+
+func printCloneManifest(m *cloneManifest) {
+	fmt.Printf("Clone %s → %s:%s\n", m.Template, m.Account, m.Zone)
+
+	if contains(m.Components, cloneSettings) {
+		fmt.Printf("\nSettings (%d):\n", len(m.Settings))
+		for _, s := range m.Settings {
+			fmt.Printf("  %-26s %s\n", s.Name, zonesettings.Format(s.Value))
+		}
+	}
+	if contains(m.Components, cloneDNS) {
+		fmt.Printf("\nDNS records (%d):\n", len(m.Records))
+		plan := dnssync.Diff(m.Zone, m.Records, nil, false)
+		plan.PrintChanges(os.Stdout)
+	}
+	if contains(m.Components, cloneRulesets) {
+		fmt.Printf("\nRulesets (%d):\n", len(m.Rulesets))
+		for _, rs := range m.Rulesets {
+			fmt.Printf("  %s: %d rules\n", rs.Phase, len(rs.Rules))
+			for _, r := range rs.Rules {
+				desc := r.Description
+				if desc == "" {
+					desc = r.Expression
+				}
+				fmt.Printf("    %-10s %s\n", r.Action, desc)
+			}
+		}
+	}
+	if contains(m.Components, cloneRoutes) {
+		fmt.Printf("\nWorker routes (%d):\n", len(m.Routes))
+		for _, r := range m.Routes {
+			fmt.Printf("  %s → %s\n", r.Pattern, r.Script)
+		}
+	}
+}
+
+func init() {
+	zoneCloneCmd.Flags().StringSlice("components", cloneComponents, "What to copy: "+strings.Join(cloneComponents, ", "))
+	zoneCloneCmd.Flags().StringArray("rename", nil, "Rewrite another domain, e.g. cdn.template.com=cdn.newbrand.com (repeatable)")
+	zoneCloneCmd.Flags().BoolP("jump-start", "j", false, "Let Cloudflare scan for DNS records when creating the zone")
+	zoneCloneCmd.Flags().Bool("dry-run", false, "Only print what would be created")
+	zoneCloneCmd.Flags().BoolP("yes", "y", false, "Clone without asking for confirmation")
+	zoneCloneCmd.Flags().Bool("skip-validation", false, "Copy DNS records even if they fail validation")
+
+	ZoneCmd.AddCommand(zoneCloneCmd)
+}
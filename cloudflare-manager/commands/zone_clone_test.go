@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestZoneClone(t *testing.T) {
+	e := newTestEnv(t)
+	tmpl := e.api.AddZone(e.account.ID, "template.com")
+
+	e.mustRun("zone", "settings", "set", "template.com", "ssl=strict", "always_use_https=on", "development_mode=on")
+	e.api.AddDNSRecord(tmpl.ID, cloudflare.DNSRecord{Type: "NS", Name: "template.com", Content: "ada.ns.cloudflare.com"})
+	e.api.AddDNSRecord(tmpl.ID, cloudflare.DNSRecord{Type: "A", Name: "template.com", Content: "192.0.2.1"})
+	e.api.AddDNSRecord(tmpl.ID, cloudflare.DNSRecord{Type: "CNAME", Name: "www.template.com", Content: "template.com"})
+	e.api.AddDNSRecord(tmpl.ID, cloudflare.DNSRecord{Type: "CNAME", Name: "cdn.template.com", Content: "cdn.mytemplate.com"})
+	e.api.AddRuleset(tmpl.ID, "http_request_firewall_custom", []cloudflare.RulesetRule{
+		{Action: "block", Expression: `(http.host eq "admin.template.com" and not ip.src in {192.0.2.0/24})`, Description: "Lock admin"},
+	})
+	e.api.AddRuleset(tmpl.ID, "http_request_dynamic_redirect", []cloudflare.RulesetRule{{
+		Action:     "redirect",
+		Expression: `(http.host eq "template.com")`,
+		ActionParameters: &cloudflare.RulesetRuleActionParameters{FromValue: &cloudflare.RulesetRuleActionParametersFromValue{
+			StatusCode: 301,
+			TargetURL:  cloudflare.RulesetRuleActionParametersTargetURL{Value: "https://www.template.com/"},
+		}},
+	}})
+	e.api.AddWorkerRoute(tmpl.ID, "*.template.com/api/*", "api")
+
+	out := e.mustRun("zone", "clone", "template.com", "newbrand.com", "--dry-run")
+	assertContains(t, out,
+		"Clone test:template.com → test:newbrand.com",
+		"ssl                        strict",
+		"always_use_https           on",
+		"DNS records (3):",
+		"www.newbrand.com",
+		"cdn.mytemplate.com",
+		"http_request_firewall_custom: 1 rules",
+		"block      Lock admin",
+		"*.newbrand.com/api/* → api",
+	)
+	if strings.Contains(out, "development_mode") {
+		t.Errorf("development mode would be copied:\n%s", out)
+	}
+	if _, ok := e.api.ZoneByName("newbrand.com"); ok {
+		t.Fatal("--dry-run created the zone")
+	}
+
+	out = e.mustRun("zone", "clone", "template.com", "newbrand.com", "--dry-run", "--components", "routes", "-o", "json")
+	assertContains(t, out, `"components": [`, `"pattern": "*.newbrand.com/api/*"`)
+	if strings.Contains(out, `"records"`) {
+		t.Errorf("unselected component in manifest:\n%s", out)
+	}
+
+	out = e.mustRun("zone", "clone", "template.com", "newbrand.com", "--yes")
+	assertContains(t, out,
+		"✓ Zone 'newbrand.com' created",
+		"✓ Copied settings: 2 changed from the defaults",
+		"✓ Copied DNS records: 3 created, 0 updated",
+		"✓ Copied rulesets: 2",
+		"✓ Copied worker routes: 1",
+		"Nameservers:",
+	)
+
+	zone, ok := e.api.ZoneByName("newbrand.com")
+	if !ok {
+		t.Fatal("zone not created")
+	}
+	settings := e.api.ZoneSettings(zone.ID)
+	if settings["ssl"] != "strict" || settings["development_mode"] != "off" {
+		t.Errorf("settings not cloned: %v", settings)
+	}
+	records := recordsByName(e.api.DNSRecords(zone.ID))
+	if len(records) != 3 || records["CNAME www.newbrand.com"].Content != "newbrand.com" || records["CNAME cdn.newbrand.com"].Content != "cdn.mytemplate.com" {
+		t.Errorf("records not cloned: %v", records)
+	}
+	rulesets := e.api.Rulesets(zone.ID)
+	if len(rulesets) != 2 {
+		t.Fatalf("got %d rulesets, want 2", len(rulesets))
+	}
+	if got := rulesets[0].Rules[0].Expression; got != `(http.host eq "admin.newbrand.com" and not ip.src in {192.0.2.0/24})` {
+		t.Errorf("expression = %s", got)
+	}
+	if got := rulesets[1].Rules[0].ActionParameters.FromValue.TargetURL.Value; got != "https://www.newbrand.com/" {
+		t.Errorf("redirect target = %s", got)
+	}
+	if routes := e.api.WorkerRoutes(zone.ID); len(routes) != 1 || routes[0].Pattern != "*.newbrand.com/api/*" {
+		t.Errorf("routes = %+v", routes)
+	}
+
+	assertContains(t, e.mustFail("zone", "clone", "template.com", "other.com", "--components", "pagerules"), `unknown component "pagerules"`)
+}
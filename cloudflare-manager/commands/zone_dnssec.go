@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/utils"
+	"github.com/spf13/cobra"
+)
+
+var zoneDNSSECCmd = &cobra.Command{
+	Use:   "dnssec",
+	Short: "Manage DNSSEC for a zone",
+	Long: `Show, enable and disable DNSSEC. Enabling DNSSEC gives a DS record that
+has to be added at the domain's registrar; the zone stays pending until
+Cloudflare sees it there.`,
+}
+
+var zoneDNSSECStatusCmd = &cobra.Command{
+	Use:   "status [zone-id or domain]",
+	Short: "Show DNSSEC status and the DS record for the registrar",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, zoneID, zoneName, err := dnssecZone(args[0])
+		if err != nil {
+			return err
+		}
+
+		d, err := c.DNSSEC(zoneID)
+		if err != nil {
+			return fmt.Errorf("failed to get DNSSEC status: %w", err)
+		}
+		if wait, _ := cmd.Flags().GetBool("wait"); wait && d.Status != "active" {
+			if d, err = waitForDNSSEC(cmd, c, zoneID, d); err != nil {
+				return err
+			}
+		}
+		return renderDNSSEC(zoneName, d)
+	},
+}
+
+var zoneDNSSECEnableCmd = &cobra.Command{
+	Use:   "enable [zone-id or domain]",
+	Short: "Enable DNSSEC",
+	Long: `Enable DNSSEC and print the DS record to add at the registrar. With --wait
+the command keeps polling until the status becomes active, which happens
+once the DS record has been published by the registrar.`,
+	Example: `  cfm zone dnssec enable example.com
+  cfm zone dnssec enable example.com --wait --poll-interval 1m --timeout 2h
+  cfm zone dnssec enable example.com --multi-signer`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, zoneID, zoneName, err := dnssecZone(args[0])
+		if err != nil {
+			return err
+		}
+
+		update := client.DNSSECUpdate{Status: "active"}
+		if cmd.Flags().Changed("multi-signer") {
+			multiSigner, _ := cmd.Flags().GetBool("multi-signer")
+			update.MultiSigner = &multiSigner
+		}
+		d, err := c.UpdateDNSSEC(zoneID, update)
+		if err != nil {
+			return fmt.Errorf("failed to enable DNSSEC: %w", err)
+		}
+
+		if utils.IsTableOutput() {
+			fmt.Printf("✓ DNSSEC enabled for %s\n\n", zoneName)
+		}
+		if wait, _ := cmd.Flags().GetBool("wait"); wait && d.Status != "active" {
+			if err := renderDNSSEC(zoneName, d); err != nil {
+				return err
+			}
+			if d, err = waitForDNSSEC(cmd, c, zoneID, d); err != nil {
+				return err
+			}
+			fmt.Printf("✓ DNSSEC is active for %s\n", zoneName)
+			return nil
+		}
+		return renderDNSSEC(zoneName, d)
+	},
+}
+
+var zoneDNSSECDisableCmd = &cobra.Command{
+	Use:   "disable [zone-id or domain]",
+	Short: "Disable DNSSEC",
+	Long: `Disable DNSSEC. Remove the DS record at the registrar first and wait for
+its TTL to expire: a DS record without signatures makes the domain fail to
+resolve for validating resolvers.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		c, zoneID, zoneName, err := dnssecZone(args[0])
+		if err != nil {
+			return err
+		}
+
+		if !yes {
+			fmt.Println("Make sure the DS record has been removed at the registrar, or the domain will stop resolving for validating resolvers.")
+			if !utils.Confirm(fmt.Sprintf("Disable DNSSEC for %s?", zoneName)) {
+				fmt.Println("Disable cancelled.")
+				return nil
+			}
+		}
+
+		if _, err := c.UpdateDNSSEC(zoneID, client.DNSSECUpdate{Status: "disabled"}); err != nil {
+			return fmt.Errorf("failed to disable DNSSEC: %w", err)
+		}
+
+		fmt.Printf("✓ DNSSEC disabled for %s\n", zoneName)
+		return nil
+	},
+}
+
+// dnssecZone resolves the zone and its name, which the output shows even
+// when the zone is given by ID.
+func dnssecZone(identifier string) (*client.Client, string, string, error) {
+	c, err := client.NewFromConfig()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	zoneID, err := getZoneID(c, identifier)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	zone, err := c.API.ZoneDetails(c.Context, zoneID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get zone info: %w", err)
+	}
+	return c, zoneID, zone.Name, nil
+}
+
+// waitForDNSSEC polls until DNSSEC is active. It gives up when the status
+// becomes disabled or error, or when the command's --timeout expires.
+func waitForDNSSEC(cmd *cobra.Command, c *client.Client, zoneID string, d client.DNSSEC) (client.DNSSEC, error) {
+	interval, _ := cmd.Flags().GetDuration("poll-interval")
+
+	for {
+		switch d.Status {
+		case "active":
+			return d, nil
+		case "disabled", "error":
+			return d, fmt.Errorf("DNSSEC status is %s", d.Status)
+		}
+		fmt.Printf("Waiting for DNSSEC to become active (status: %s)...\n", d.Status)
+
+		select {
+		case <-c.Context.Done():
+			return d, fmt.Errorf("stopped waiting for DNSSEC: %w", c.Context.Err())
+		case <-time.After(interval):
+		}
+
+		var err error
+		d, err = c.DNSSEC(zoneID)
+		if err != nil && c.Context.Err() != nil {
+			return d, fmt.Errorf("stopped waiting for DNSSEC: %w", c.Context.Err())
+		}
+		if err != nil {
+			return d, fmt.Errorf("failed to get DNSSEC status: %w", err)
+		}
+	}
+}
+
+func renderDNSSEC(zoneName string, d client.DNSSEC) error {
+	return utils.Render(utils.View{
+		Data:    d,
+		Headers: []string{"ZONE", "STATUS", "MULTI_SIGNER", "KEY_TAG", "ALGORITHM", "DIGEST_TYPE", "DIGEST", "DS", "PUBLIC_KEY"},
+		Rows: [][]string{{
+			zoneName,
+			d.Status,
+			fmt.Sprintf("%t", d.MultiSigner),
+			fmt.Sprintf("%d", d.KeyTag),
+			d.Algorithm,
+			d.DigestType,
+			d.Digest,
+			d.DS,
+			d.PublicKey,
+		}},
+		Text: func() {
+			fmt.Printf("DNSSEC for %s:\n", zoneName)
+			fmt.Printf("  Status:        %s\n", d.Status)
+			fmt.Printf("  Multi-signer:  %s\n", utils.BoolToString(d.MultiSigner))
+			fmt.Printf("  Presigned:     %s\n", utils.BoolToString(d.Presigned))
+			if !d.ModifiedOn.IsZero() {
+				fmt.Printf("  Modified On:   %s\n", d.ModifiedOn.Format(time.RFC3339))
+			}
+			if d.Status == "disabled" || d.DS == "" {
+				return
+			}
+
+			fmt.Printf("\nDS record:\n  %s\n", d.DS)
+			fmt.Printf("\nRegistrar details:\n")
+			fmt.Printf("  Key Tag:       %d\n", d.KeyTag)
+			fmt.Printf("  Algorithm:     %s (%s)\n", d.Algorithm, d.KeyType)
+			fmt.Printf("  Digest Type:   %s (%s)\n", d.DigestType, d.DigestAlgorithm)
+			fmt.Printf("  Digest:        %s\n", d.Digest)
+			fmt.Printf("  Flags:         %d\n", d.Flags)
+			fmt.Printf("  Public Key:    %s\n", d.PublicKey)
+			if d.Status == "pending" {
+				fmt.Printf("\nAdd the DS record at the registrar; DNSSEC becomes active once Cloudflare sees it.\n")
+			}
+		},
+	})
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{zoneDNSSECStatusCmd, zoneDNSSECEnableCmd} {
+		cmd.Flags().Bool("wait", false, "Poll until DNSSEC is active (bounded by --timeout)")
+		cmd.Flags().Duration("poll-interval", 30*time.Second, "Time between status checks with --wait")
+	}
+	zoneDNSSECEnableCmd.Flags().Bool("multi-signer", false, "Enable multi-signer DNSSEC (for use with another DNS provider)")
+	zoneDNSSECDisableCmd.Flags().BoolP("yes", "y", false, "Disable without asking for confirmation")
+
+	zoneDNSSECCmd.AddCommand(zoneDNSSECStatusCmd)
+	zoneDNSSECCmd.AddCommand(zoneDNSSECEnableCmd)
+	zoneDNSSECCmd.AddCommand(zoneDNSSECDisableCmd)
+	ZoneCmd.AddCommand(zoneDNSSECCmd)
+}
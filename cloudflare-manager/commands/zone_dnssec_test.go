@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestZoneDNSSEC(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	out := e.mustRun("zone", "dnssec", "status", "example.com")
+	assertContains(t, out, "Status:        disabled")
+	if strings.Contains(out, "DS record") {
+		t.Errorf("disabled zone shows a DS record:\n%s", out)
+	}
+
+	out = e.mustRun("zone", "dnssec", "enable", "example.com", "--multi-signer")
+	assertContains(t, out,
+		"✓ DNSSEC enabled for example.com",
+		"Status:        pending",
+		"Multi-signer:  ✓",
+		"example.com. 3600 IN DS 2371 13 2 ",
+		"Algorithm:     13 (ECDSAP256SHA256)",
+		"Digest Type:   2 (SHA256)",
+		"Add the DS record at the registrar",
+	)
+
+	e.api.ActivateDNSSECAfter(zone.ID, 2)
+	out = e.mustRun("zone", "dnssec", "status", "example.com", "--wait", "--poll-interval", "1ms")
+	assertContains(t, out, "Waiting for DNSSEC to become active (status: pending)", "Status:        active")
+
+	out = e.mustRun("zone", "dnssec", "status", "example.com", "-o", "csv")
+	assertContains(t, out, "ZONE,STATUS,MULTI_SIGNER,KEY_TAG", "example.com,active,true,2371,13,2,")
+
+	e.mustRun("zone", "dnssec", "disable", "example.com", "--yes")
+	out = e.mustRun("zone", "dnssec", "status", "example.com", "-o", "json")
+	assertContains(t, out, `"status": "disabled"`, `"dnssec_multi_signer": true`)
+
+	msg := e.mustFail("zone", "dnssec", "status", "example.com", "--wait", "--poll-interval", "1ms")
+	assertContains(t, msg, "DNSSEC status is disabled")
+	msg = e.mustFail("--timeout", "50ms", "zone", "dnssec", "enable", "example.com", "--wait", "--poll-interval", "10ms")
+	assertContains(t, msg, "stopped waiting for DNSSEC")
+}
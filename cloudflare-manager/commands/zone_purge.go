@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/utils"
+	"github.com/spf13/cobra"
+)
+
+var zonePurgeCmd = &cobra.Command{
+	Use:   "purge [zone-id or domain]",
+	Short: "Purge cache for a zone",
+	Long: `Purge cached files by URL, cache tag, hostname or URL prefix, or purge
+everything. At least one of them has to be given; purging everything asks
+for confirmation unless --yes is given.
+
+--from-file reads URLs from a file, or from standard input with "-": one per
+line, blank lines and lines starting with # are skipped. A line may also be
+a JSON object with cache key headers:
+
+  {"url": "https://example.com/app.js", "headers": {"CF-Device-Type": "mobile"}}
+
+--header adds a cache key header to every URL that has none of its own.
+The API takes at most 30 items per request, so larger lists are sent in
+batches, several at a time.`,
+	Example: `  cfm zone purge example.com --files https://example.com/a.css,https://example.com/b.js
+  cfm zone purge example.com --tags product-42 --hosts static.example.com
+  cfm zone purge example.com --prefixes example.com/assets/
+  git diff --name-only | sed 's|^|https://example.com/|' | cfm zone purge example.com --from-file -
+  cfm zone purge example.com --files https://example.com/ --header "CF-Device-Type: mobile"
+  cfm zone purge example.com --everything --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		everything, _ := cmd.Flags().GetBool("everything")
+		files, _ := cmd.Flags().GetStringSlice("files")
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+		hosts, _ := cmd.Flags().GetStringSlice("hosts")
+		prefixes, _ := cmd.Flags().GetStringSlice("prefixes")
+		fromFile, _ := cmd.Flags().GetString("from-file")
+		headerSpecs, _ := cmd.Flags().GetStringArray("header")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		yes, _ := cmd.Flags().GetBool("yes")
+		if parallel < 1 {
+			parallel = 1
+		}
+
+		headers, err := parsePurgeHeaders(headerSpecs)
+		if err != nil {
+			return err
+		}
+		var purgeFiles []client.PurgeFile
+		for _, f := range files {
+			purgeFiles = append(purgeFiles, client.PurgeFile{URL: f})
+		}
+		if fromFile != "" {
+			listed, err := readPurgeFiles(fromFile)
+			if err != nil {
+				return err
+			}
+			purgeFiles = append(purgeFiles, listed...)
+		}
+		for i := range purgeFiles {
+			if purgeFiles[i].Headers == nil {
+				purgeFiles[i].Headers = headers
+			}
+		}
+
+		selective := len(purgeFiles)+len(tags)+len(hosts)+len(prefixes) > 0
+		switch {
+		case everything && selective:
+			return fmt.Errorf("--everything cannot be combined with files, tags, hosts or prefixes")
+		case !everything && !selective:
+			if fromFile != "" {
+				return fmt.Errorf("no URLs found in %s", fromFile)
+			}
+			return fmt.Errorf("nothing to purge: give --files, --tags, --hosts, --prefixes, --from-file or --everything")
+		}
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		zoneID, err := getZoneID(c, args[0])
+		if err != nil {
+			return err
+		}
+
+		if everything {
+			if !yes && !utils.Confirm(fmt.Sprintf("Purge everything cached for %s?", args[0])) {
+				fmt.Println("Purge cancelled.")
+				return nil
+			}
+			if err := c.PurgeCache(zoneID, client.PurgeRequest{Everything: true}); err != nil {
+				return fmt.Errorf("failed to purge cache: %w", err)
+			}
+			fmt.Printf("✓ Cache purged successfully\n")
+			return nil
+		}
+
+		batches := purgeBatches(purgeFiles, tags, hosts, prefixes)
+		failed := purgeConcurrently(c, zoneID, batches, parallel)
+
+		var summary []string
+		for _, part := range []struct {
+			n    int
+			noun string
+		}{{len(purgeFiles), "files"}, {len(tags), "tags"}, {len(hosts), "hosts"}, {len(prefixes), "prefixes"}} {
+			if part.n > 0 {
+				summary = append(summary, fmt.Sprintf("%d %s", part.n, part.noun))
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d purge requests failed", failed, len(batches))
+		}
+		fmt.Printf("✓ Purged %s in %d requests\n", strings.Join(summary, ", "), len(batches))
+		return nil
+	},
+}
+
+// purgeBatch is one purge request and what it covers, for messages.
+type purgeBatch struct {
+	req   client.PurgeRequest
+	label string
+}
+
+// purgeBatches splits each kind of item into requests of at most
+// client.MaxPurgeItems.
+func purgeBatches(files []client.PurgeFile, tags, hosts, prefixes []string) []purgeBatch {
+	var batches []purgeBatch
+	for start := 0; start < len(files); start += client.MaxPurgeItems {
+		end := min(start+client.MaxPurgeItems, len(files))
+		batches = append(batches, purgeBatch{
+			req:   client.PurgeRequest{Files: files[start:end]},
+			label: fmt.Sprintf("files %d-%d", start+1, end),
+		})
+	}
+	for _, kind := range []struct {
+		name  string
+		items []string
+		set   func(*client.PurgeRequest, []string)
+	}{
+		{"tags", tags, func(r *client.PurgeRequest, v []string) { r.Tags = v }},
+		{"hosts", hosts, func(r *client.PurgeRequest, v []string) { r.Hosts = v }},
+		{"prefixes", prefixes, func(r *client.PurgeRequest, v []string) { r.Prefixes = v }},
+	} {
+		for start := 0; start < len(kind.items); start += client.MaxPurgeItems {
+			end := min(start+client.MaxPurgeItems, len(kind.items))
+			b := purgeBatch{label: fmt.Sprintf("%s %d-%d", kind.name, start+1, end)}
+			kind.set(&b.req, kind.items[start:end])
+			batches = append(batches, b)
+		}
+	}
+	return batches
+}
+
+// purgeConcurrently sends the batches with at most parallel requests in
+// flight, reports each failure and returns how many failed.
+func purgeConcurrently(c *client.Client, zoneID string, batches []purgeBatch, parallel int) int {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed int
+		sem    = make(chan struct{}, parallel)
+	)
+	for _, b := range batches {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(b purgeBatch) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := c.PurgeCache(zoneID, b.req)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Printf("✗ Failed to purge %s: %v\n", b.label, err)
+				failed++
+			}
+		}(b)
+	}
+	wg.Wait()
+	return failed
+}
+
+// readPurgeFiles reads URLs from a file, or standard input for "-".
+func readPurgeFiles(path string) ([]client.PurgeFile, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var files []client.PurgeFile
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "{"):
+			var f client.PurgeFile
+			if err := json.Unmarshal([]byte(line), &f); err != nil || f.URL == "" {
+				return nil, fmt.Errorf("%s:%d: expected a URL or {\"url\": ..., \"headers\": {...}}", path, n)
+			}
+			files = append(files, f)
+		default:
+			files = append(files, client.PurgeFile{URL: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return files, nil
+}
+
+// parsePurgeHeaders parses "Name: value" headers.
+func parsePurgeHeaders(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	headers := map[string]string{}
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q (expected \"Name: value\")", spec)
+		}
+		headers[name] = value
+	}
+	return headers, nil
+}
+
+func init() {
+	zonePurgeCmd.Flags().Bool("everything", false, "Purge everything")
+	zonePurgeCmd.Flags().StringSlice("files", []string{}, "Specific files to purge")
+	zonePurgeCmd.Flags().StringSlice("tags", nil, "Cache tags to purge")
+	zonePurgeCmd.Flags().StringSlice("hosts", nil, "Hostnames to purge")
+	zonePurgeCmd.Flags().StringSlice("prefixes", nil, "URL prefixes to purge, e.g. example.com/assets/")
+	zonePurgeCmd.Flags().String("from-file", "", "Read URLs to purge from a file (\"-\" for standard input)")
+	zonePurgeCmd.Flags().StringArray("header", nil, "Cache key header sent with each URL, e.g. \"CF-Device-Type: mobile\" (repeatable)")
+	zonePurgeCmd.Flags().Int("parallel", 4, "Number of purge requests sent at the same time")
+	zonePurgeCmd.Flags().BoolP("yes", "y", false, "Purge everything without asking for confirmation")
+
+	ZoneCmd.AddCommand(zonePurgeCmd)
+}
@@ -0,0 +1,334 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/utils"
+	"github.com/cloudflare-manager/zonesettings"
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/spf13/cobra"
+)
+
+var zoneSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Show and change zone settings",
+	Long: `Show and change zone settings such as the SSL mode, Always Use HTTPS,
+the minimum TLS version, HTTP/3, Brotli and the security level.
+
+A settings profile is a YAML file with the values a zone should have:
+
+  settings:
+    ssl: strict
+    always_use_https: on
+    min_tls_version: "1.2"
+    browser_cache_ttl: 14400
+
+zone settings diff compares zones with a profile and zone settings apply
+changes them to match it.`,
+}
+
+var zoneSettingsGetCmd = &cobra.Command{
+	Use:   "get [zone-id or domain] [setting...]",
+	Short: "List a zone's settings",
+	Example: `  cfm zone settings get example.com
+  cfm zone settings get example.com ssl min_tls_version`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		zoneID, err := getZoneID(c, args[0])
+		if err != nil {
+			return err
+		}
+		settings, err := zoneSettings(c, zoneID)
+		if err != nil {
+			return err
+		}
+
+		if names := args[1:]; len(names) > 0 {
+			byID := map[string]cloudflare.ZoneSetting{}
+			for _, s := range settings {
+				byID[s.ID] = s
+			}
+			settings = settings[:0:0]
+			for _, name := range names {
+				s, ok := byID[name]
+				if !ok {
+					return fmt.Errorf("zone has no setting %q", name)
+				}
+				settings = append(settings, s)
+			}
+		}
+
+		var rows [][]string
+		for _, s := range settings {
+			modified := s.ModifiedOn
+			if len(modified) > 10 {
+				modified = modified[:10]
+			}
+			rows = append(rows, []string{s.ID, zonesettings.Format(s.Value), utils.BoolToString(s.Editable), modified})
+		}
+		return utils.Render(utils.View{
+			Data:     settings,
+			Headers:  []string{"SETTING", "VALUE", "EDITABLE", "MODIFIED"},
+			Rows:     rows,
+			MaxWidth: map[string]int{"VALUE": 60},
+		})
+	},
+}
+
+var zoneSettingsSetCmd = &cobra.Command{
+	Use:   "set [zone-id or domain] <setting>=<value>...",
+	Short: "Change zone settings",
+	Example: `  cfm zone settings set example.com ssl=strict
+  cfm zone settings set example.com always_use_https=on min_tls_version=1.2 browser_cache_ttl=3600`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile := &zonesettings.Profile{Settings: map[string]interface{}{}}
+		for _, arg := range args[1:] {
+			name, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return fmt.Errorf("invalid setting %q (expected name=value)", arg)
+			}
+			v, err := zonesettings.Normalize(name, value)
+			if err != nil {
+				return err
+			}
+			profile.Settings[name] = v
+		}
+
+		c, err := client.NewFromConfig()
+		if err != nil {
+			return err
+		}
+
+		zoneID, err := getZoneID(c, args[0])
+		if err != nil {
+			return err
+		}
+		current, err := zoneSettings(c, zoneID)
+		if err != nil {
+			return err
+		}
+
+		changes := profile.Diff(current)
+		if len(changes) == 0 {
+			fmt.Println("Settings already have these values.")
+			return nil
+		}
+		if _, err := c.API.UpdateZoneSettings(c.Context, zoneID, zonesettings.Updates(changes)); err != nil {
+			return fmt.Errorf("failed to update settings: %w", err)
+		}
+		for _, ch := range changes {
+			fmt.Printf("✓ %s: %s → %s\n", ch.Name, ch.From, ch.To)
+		}
+		return nil
+	},
+}
+
+// zoneSettingsDiff is the difference between one zone and a profile.
+type zoneSettingsDiff struct {
+	Zone    string                `json:"zone"`
+	ZoneID  string                `json:"zone_id"`
+	Changes []zonesettings.Change `json:"changes"`
+}
+
+var zoneSettingsDiffCmd = &cobra.Command{
+	Use:   "diff [zone-id or domain...]",
+	Short: "Compare zones with a settings profile",
+	Example: `  cfm zone settings diff example.com -f baseline.yaml
+  cfm zone settings diff --all -f baseline.yaml -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, profile, zones, err := settingsProfileZones(cmd, args)
+		if err != nil {
+			return err
+		}
+		diffs, err := diffZoneSettings(c, profile, zones)
+		if err != nil {
+			return err
+		}
+
+		var rows [][]string
+		for _, d := range diffs {
+			for _, ch := range d.Changes {
+				rows = append(rows, []string{d.Zone, ch.Name, ch.From, ch.To})
+			}
+		}
+		return utils.Render(utils.View{
+			Data:    diffs,
+			Headers: []string{"ZONE", "SETTING", "CURRENT", "PROFILE"},
+			Rows:    rows,
+			Text:    func() { printSettingsDiffs(diffs) },
+		})
+	},
+}
+
+var zoneSettingsApplyCmd = &cobra.Command{
+	Use:   "apply [zone-id or domain...]",
+	Short: "Change zones to match a settings profile",
+	Long: `Compare each zone with the settings profile, show the settings that differ
+and, after confirmation, change them. Settings the profile does not mention
+are left alone.`,
+	Example: `  cfm zone settings apply example.com example.org -f baseline.yaml
+  cfm zone settings apply --all -f baseline.yaml --dry-run
+  cfm zone settings apply --all -f baseline.yaml --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		c, profile, zones, err := settingsProfileZones(cmd, args)
+		if err != nil {
+			return err
+		}
+		diffs, err := diffZoneSettings(c, profile, zones)
+		if err != nil {
+			return err
+		}
+
+		printSettingsDiffs(diffs)
+		var pending []zoneSettingsDiff
+		for _, d := range diffs {
+			if len(d.Changes) > 0 {
+				pending = append(pending, d)
+			}
+		}
+		if dryRun || len(pending) == 0 {
+			return nil
+		}
+
+		fmt.Println()
+		if !yes && !utils.Confirm(fmt.Sprintf("Change settings of %d zones?", len(pending))) {
+			fmt.Println("Apply cancelled.")
+			return nil
+		}
+
+		failed := 0
+		for _, d := range pending {
+			if _, err := c.API.UpdateZoneSettings(c.Context, d.ZoneID, zonesettings.Updates(d.Changes)); err != nil {
+				fmt.Printf("✗ %s: %v\n", d.Zone, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s: %d settings changed\n", d.Zone, len(d.Changes))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d zones failed", failed, len(pending))
+		}
+		return nil
+	},
+}
+
+// settingsProfileZones loads the --file profile and resolves the zones
+// given as arguments, or every zone of the account with --all.
+func settingsProfileZones(cmd *cobra.Command, args []string) (*client.Client, *zonesettings.Profile, []cloudflare.Zone, error) {
+	file, _ := cmd.Flags().GetString("file")
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		return nil, nil, nil, fmt.Errorf("give one or more zones, or --all")
+	}
+
+	profile, err := zonesettings.LoadProfile(file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	c, err := client.NewFromConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if all {
+		zones, err := c.API.ListZonesContext(c.Context, cloudflare.WithZoneFilters("", c.Account.AccountID, ""))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to list zones: %w", err)
+		}
+		sort.Slice(zones.Result, func(i, j int) bool { return zones.Result[i].Name < zones.Result[j].Name })
+		return c, profile, zones.Result, nil
+	}
+
+	var zones []cloudflare.Zone
+	for _, arg := range args {
+		zoneID, err := getZoneID(c, arg)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		zone, err := c.API.ZoneDetails(c.Context, zoneID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get zone info: %w", err)
+		}
+		zones = append(zones, zone)
+	}
+	return c, profile, zones, nil
+}
+
+func diffZoneSettings(c *client.Client, profile *zonesettings.Profile, zones []cloudflare.Zone) ([]zoneSettingsDiff, error) {
+	var diffs []zoneSettingsDiff
+	for _, z := range zones {
+		current, err := zoneSettings(c, z.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", z.Name, err)
+		}
+		diffs = append(diffs, zoneSettingsDiff{Zone: z.Name, ZoneID: z.ID, Changes: profile.Diff(current)})
+	}
+	return diffs, nil
+}
+
+func printSettingsDiffs(diffs []zoneSettingsDiff) {
+	differ := 0
+	for _, d := range diffs {
+		if len(d.Changes) == 0 {
+			fmt.Printf("✓ %s matches the profile\n", d.Zone)
+			continue
+		}
+		differ++
+		fmt.Printf("%s:\n", d.Zone)
+		for _, ch := range d.Changes {
+			fmt.Printf("  ~ %-24s %s → %s\n", ch.Name, ch.From, ch.To)
+		}
+	}
+	fmt.Printf("\n%d of %d zones differ from the profile\n", differ, len(diffs))
+}
+
+func zoneSettings(c *client.Client, zoneID string) ([]cloudflare.ZoneSetting, error) {
+	res, err := c.API.ZoneSettings(c.Context, zoneID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get zone settings: %w", err)
+	}
+	settings := res.Result
+	sort.Slice(settings, func(i, j int) bool { return settings[i].ID < settings[j].ID })
+	return settings, nil
+}
+
+// knownSettingsHelp lists the settings set and profiles accept.
+func knownSettingsHelp() string {
+	var b strings.Builder
+	b.WriteString("Settings and their values:\n")
+	for _, s := range zonesettings.Known() {
+		fmt.Fprintf(&b, "  %-26s %s (%s)\n", s.Name, s.Description, s.Type())
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func init() {
+	zoneSettingsSetCmd.Long = "Change one or more settings of a zone. Values are checked before anything\nis sent.\n\n" + knownSettingsHelp()
+
+	for _, cmd := range []*cobra.Command{zoneSettingsDiffCmd, zoneSettingsApplyCmd} {
+		cmd.Flags().StringP("file", "f", "", "Settings profile (YAML)")
+		cmd.Flags().Bool("all", false, "Use every zone of the account")
+		cmd.MarkFlagRequired("file")
+	}
+	zoneSettingsApplyCmd.Flags().Bool("dry-run", false, "Only show the settings that would change")
+	zoneSettingsApplyCmd.Flags().BoolP("yes", "y", false, "Apply without asking for confirmation")
+
+	zoneSettingsCmd.AddCommand(zoneSettingsGetCmd)
+	zoneSettingsCmd.AddCommand(zoneSettingsSetCmd)
+	zoneSettingsCmd.AddCommand(zoneSettingsDiffCmd)
+	zoneSettingsCmd.AddCommand(zoneSettingsApplyCmd)
+	ZoneCmd.AddCommand(zoneSettingsCmd)
+}
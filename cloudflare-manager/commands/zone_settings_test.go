@@ -0,0 +1,85 @@
+package commands
+
+import "testing"
+
+func TestZoneSettingsGetSet(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	out := e.mustRun("zone", "settings", "get", "example.com")
+	assertContains(t, out, "SETTING", "always_use_https", "browser_cache_ttl", "14400", "ssl", "flexible")
+
+	out = e.mustRun("zone", "settings", "set", "example.com", "ssl=strict", "always_use_https=true", "min_tls_version=1.2", "browser_cache_ttl=3600")
+	assertContains(t, out,
+		"✓ always_use_https: off → on",
+		"✓ browser_cache_ttl: 14400 → 3600",
+		"✓ min_tls_version: 1.0 → 1.2",
+		"✓ ssl: flexible → strict",
+	)
+	got := e.api.ZoneSettings(zone.ID)
+	if got["ssl"] != "strict" || got["always_use_https"] != "on" || got["browser_cache_ttl"] != float64(3600) {
+		t.Errorf("settings not updated: %v", got)
+	}
+
+	out = e.mustRun("zone", "settings", "get", "example.com", "ssl", "-o", "csv")
+	assertContains(t, out, "ssl,strict,")
+
+	for msg, setting := range map[string]string{
+		"ssl must be one of off, flexible, full, strict": "ssl=very",
+		"http3 must be on or off":                        "http3=yes",
+		"browser_cache_ttl 17 is not allowed":            "browser_cache_ttl=17",
+		`unknown zone setting "nope"`:                    "nope=on",
+		"http2 is not editable":                          "http2=off",
+		`invalid setting "ssl" (expected name=value)`:    "ssl",
+	} {
+		assertContains(t, e.mustFail("zone", "settings", "set", "example.com", setting), msg)
+	}
+}
+
+func TestZoneSettingsProfile(t *testing.T) {
+	e := newTestEnv(t)
+	a := e.api.AddZone(e.account.ID, "example.com")
+	b := e.api.AddZone(e.account.ID, "example.org")
+	profile := writeFile(t, "baseline.yaml", `settings:
+  ssl: strict
+  always_use_https: on
+  min_tls_version: 1.2
+  0rtt: off
+`)
+
+	e.mustRun("zone", "settings", "set", "example.org", "ssl=strict", "always_use_https=on")
+
+	out := e.mustRun("zone", "settings", "diff", "--all", "-f", profile)
+	assertContains(t, out,
+		"example.com:",
+		"  ~ always_use_https         off → on",
+		"  ~ min_tls_version          1.0 → 1.2",
+		"  ~ ssl                      flexible → strict",
+		"example.org:",
+		"2 of 2 zones differ from the profile",
+	)
+
+	out = e.mustRun("zone", "settings", "diff", "example.org", "-f", profile, "-o", "json")
+	assertContains(t, out, `"zone": "example.org"`, `"name": "min_tls_version"`, `"to": "1.2"`)
+
+	out = e.mustRun("zone", "settings", "apply", "--all", "-f", profile, "--dry-run")
+	assertContains(t, out, "2 of 2 zones differ from the profile")
+	if e.api.ZoneSettings(a.ID)["ssl"] != "flexible" {
+		t.Fatalf("dry run changed settings")
+	}
+
+	out = e.mustRun("zone", "settings", "apply", "--all", "-f", profile, "--yes")
+	assertContains(t, out, "✓ example.com: 3 settings changed", "✓ example.org: 1 settings changed")
+	for _, id := range []string{a.ID, b.ID} {
+		if got := e.api.ZoneSettings(id); got["ssl"] != "strict" || got["min_tls_version"] != "1.2" || got["0rtt"] != "off" {
+			t.Errorf("zone %s not changed: %v", id, got)
+		}
+	}
+
+	out = e.mustRun("zone", "settings", "diff", "example.com", "example.org", "-f", profile)
+	assertContains(t, out, "✓ example.com matches the profile", "0 of 2 zones differ from the profile")
+
+	bad := writeFile(t, "bad.yaml", "settings:\n  ssl: maybe\n")
+	assertContains(t, e.mustFail("zone", "settings", "apply", "example.com", "-f", bad), "ssl must be one of")
+	assertContains(t, e.mustFail("zone", "settings", "diff", "-f", profile), "give one or more zones, or --all")
+}
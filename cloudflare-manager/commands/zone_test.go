@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestZoneCommands(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	out := e.mustRun("zone", "list")
+	assertContains(t, out, "example.com", zone.ID, "active")
+
+	out = e.mustRun("zone", "create", "example.org")
+	assertContains(t, out, "Zone 'example.org' created successfully", "pending", "ada.ns.cloudflare.com")
+
+	out = e.mustRun("zone", "info", "example.com")
+	assertContains(t, out, "Zone Information:", zone.ID, "Free Website")
+
+	out = e.mustRun("zone", "list", "-o", "json")
+	var zones []cloudflare.Zone
+	if err := json.Unmarshal([]byte(out), &zones); err != nil {
+		t.Fatalf("zone list -o json: %v\n%s", err, out)
+	}
+	if len(zones) != 2 {
+		t.Errorf("zone list returned %d zones, want 2", len(zones))
+	}
+
+	e.mustRun("zone", "delete", "example.org")
+	out = e.mustRun("zone", "list", "--template", "{{.Name}}")
+	if out != "example.com\n" {
+		t.Errorf("zone list after delete = %q, want only example.com", out)
+	}
+
+	if msg := e.mustFail("zone", "info", "missing.example"); msg != "zone not found: missing.example" {
+		t.Errorf("unexpected error for unknown zone: %s", msg)
+	}
+}
+
+func TestZoneListPagination(t *testing.T) {
+	e := newTestEnv(t)
+	for i := 0; i < 45; i++ {
+		e.api.AddZone(e.account.ID, fmt.Sprintf("z%02d.example.com", i))
+	}
+	// Zones of other accounts the token can reach are not listed.
+	e.api.AddZone(e.addAccount("other").ID, "other.example.com")
+	countZones := func(out string) int { return strings.Count(out, ".example.com") }
+
+	// Every zone by default.
+	out := e.mustRun("zone", "list")
+	if n := countZones(out); n != 45 || strings.Contains(out, "More zones") {
+		t.Errorf("zone list showed %d zones, want 45:\n%s", n, out)
+	}
+
+	// One page of the API's default size.
+	out = e.mustRun("zone", "list", "--page", "1")
+	if n := countZones(out); n != 20 {
+		t.Errorf("zone list --page 1 showed %d zones, want 20", n)
+	}
+	assertContains(t, out, "More zones available: continue with --page 2, or use --all.")
+
+	// Pages hold at least 5 zones, so a smaller limit cuts the first one.
+	out = e.mustRun("zone", "list", "--limit", "3")
+	if n := countZones(out); n != 3 {
+		t.Errorf("zone list --limit 3 showed %d zones, want 3", n)
+	}
+	assertContains(t, out, "More zones available: use --all to list them.")
+
+	out = e.mustRun("zone", "list", "--limit", "10", "--page", "3")
+	assertContains(t, out, "z20.example.com", "z29.example.com", "continue with --limit 10 --page 4")
+	if n := countZones(out); n != 10 {
+		t.Errorf("zone list --limit 10 --page 3 showed %d zones, want 10", n)
+	}
+
+	out = e.mustRun("zone", "list", "--all", "-o", "json")
+	var zones []cloudflare.Zone
+	if err := json.Unmarshal([]byte(out), &zones); err != nil {
+		t.Fatalf("zone list --all -o json: %v\n%s", err, out)
+	}
+	if len(zones) != 45 {
+		t.Errorf("zone list --all returned %d zones, want 45", len(zones))
+	}
+
+	out = e.mustRun("zone", "list", "--page", "3")
+	if n := countZones(out); n != 5 || strings.Contains(out, "More zones") {
+		t.Errorf("last page showed %d zones:\n%s", n, out)
+	}
+
+	if msg := e.mustFail("zone", "list", "--all", "--limit", "5"); msg != "--all and --limit cannot be combined" {
+		t.Errorf("unexpected error: %s", msg)
+	}
+}
+
+func TestZonePurge(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	e.mustRun("zone", "purge", "example.com", "--files", "https://example.com/a.css,https://example.com/b.js")
+	out := e.mustRun("zone", "purge", "example.com", "--everything")
+	assertContains(t, out, "Purge everything cached for example.com? [y/N]", "Purge cancelled.")
+	e.mustRun("zone", "purge", "example.com", "--everything", "--yes")
+
+	purges := e.api.Purges(zone.ID)
+	if len(purges) != 2 {
+		t.Fatalf("got %d purge requests, want 2", len(purges))
+	}
+	if len(purges[0].Files) != 2 || purges[0].Everything {
+		t.Errorf("first purge = %+v, want two files", purges[0])
+	}
+	if !purges[1].Everything {
+		t.Errorf("second purge = %+v, want everything", purges[1])
+	}
+
+	assertContains(t, e.mustFail("zone", "purge", "example.com"), "nothing to purge")
+	assertContains(t, e.mustFail("zone", "purge", "example.com", "--everything", "--tags", "a"), "cannot be combined")
+}
+
+func TestZonePurgeBatches(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+
+	var lines []string
+	for i := 1; i <= 65; i++ {
+		lines = append(lines, fmt.Sprintf("https://example.com/%d.js", i))
+	}
+	lines = append(lines, "", "# comment", `{"url": "https://example.com/", "headers": {"CF-Device-Type": "mobile"}}`)
+	setStdin(t, strings.Join(lines, "\n"))
+
+	tags := make([]string, 31)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	out := e.mustRun("zone", "purge", "example.com", "--from-file", "-", "--header", "Origin: https://app.example.com",
+		"--tags", strings.Join(tags, ","), "--hosts", "static.example.com", "--prefixes", "example.com/assets/")
+	assertContains(t, out, "✓ Purged 66 files, 31 tags, 1 hosts, 1 prefixes in 7 requests")
+
+	files := map[string]map[string]string{}
+	var tagCount, requests int
+	for _, p := range e.api.Purges(zone.ID) {
+		requests++
+		if len(p.Files)+len(p.Tags)+len(p.Hosts)+len(p.Prefixes) > 30 || p.Everything {
+			t.Errorf("bad batch %+v", p)
+		}
+		for _, f := range p.Files {
+			files[f.URL] = f.Headers
+		}
+		tagCount += len(p.Tags)
+	}
+	if requests != 7 || len(files) != 66 || tagCount != 31 {
+		t.Fatalf("got %d requests, %d files, %d tags", requests, len(files), tagCount)
+	}
+	if h := files["https://example.com/"]; h["CF-Device-Type"] != "mobile" || len(h) != 1 {
+		t.Errorf("JSON line headers = %v", h)
+	}
+	if h := files["https://example.com/7.js"]; h["Origin"] != "https://app.example.com" {
+		t.Errorf("--header not applied: %v", h)
+	}
+
+	empty := writeFile(t, "urls.txt", "# nothing\n")
+	assertContains(t, e.mustFail("zone", "purge", "example.com", "--from-file", empty), "no URLs found in")
+	bad := writeFile(t, "bad.txt", "{\"headers\": {}}\n")
+	assertContains(t, e.mustFail("zone", "purge", "example.com", "--from-file", bad), "bad.txt:1: expected a URL")
+}
+
+func TestZoneResolution(t *testing.T) {
+	e := newTestEnv(t)
+	zone := e.api.AddZone(e.account.ID, "example.com")
+	e.api.AddDNSRecord(zone.ID, cloudflare.DNSRecord{Type: "A", Name: "api", Content: "192.0.2.1"})
+
+	countZoneLookups := func() int {
+		n := 0
+		for _, req := range e.api.Requests() {
+			if req == "GET /zones" {
+				n++
+			}
+		}
+		return n
+	}
+
+	// An ID is used as is.
+	e.mustRun("dns", "list", zone.ID)
+	if n := countZoneLookups(); n != 0 {
+		t.Errorf("resolving a zone ID made %d zone lookups, want 0", n)
+	}
+
+	// A hostname resolves to its parent zone: api.example.com misses, then
+	// example.com is found and cached.
+	out := e.mustRun("dns", "list", "api.example.com")
+	assertContains(t, out, "api.example.com", "192.0.2.1")
+	if n := countZoneLookups(); n != 2 {
+		t.Errorf("resolving a hostname made %d zone lookups, want 2", n)
+	}
+
+	e.mustRun("dns", "list", "example.com")
+	e.mustRun("dns", "list", "www.example.com.")
+	if n := countZoneLookups(); n != 2 {
+		t.Errorf("cached names made %d more zone lookups, want none", n-2)
+	}
+
+	other := e.api.AddZone(e.account.ID, "example.net")
+	// Zones of other accounts the token can reach stay out of the cache.
+	e.api.AddZone(e.addAccount("other").ID, "example.org")
+	out = e.mustRun("zone", "refresh-cache")
+	assertContains(t, out, "Cached 2 zones")
+	before := countZoneLookups()
+	if out := e.mustRun("zone", "info", "example.net"); !strings.Contains(out, other.ID) {
+		t.Errorf("zone info example.net does not show %s:\n%s", other.ID, out)
+	}
+	if n := countZoneLookups() - before; n != 0 {
+		t.Errorf("refreshed cache still made %d zone lookups", n)
+	}
+
+	assertContains(t, e.mustFail("zone", "info", "example.org"), "zone not found")
+
+	e.mustRun("zone", "delete", "example.net")
+	e.mustFail("zone", "info", "example.net")
+}
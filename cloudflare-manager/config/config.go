@@ -4,25 +4,109 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/cloudflare-manager/secrets"
 	"gopkg.in/yaml.v3"
 )
 
+// Authentication methods for an account.
+const (
+	AuthTypeToken  = "token"
+	AuthTypeAPIKey = "api_key"
+)
+
 type Account struct {
 	Name      string `yaml:"name"`
-	APIToken  string `yaml:"api_token"`
+	AuthType  string `yaml:"auth_type,omitempty"`
+	APIToken  string `yaml:"api_token,omitempty"`
+	APIKey    string `yaml:"api_key,omitempty"`
+	TokenRef  string `yaml:"api_token_ref,omitempty"`
 	AccountID string `yaml:"account_id,omitempty"`
 	Email     string `yaml:"email,omitempty"`
+	// BaseURL points the client at another API endpoint, e.g. a proxy or a
+	// fake server in tests. Empty means api.cloudflare.com.
+	BaseURL string `yaml:"base_url,omitempty"`
+}
+
+// UsesAPIKey reports whether the account authenticates with a Global API
+// Key and email instead of an API token.
+func (a *Account) UsesAPIKey() bool {
+	return a.AuthType == AuthTypeAPIKey
+}
+
+// Credential returns the API token or Global API Key, depending on AuthType.
+func (a *Account) Credential() string {
+	if a.UsesAPIKey() {
+		return a.APIKey
+	}
+	return a.APIToken
+}
+
+func (a *Account) setCredential(secret string) {
+	if a.UsesAPIKey() {
+		a.APIKey = secret
+	} else {
+		a.APIToken = secret
+	}
+}
+
+// AuthDescription is a short human-readable name for the auth method.
+func (a *Account) AuthDescription() string {
+	if a.UsesAPIKey() {
+		return "API key"
+	}
+	return "API token"
+}
+
+// LoadSecret fetches the credential TokenRef points to, unless it is
+// already set. Load leaves references unresolved so that only accounts that
+// are actually used unlock the keyring or vault.
+func (a *Account) LoadSecret() error {
+	if a.TokenRef == "" || a.Credential() != "" {
+		return nil
+	}
+	secret, err := secrets.Resolve(a.TokenRef, VaultPath())
+	if err != nil {
+		return err
+	}
+	a.setCredential(secret)
+	return nil
 }
 
 type Config struct {
-	CurrentAccount string    `yaml:"current_account"`
-	Accounts       []Account `yaml:"accounts"`
+	CurrentAccount string `yaml:"current_account"`
+	SecretsBackend string `yaml:"secrets_backend,omitempty"`
+	// AutoSnapshot makes mutating dns commands save a snapshot of the zone
+	// before changing it.
+	AutoSnapshot bool      `yaml:"auto_snapshot,omitempty"`
+	Accounts     []Account `yaml:"accounts"`
 }
 
+// Environment variables that override the saved configuration for a single
+// invocation.
+const (
+	EnvConfig    = "CFM_CONFIG"
+	EnvAccount   = "CFM_ACCOUNT"
+	EnvAPIToken  = "CFM_API_TOKEN"
+	EnvAPIKey    = "CFM_API_KEY"
+	EnvEmail     = "CFM_EMAIL"
+	EnvAccountID = "CFM_ACCOUNT_ID"
+	EnvBaseURL   = "CFM_API_BASE_URL"
+	EnvCacheDir  = "CFM_CACHE_DIR"
+	EnvStateDir  = "CFM_STATE_DIR"
+)
+
 var configPath string
 
+// selectedAccount is set from the --account flag.
+var selectedAccount string
+
 func init() {
+	if path := os.Getenv(EnvConfig); path != "" {
+		configPath = path
+		return
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		panic(err)
@@ -30,10 +114,106 @@ func init() {
 	configPath = filepath.Join(home, ".cloudflare-manager.yaml")
 }
 
+func SetConfigPath(path string) {
+	configPath = path
+}
+
+// SelectAccount makes ActiveAccount use the named account instead of the
+// current one, without changing the saved configuration.
+func SelectAccount(name string) {
+	selectedAccount = name
+}
+
+// ActiveAccount returns the account commands should run against. In order of
+// precedence: the --account flag, CFM_API_TOKEN or CFM_API_KEY with
+// CFM_EMAIL (no config file needed), CFM_ACCOUNT, and finally the saved
+// current account. CFM_ACCOUNT_ID and CFM_API_BASE_URL override the account
+// ID and API endpoint of whichever account is chosen.
+func ActiveAccount() (*Account, error) {
+	var account *Account
+
+	if selectedAccount == "" && os.Getenv(EnvAPIToken) != "" {
+		account = &Account{
+			Name:     "env",
+			AuthType: AuthTypeToken,
+			APIToken: os.Getenv(EnvAPIToken),
+		}
+	} else if selectedAccount == "" && os.Getenv(EnvAPIKey) != "" {
+		account = &Account{
+			Name:     "env",
+			AuthType: AuthTypeAPIKey,
+			APIKey:   os.Getenv(EnvAPIKey),
+			Email:    os.Getenv(EnvEmail),
+		}
+	} else {
+		cfg, err := Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+
+		name := selectedAccount
+		if name == "" {
+			name = os.Getenv(EnvAccount)
+		}
+
+		if name != "" {
+			account, err = cfg.GetAccount(name)
+		} else {
+			account, err = cfg.GetCurrentAccount()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if id := os.Getenv(EnvAccountID); id != "" {
+		account.AccountID = id
+	}
+	if url := os.Getenv(EnvBaseURL); url != "" {
+		account.BaseURL = url
+	}
+	return account, nil
+}
+
 func GetConfigPath() string {
 	return configPath
 }
 
+// CacheDir is where cached API lookups are kept: $CFM_CACHE_DIR, or a
+// cloudflare-manager directory in the user's cache directory.
+func CacheDir() (string, error) {
+	if dir := os.Getenv(EnvCacheDir); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cloudflare-manager"), nil
+}
+
+// StateDir is where local state such as DNS snapshots is kept:
+// $CFM_STATE_DIR, $XDG_STATE_HOME/cloudflare-manager, or
+// ~/.local/state/cloudflare-manager.
+func StateDir() (string, error) {
+	if dir := os.Getenv(EnvStateDir); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "cloudflare-manager"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "cloudflare-manager"), nil
+}
+
+// VaultPath is where the encrypted token vault lives, next to the config file.
+func VaultPath() string {
+	return strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".vault"
+}
+
 func Load() (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -51,14 +231,101 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) Save() error {
-	data, err := yaml.Marshal(c)
+	out := *c
+	out.Accounts = make([]Account, len(c.Accounts))
+	for i, acc := range c.Accounts {
+		if acc.TokenRef != "" {
+			acc.APIToken = ""
+			acc.APIKey = ""
+		}
+		out.Accounts[i] = acc
+	}
+
+	data, err := yaml.Marshal(&out)
 	if err != nil {
 		return err
 	}
 	return os.WriteFile(configPath, data, 0600)
 }
 
+// storeSecret moves the account's credential into backend and replaces it with a
+// reference.
+func storeSecret(account *Account, backend string) error {
+	store, err := secrets.Open(backend, VaultPath())
+	if err != nil {
+		return err
+	}
+	if err := store.Set(account.Name, account.Credential()); err != nil {
+		return err
+	}
+	account.TokenRef = secrets.Ref(backend, account.Name)
+	return nil
+}
+
+// MigrateSecrets moves every token into backend, from the config file or
+// another backend, and makes it the default for accounts added later.
+// Tokens moved out of another backend are deleted there once the config is
+// saved. It returns the names of the accounts that were migrated.
+func (c *Config) MigrateSecrets(backend string) ([]string, error) {
+	if _, err := secrets.Open(backend, VaultPath()); err != nil {
+		return nil, err
+	}
+
+	var migrated, oldRefs []string
+	for i := range c.Accounts {
+		acc := &c.Accounts[i]
+		if acc.TokenRef == secrets.Ref(backend, acc.Name) {
+			continue
+		}
+		if err := acc.LoadSecret(); err != nil {
+			return migrated, fmt.Errorf("account %s: %w", acc.Name, err)
+		}
+		if acc.Credential() == "" {
+			continue
+		}
+		oldRef := acc.TokenRef
+		if err := storeSecret(acc, backend); err != nil {
+			return migrated, fmt.Errorf("account %s: %w", acc.Name, err)
+		}
+		migrated = append(migrated, acc.Name)
+		if oldRef != "" {
+			oldRefs = append(oldRefs, oldRef)
+		}
+	}
+
+	c.SecretsBackend = backend
+	if err := c.Save(); err != nil {
+		return migrated, err
+	}
+	for _, ref := range oldRefs {
+		if err := deleteSecret(ref); err != nil {
+			return migrated, fmt.Errorf("failed to delete old token %s: %w", ref, err)
+		}
+	}
+	return migrated, nil
+}
+
+// deleteSecret removes the secret a reference points to.
+func deleteSecret(ref string) error {
+	backend, key, err := secrets.ParseRef(ref)
+	if err != nil {
+		return err
+	}
+	store, err := secrets.Open(backend, VaultPath())
+	if err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
+
 func (c *Config) AddAccount(account Account) error {
+	account.TokenRef = ""
+	if c.SecretsBackend != "" && account.Credential() != "" {
+		if err := storeSecret(&account, c.SecretsBackend); err != nil {
+			return err
+		}
+	}
+
 	for i, acc := range c.Accounts {
 		if acc.Name == account.Name {
 			c.Accounts[i] = account
@@ -75,6 +342,9 @@ func (c *Config) AddAccount(account Account) error {
 func (c *Config) RemoveAccount(name string) error {
 	for i, acc := range c.Accounts {
 		if acc.Name == name {
+			if acc.TokenRef != "" {
+				deleteSecret(acc.TokenRef)
+			}
 			c.Accounts = append(c.Accounts[:i], c.Accounts[i+1:]...)
 			if c.CurrentAccount == name {
 				if len(c.Accounts) > 0 {
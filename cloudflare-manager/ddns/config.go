@@ -0,0 +1,132 @@
+package ddns
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults used when the config leaves a field out.
+const (
+	DefaultInterval   = 5 * time.Minute
+	DefaultBackoffMin = 10 * time.Second
+	DefaultBackoffMax = 5 * time.Minute
+)
+
+// DefaultIPv4URLs and DefaultIPv6URLs are the echo services asked when a
+// detector has no source configured. Each returns the caller's address as
+// plain text.
+var (
+	DefaultIPv4URLs = []string{"https://api.ipify.org", "https://ipv4.icanhazip.com"}
+	DefaultIPv6URLs = []string{"https://api6.ipify.org", "https://ipv6.icanhazip.com"}
+)
+
+// Config is the ddns YAML file.
+//
+//	interval: 5m
+//	ipv4:
+//	  urls: [https://api.ipify.org]
+//	ipv6:
+//	  interface: eth0
+//	records:
+//	  - zone: example.com
+//	    name: home
+//	    proxied: false
+type Config struct {
+	Interval   time.Duration `yaml:"interval,omitempty"`
+	BackoffMin time.Duration `yaml:"backoff_min,omitempty"`
+	BackoffMax time.Duration `yaml:"backoff_max,omitempty"`
+
+	// IPv4 and IPv6 select how each address is detected. A family that is
+	// not configured is not updated.
+	IPv4 *DetectorConfig `yaml:"ipv4,omitempty"`
+	IPv6 *DetectorConfig `yaml:"ipv6,omitempty"`
+
+	Records []RecordConfig `yaml:"records"`
+}
+
+// DetectorConfig picks one address source: HTTP echo URLs (tried in order),
+// a local network interface, or a shell command that prints the address.
+// With nothing set the default echo URLs are used.
+type DetectorConfig struct {
+	URLs      []string `yaml:"urls,omitempty"`
+	Interface string   `yaml:"interface,omitempty"`
+	Command   string   `yaml:"command,omitempty"`
+}
+
+// RecordConfig is a hostname kept pointed at the detected addresses. It gets
+// an A record for IPv4 and an AAAA record for IPv6; missing records are
+// created with TTL and Proxied.
+type RecordConfig struct {
+	Zone    string `yaml:"zone"`
+	Name    string `yaml:"name"`
+	TTL     int    `yaml:"ttl,omitempty"`
+	Proxied *bool  `yaml:"proxied,omitempty"`
+}
+
+// LoadConfig reads and validates a ddns config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	if c.Interval == 0 {
+		c.Interval = DefaultInterval
+	}
+	if c.BackoffMin == 0 {
+		c.BackoffMin = DefaultBackoffMin
+	}
+	if c.BackoffMax == 0 {
+		c.BackoffMax = DefaultBackoffMax
+	}
+	if c.Interval < 0 || c.BackoffMin < 0 || c.BackoffMax < c.BackoffMin {
+		return fmt.Errorf("interval and backoff durations must be positive, with backoff_min <= backoff_max")
+	}
+
+	if c.IPv4 == nil && c.IPv6 == nil {
+		return fmt.Errorf("configure at least one of ipv4 or ipv6")
+	}
+	for family, d := range map[string]*DetectorConfig{"ipv4": c.IPv4, "ipv6": c.IPv6} {
+		if d == nil {
+			continue
+		}
+		sources := 0
+		if len(d.URLs) > 0 {
+			sources++
+		}
+		if d.Interface != "" {
+			sources++
+		}
+		if d.Command != "" {
+			sources++
+		}
+		if sources > 1 {
+			return fmt.Errorf("%s: set only one of urls, interface or command", family)
+		}
+	}
+
+	if len(c.Records) == 0 {
+		return fmt.Errorf("no records configured")
+	}
+	for i, r := range c.Records {
+		if strings.TrimSpace(r.Zone) == "" || strings.TrimSpace(r.Name) == "" {
+			return fmt.Errorf("record %d needs both zone and name", i+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Package ddns keeps A and AAAA records pointed at the machine's current
+// public addresses.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/cloudflare-manager/client"
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Updater compares detected addresses with the configured records and
+// updates the records that differ.
+type Updater struct {
+	client    *client.Client
+	config    *Config
+	detectors map[Family]Detector
+	log       *slog.Logger
+
+	// last holds the address each family was successfully synced to, so
+	// records are only re-read when the address changes.
+	last  map[Family]string
+	zones map[string]zoneRef
+}
+
+type zoneRef struct {
+	id   string
+	name string
+}
+
+// New builds an Updater with the detectors selected in cfg.
+func New(c *client.Client, cfg *Config, log *slog.Logger) *Updater {
+	u := &Updater{
+		client:    c,
+		config:    cfg,
+		detectors: map[Family]Detector{},
+		log:       log,
+		last:      map[Family]string{},
+		zones:     map[string]zoneRef{},
+	}
+	if cfg.IPv4 != nil {
+		u.detectors[IPv4] = NewDetector(IPv4, *cfg.IPv4)
+	}
+	if cfg.IPv6 != nil {
+		u.detectors[IPv6] = NewDetector(IPv6, *cfg.IPv6)
+	}
+	return u
+}
+
+// SetDetector replaces the detector for a family.
+func (u *Updater) SetDetector(family Family, d Detector) {
+	u.detectors[family] = d
+}
+
+// Run syncs every interval until ctx is cancelled. After a failed pass it
+// retries sooner, with jittered exponential backoff capped at the interval
+// and backoff_max.
+func (u *Updater) Run(ctx context.Context) error {
+	u.log.Info("ddns started", "interval", u.config.Interval, "records", len(u.config.Records))
+
+	failures := 0
+	for {
+		wait := u.config.Interval
+		err := u.Sync(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			failures++
+			wait = u.backoff(failures)
+			u.log.Warn("sync failed, retrying", "attempt", failures, "retry_in", wait.Round(time.Millisecond))
+		} else {
+			failures = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	u.log.Info("ddns stopped")
+	return nil
+}
+
+func (u *Updater) backoff(failures int) time.Duration {
+	ceiling := u.config.BackoffMin << uint(failures-1)
+	if ceiling > u.config.BackoffMax || ceiling <= 0 {
+		ceiling = u.config.BackoffMax
+	}
+	if ceiling > u.config.Interval {
+		ceiling = u.config.Interval
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// Sync runs one pass: detect each address family and update the records
+// whose content differs. Errors for one family or record do not stop the
+// others; they are logged and returned together.
+func (u *Updater) Sync(ctx context.Context) error {
+	var errs []error
+	for _, family := range []Family{IPv4, IPv6} {
+		detector, ok := u.detectors[family]
+		if !ok {
+			continue
+		}
+
+		ip, err := detector.Detect(ctx)
+		if err != nil {
+			u.log.Error("address detection failed", "family", family.String(), "error", err)
+			errs = append(errs, fmt.Errorf("failed to detect %s address: %w", family, err))
+			continue
+		}
+		addr := ip.String()
+		if u.last[family] == addr {
+			u.log.Debug("address unchanged", "family", family.String(), "address", addr)
+			continue
+		}
+		u.log.Info("address detected", "family", family.String(), "address", addr)
+
+		synced := true
+		for _, rec := range u.config.Records {
+			if err := u.syncRecord(ctx, rec, family, ip); err != nil {
+				u.log.Error("record update failed", "zone", rec.Zone, "name", rec.Name, "type", string(family), "error", err)
+				errs = append(errs, err)
+				synced = false
+			}
+		}
+		if synced {
+			u.last[family] = addr
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (u *Updater) syncRecord(ctx context.Context, rec RecordConfig, family Family, ip net.IP) error {
+	zone, err := u.zone(ctx, rec.Zone)
+	if err != nil {
+		return err
+	}
+	name := dnssync.ExpandName(zone.name, rec.Name)
+	content := ip.String()
+
+	records, _, err := u.client.API.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zone.id), cloudflare.ListDNSRecordsParams{
+		Name: name,
+		Type: string(family),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list DNS records for %s: %w", name, err)
+	}
+
+	switch len(records) {
+	case 0:
+		ttl := rec.TTL
+		if ttl == 0 {
+			ttl = 1
+		}
+		created, err := u.client.API.CreateDNSRecord(ctx, cloudflare.ZoneIdentifier(zone.id), cloudflare.CreateDNSRecordParams{
+			Type:    string(family),
+			Name:    name,
+			Content: content,
+			TTL:     ttl,
+			Proxied: rec.Proxied,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create %s record for %s: %w", string(family), name, err)
+		}
+		u.log.Info("record created", "name", created.Name, "type", created.Type, "content", created.Content)
+		return nil
+	case 1:
+	default:
+		return fmt.Errorf("%d %s records exist for %s; ddns only manages a single record per name", len(records), string(family), name)
+	}
+
+	existing := records[0]
+	if net.ParseIP(existing.Content).Equal(ip) {
+		u.log.Debug("record up to date", "name", name, "type", string(family), "content", existing.Content)
+		return nil
+	}
+
+	params := cloudflare.UpdateDNSRecordParams{
+		ID:      existing.ID,
+		Type:    existing.Type,
+		Name:    existing.Name,
+		Content: content,
+		TTL:     existing.TTL,
+		Proxied: existing.Proxied,
+		Tags:    existing.Tags,
+	}
+	if rec.TTL != 0 {
+		params.TTL = rec.TTL
+	}
+	if rec.Proxied != nil {
+		params.Proxied = rec.Proxied
+	}
+	if _, err := u.client.API.UpdateDNSRecord(ctx, cloudflare.ZoneIdentifier(zone.id), params); err != nil {
+		return fmt.Errorf("failed to update %s record for %s: %w", string(family), name, err)
+	}
+	u.log.Info("record updated", "name", name, "type", string(family), "old", existing.Content, "new", content)
+	return nil
+}
+
+// zone resolves and remembers a configured zone's ID and canonical name.
+func (u *Updater) zone(ctx context.Context, identifier string) (zoneRef, error) {
+	if z, ok := u.zones[identifier]; ok {
+		return z, nil
+	}
+	id, err := u.client.ZoneID(identifier)
+	if err != nil {
+		return zoneRef{}, err
+	}
+	details, err := u.client.API.ZoneDetails(ctx, id)
+	if err != nil {
+		return zoneRef{}, fmt.Errorf("failed to get zone details: %w", err)
+	}
+	z := zoneRef{id: id, name: details.Name}
+	u.zones[identifier] = z
+	return z, nil
+}
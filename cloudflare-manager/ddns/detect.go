@@ -0,0 +1,182 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Family is an IP address family and the record type that holds it.
+type Family string
+
+const (
+	IPv4 Family = "A"
+	IPv6 Family = "AAAA"
+)
+
+func (f Family) String() string {
+	if f == IPv6 {
+		return "ipv6"
+	}
+	return "ipv4"
+}
+
+// matches reports whether ip belongs to the family.
+func (f Family) matches(ip net.IP) bool {
+	if f == IPv4 {
+		return ip.To4() != nil
+	}
+	return ip.To4() == nil && ip.To16() != nil
+}
+
+// Detector finds the current public address of one family.
+type Detector interface {
+	Detect(ctx context.Context) (net.IP, error)
+}
+
+// NewDetector builds the detector selected by cfg.
+func NewDetector(family Family, cfg DetectorConfig) Detector {
+	switch {
+	case cfg.Interface != "":
+		return &InterfaceDetector{Family: family, Interface: cfg.Interface}
+	case cfg.Command != "":
+		return &CommandDetector{Family: family, Command: cfg.Command}
+	}
+
+	urls := cfg.URLs
+	if len(urls) == 0 {
+		urls = DefaultIPv4URLs
+		if family == IPv6 {
+			urls = DefaultIPv6URLs
+		}
+	}
+	return &HTTPDetector{Family: family, URLs: urls}
+}
+
+// HTTPDetector asks echo services that reply with the caller's address as
+// plain text, falling back to the next URL when one fails.
+type HTTPDetector struct {
+	Family Family
+	URLs   []string
+	Client *http.Client
+}
+
+func (d *HTTPDetector) Detect(ctx context.Context) (net.IP, error) {
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	var errs []error
+	for _, url := range d.URLs {
+		ip, err := d.fetch(ctx, client, url)
+		if err == nil {
+			return ip, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (d *HTTPDetector) fetch(ctx context.Context, client *http.Client, url string) (net.IP, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return nil, err
+	}
+	return parseIP(d.Family, string(body))
+}
+
+// InterfaceDetector reads the address assigned to a local interface,
+// preferring public over private addresses.
+type InterfaceDetector struct {
+	Family    Family
+	Interface string
+}
+
+func (d *InterfaceDetector) Detect(ctx context.Context) (net.IP, error) {
+	iface, err := net.InterfaceByName(d.Interface)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	var private net.IP
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || !d.Family.matches(ipnet.IP) || !ipnet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if !ipnet.IP.IsPrivate() {
+			return ipnet.IP, nil
+		}
+		if private == nil {
+			private = ipnet.IP
+		}
+	}
+	if private != nil {
+		return private, nil
+	}
+	return nil, fmt.Errorf("interface %s has no %s address", d.Interface, d.Family)
+}
+
+// CommandDetector runs a shell command and reads the address from the first
+// word of its output.
+type CommandDetector struct {
+	Family  Family
+	Command string
+}
+
+func (d *CommandDetector) Detect(ctx context.Context) (net.IP, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", d.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", d.Command)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%q failed: %w", d.Command, err)
+	}
+	return parseIP(d.Family, string(out))
+}
+
+func parseIP(family Family, text string) (net.IP, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty response, expected an %s address", family)
+	}
+	ip := net.ParseIP(fields[0])
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not an IP address", fields[0])
+	}
+	if !family.matches(ip) {
+		return nil, fmt.Errorf("%s is not an %s address", ip, family)
+	}
+	return ip, nil
+}
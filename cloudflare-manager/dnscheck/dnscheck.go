@@ -0,0 +1,362 @@
+// Package dnscheck queries a zone's delegation and its authoritative name
+// servers over DNS and compares the answers with the records Cloudflare has
+// for the zone.
+package dnscheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/miekg/dns"
+)
+
+// DefaultTimeout bounds each DNS query.
+const DefaultTimeout = 5 * time.Second
+
+// Checker sends the queries. Recursive lookups (the parent zone's servers
+// and name server addresses) go to Resolver; everything else is asked of
+// the authoritative servers directly, on Port.
+type Checker struct {
+	Resolver string
+	Port     string
+	Timeout  time.Duration
+}
+
+// New returns a checker using resolver (host:port) for recursive lookups.
+// An empty resolver means the first server in /etc/resolv.conf.
+func New(resolver string) *Checker {
+	if resolver == "" {
+		resolver = DefaultResolver()
+	}
+	return &Checker{Resolver: resolver, Port: "53", Timeout: DefaultTimeout}
+}
+
+// DefaultResolver is the first name server in /etc/resolv.conf, or 1.1.1.1
+// when there is none.
+func DefaultResolver() string {
+	if conf, err := dns.ClientConfigFromFile("/etc/resolv.conf"); err == nil && len(conf.Servers) > 0 {
+		return net.JoinHostPort(conf.Servers[0], conf.Port)
+	}
+	return "1.1.1.1:53"
+}
+
+// Delegation is the NS set the parent zone hands out for a zone, compared
+// with the name servers Cloudflare assigned.
+type Delegation struct {
+	Parent      string   `json:"parent"`
+	Server      string   `json:"server"`
+	NameServers []string `json:"name_servers"`
+	Expected    []string `json:"expected"`
+	Missing     []string `json:"missing,omitempty"`
+	Extra       []string `json:"extra,omitempty"`
+}
+
+// OK reports whether the delegation is exactly the expected name servers.
+func (d Delegation) OK() bool {
+	return len(d.NameServers) > 0 && len(d.Missing) == 0 && len(d.Extra) == 0
+}
+
+// Delegation asks the servers of the zone's parent (e.g. the .com servers
+// for example.com) which name servers the zone is delegated to.
+func (ch *Checker) Delegation(ctx context.Context, zone string, expected []string) (Delegation, error) {
+	zone = normalize(zone)
+	d := Delegation{Expected: normalizeAll(expected)}
+
+	parent, parentServers, err := ch.parentServers(ctx, zone)
+	if err != nil {
+		return d, err
+	}
+	d.Parent = parent
+
+	var lastErr error
+	for _, server := range parentServers {
+		addrs, err := ch.addresses(ctx, server)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, addr := range addrs {
+			resp, err := ch.exchange(ctx, zone, dns.TypeNS, net.JoinHostPort(addr, ch.Port), false)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			for _, rr := range append(resp.Answer, resp.Ns...) {
+				if ns, ok := rr.(*dns.NS); ok && normalize(ns.Hdr.Name) == zone {
+					d.NameServers = append(d.NameServers, normalize(ns.Ns))
+				}
+			}
+			if len(d.NameServers) == 0 {
+				lastErr = fmt.Errorf("%s has no delegation for %s (%s)", server, zone, dns.RcodeToString[resp.Rcode])
+				continue
+			}
+			d.Server = server
+			sort.Strings(d.NameServers)
+			d.Missing = difference(d.Expected, d.NameServers)
+			d.Extra = difference(d.NameServers, d.Expected)
+			return d, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no servers found for %s", parent)
+	}
+	return d, lastErr
+}
+
+// parentServers finds the closest enclosing zone with NS records.
+func (ch *Checker) parentServers(ctx context.Context, zone string) (string, []string, error) {
+	labels := strings.Split(zone, ".")
+	for i := 1; i < len(labels); i++ {
+		parent := strings.Join(labels[i:], ".")
+		resp, err := ch.exchange(ctx, parent, dns.TypeNS, ch.Resolver, true)
+		if err != nil {
+			return parent, nil, fmt.Errorf("failed to look up the %s name servers: %w", parent, err)
+		}
+		var servers []string
+		for _, rr := range resp.Answer {
+			if ns, ok := rr.(*dns.NS); ok {
+				servers = append(servers, normalize(ns.Ns))
+			}
+		}
+		if len(servers) > 0 {
+			sort.Strings(servers)
+			return parent, servers, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no parent zone with name servers found for %s", zone)
+}
+
+// addresses resolves a host name through the resolver.
+func (ch *Checker) addresses(ctx context.Context, host string) ([]string, error) {
+	var addrs []string
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		resp, err := ch.exchange(ctx, host, qtype, ch.Resolver, true)
+		if err != nil {
+			if qtype == dns.TypeA {
+				return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+			}
+			continue
+		}
+		for _, rr := range resp.Answer {
+			switch rr := rr.(type) {
+			case *dns.A:
+				addrs = append(addrs, rr.A.String())
+			case *dns.AAAA:
+				addrs = append(addrs, rr.AAAA.String())
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("%s has no addresses", host)
+	}
+	return addrs, nil
+}
+
+func (ch *Checker) exchange(ctx context.Context, name string, qtype uint16, server string, recursive bool) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	m.RecursionDesired = recursive
+
+	c := &dns.Client{Timeout: ch.Timeout}
+	resp, _, err := c.ExchangeContext(ctx, m, server)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Truncated {
+		c.Net = "tcp"
+		if resp, _, err = c.ExchangeContext(ctx, m, server); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// Record is the set of values Cloudflare has for one name and type, in the
+// form they are compared in.
+type Record struct {
+	Type   string   `json:"type"`
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// compared lists the types whose served values can be compared with the
+// API's content.
+var compared = map[string]uint16{
+	"A":     dns.TypeA,
+	"AAAA":  dns.TypeAAAA,
+	"CNAME": dns.TypeCNAME,
+	"MX":    dns.TypeMX,
+	"NS":    dns.TypeNS,
+	"TXT":   dns.TypeTXT,
+}
+
+// Expected groups the records that can be checked by name and type.
+// Proxied records are skipped, since Cloudflare answers for them with its
+// own addresses, as are types that are not compared.
+func Expected(records []cloudflare.DNSRecord) (expected []Record, skipped int) {
+	index := map[string]int{}
+	for _, rec := range records {
+		if _, ok := compared[rec.Type]; !ok || (rec.Proxied != nil && *rec.Proxied) {
+			skipped++
+			continue
+		}
+
+		value := rec.Content
+		switch rec.Type {
+		case "CNAME", "NS":
+			value = normalize(value)
+		case "MX":
+			priority := uint16(0)
+			if rec.Priority != nil {
+				priority = *rec.Priority
+			}
+			value = fmt.Sprintf("%d %s", priority, normalize(value))
+		case "TXT":
+			value = txtValue(value)
+		case "AAAA":
+			if ip := net.ParseIP(value); ip != nil {
+				value = ip.String()
+			}
+		}
+
+		key := rec.Type + " " + normalize(rec.Name)
+		i, ok := index[key]
+		if !ok {
+			i = len(expected)
+			index[key] = i
+			expected = append(expected, Record{Type: rec.Type, Name: normalize(rec.Name)})
+		}
+		expected[i].Values = append(expected[i].Values, value)
+	}
+	for i := range expected {
+		sort.Strings(expected[i].Values)
+	}
+	return expected, skipped
+}
+
+// Mismatch is a name and type a server answers differently for.
+type Mismatch struct {
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	Expected []string `json:"expected"`
+	Served   []string `json:"served"`
+}
+
+// ServerResult is what one authoritative server answered.
+type ServerResult struct {
+	Server     string     `json:"server"`
+	Address    string     `json:"address,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Checked    int        `json:"checked"`
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+}
+
+// OK reports whether the server answered every query as expected.
+func (r ServerResult) OK() bool {
+	return r.Error == "" && len(r.Mismatches) == 0
+}
+
+// CheckServer asks server for every expected record.
+func (ch *Checker) CheckServer(ctx context.Context, server string, expected []Record) ServerResult {
+	res := ServerResult{Server: normalize(server)}
+	addrs, err := ch.addresses(ctx, server)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Address = addrs[0]
+	addr := net.JoinHostPort(res.Address, ch.Port)
+
+	for _, want := range expected {
+		resp, err := ch.exchange(ctx, want.Name, compared[want.Type], addr, false)
+		if err != nil {
+			res.Error = fmt.Sprintf("%s %s: %v", want.Type, want.Name, err)
+			return res
+		}
+		res.Checked++
+
+		// A delegated name is answered with a referral: its NS records come
+		// in the authority section and the answer is empty.
+		answer := resp.Answer
+		if want.Type == "NS" && len(answer) == 0 {
+			answer = resp.Ns
+		}
+		var served []string
+		for _, rr := range answer {
+			if normalize(rr.Header().Name) != want.Name {
+				continue
+			}
+			switch rr := rr.(type) {
+			case *dns.A:
+				if want.Type == "A" {
+					served = append(served, rr.A.String())
+				}
+			case *dns.AAAA:
+				if want.Type == "AAAA" {
+					served = append(served, rr.AAAA.String())
+				}
+			case *dns.CNAME:
+				if want.Type == "CNAME" {
+					served = append(served, normalize(rr.Target))
+				}
+			case *dns.MX:
+				served = append(served, fmt.Sprintf("%d %s", rr.Preference, normalize(rr.Mx)))
+			case *dns.NS:
+				served = append(served, normalize(rr.Ns))
+			case *dns.TXT:
+				served = append(served, strings.Join(rr.Txt, ""))
+			}
+		}
+		sort.Strings(served)
+		if strings.Join(served, "\x00") != strings.Join(want.Values, "\x00") {
+			res.Mismatches = append(res.Mismatches, Mismatch{Type: want.Type, Name: want.Name, Expected: want.Values, Served: served})
+		}
+	}
+	return res
+}
+
+// txtValue unquotes TXT content the way it appears on the wire: the API
+// may return it as one or more quoted strings.
+func txtValue(content string) string {
+	if !strings.HasPrefix(content, `"`) || !strings.HasSuffix(content, `"`) {
+		return content
+	}
+	rr, err := dns.NewRR(". IN TXT " + content)
+	if err != nil {
+		return content
+	}
+	return strings.Join(rr.(*dns.TXT).Txt, "")
+}
+
+func normalize(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+func normalizeAll(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		out = append(out, normalize(n))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// difference returns the names in a that are not in b.
+func difference(a, b []string) []string {
+	in := map[string]bool{}
+	for _, s := range b {
+		in[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,418 @@
+package dnssync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare-manager/zonefile"
+	"github.com/cloudflare/cloudflare-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Record is a desired DNS record as written in a state file. Fields left
+// unset (nil or zero) are not compared against the live record.
+type Record struct {
+	Type     string                 `yaml:"type" json:"type"`
+	Name     string                 `yaml:"name" json:"name"`
+	Content  string                 `yaml:"content,omitempty" json:"content,omitempty"`
+	TTL      int                    `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Proxied  *bool                  `yaml:"proxied,omitempty" json:"proxied,omitempty"`
+	Priority *uint16                `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Comment  *string                `yaml:"comment,omitempty" json:"comment,omitempty"`
+	Tags     []string               `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Data     map[string]interface{} `yaml:"data,omitempty" json:"data,omitempty"`
+}
+
+// File is the desired state of one zone.
+type File struct {
+	Zone    string   `yaml:"zone,omitempty" json:"zone,omitempty"`
+	Records []Record `yaml:"records" json:"records"`
+}
+
+// Load reads a YAML or JSON state file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for i, r := range f.Records {
+		if r.Type == "" || r.Name == "" {
+			return nil, fmt.Errorf("%s: record %d needs both type and name", path, i+1)
+		}
+		if r.Content == "" && len(r.Data) == 0 {
+			return nil, fmt.Errorf("%s: record %d (%s %s) needs content or data", path, i+1, r.Type, r.Name)
+		}
+	}
+	return &f, nil
+}
+
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Change is a single step needed to reconcile a zone.
+type Change struct {
+	Action  Action
+	Desired Record
+	Current cloudflare.DNSRecord
+	Diffs   []string
+}
+
+// Plan is the set of changes needed to bring a zone to its desired state.
+type Plan struct {
+	Zone      string
+	Changes   []Change
+	Unchanged int
+	Unmanaged int
+}
+
+// Empty reports whether the plan has nothing to do.
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes of the given action.
+func (p Plan) Count(a Action) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// Diff compares the desired records with the live ones, matching on
+// type, name and content. Live records that are not in the desired set are
+// deleted only when prune is true.
+func Diff(zone string, desired []Record, current []cloudflare.DNSRecord, prune bool) Plan {
+	plan := Plan{Zone: zone}
+	matched := make([]bool, len(current))
+
+	for _, d := range desired {
+		d = Normalize(zone, d)
+		found := -1
+		for i, cur := range current {
+			if matched[i] {
+				continue
+			}
+			if Key(d.Type, d.Name, Content(d)) == Key(cur.Type, cur.Name, cur.Content) {
+				found = i
+				break
+			}
+		}
+
+		if found < 0 {
+			plan.Changes = append(plan.Changes, Change{Action: Create, Desired: d})
+			continue
+		}
+
+		matched[found] = true
+		if diffs := compare(d, current[found]); len(diffs) > 0 {
+			plan.Changes = append(plan.Changes, Change{Action: Update, Desired: d, Current: current[found], Diffs: diffs})
+		} else {
+			plan.Unchanged++
+		}
+	}
+
+	for i, cur := range current {
+		if matched[i] {
+			continue
+		}
+		if prune {
+			plan.Changes = append(plan.Changes, Change{Action: Delete, Current: cur})
+		} else {
+			plan.Unmanaged++
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return order(plan.Changes[i].Action) < order(plan.Changes[j].Action)
+	})
+	return plan
+}
+
+func order(a Action) int {
+	switch a {
+	case Delete:
+		return 0
+	case Update:
+		return 1
+	}
+	return 2
+}
+
+// Normalize upper-cases the type and expands "@" and relative names
+// against zone.
+func Normalize(zone string, r Record) Record {
+	r.Type = strings.ToUpper(r.Type)
+	r.Name = ExpandName(zone, r.Name)
+	return r
+}
+
+// ExpandName turns "@" and relative names into fully qualified names.
+func ExpandName(zone, name string) string {
+	zone = strings.TrimSuffix(strings.ToLower(zone), ".")
+	name = strings.ToLower(name)
+	if name == "@" || name == "" {
+		return zone
+	}
+	if strings.HasSuffix(name, ".") {
+		return strings.TrimSuffix(name, ".")
+	}
+	if name == zone || strings.HasSuffix(name, "."+zone) {
+		return name
+	}
+	return name + "." + zone
+}
+
+// Content returns the record content, deriving it from Data for structured
+// types the same way the API renders it.
+func Content(r Record) string {
+	if r.Content != "" || len(r.Data) == 0 {
+		return r.Content
+	}
+	return zonefile.FormatData(r.Type, r.Data)
+}
+
+// Key is the identity used to match records.
+func Key(rrtype, name, content string) string {
+	rrtype = strings.ToUpper(rrtype)
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	switch rrtype {
+	case "CNAME", "MX", "NS", "PTR":
+		content = strings.TrimSuffix(strings.ToLower(content), ".")
+	case "AAAA":
+		content = strings.ToLower(content)
+	}
+	return rrtype + "|" + name + "|" + content
+}
+
+func compare(d Record, cur cloudflare.DNSRecord) []string {
+	var diffs []string
+	if d.TTL != 0 && d.TTL != cur.TTL {
+		diffs = append(diffs, fmt.Sprintf("ttl: %d -> %d", cur.TTL, d.TTL))
+	}
+	if d.Proxied != nil {
+		curProxied := cur.Proxied != nil && *cur.Proxied
+		if *d.Proxied != curProxied {
+			diffs = append(diffs, fmt.Sprintf("proxied: %t -> %t", curProxied, *d.Proxied))
+		}
+	}
+	if d.Priority != nil && (cur.Priority == nil || *cur.Priority != *d.Priority) {
+		old := "none"
+		if cur.Priority != nil {
+			old = fmt.Sprintf("%d", *cur.Priority)
+		}
+		diffs = append(diffs, fmt.Sprintf("priority: %s -> %d", old, *d.Priority))
+	}
+	if d.Comment != nil && *d.Comment != cur.Comment {
+		diffs = append(diffs, fmt.Sprintf("comment: %q -> %q", cur.Comment, *d.Comment))
+	}
+	if d.Tags != nil && !sameTags(d.Tags, cur.Tags) {
+		diffs = append(diffs, fmt.Sprintf("tags: %v -> %v", cur.Tags, d.Tags))
+	}
+	return diffs
+}
+
+func sameTags(a, b []string) bool {
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Print writes the plan in a terraform-like format.
+func (p Plan) Print(w io.Writer) {
+	p.PrintChanges(w)
+
+	if len(p.Changes) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Plan: %d to add, %d to change, %d to destroy.\n", p.Count(Create), p.Count(Update), p.Count(Delete))
+	if p.Unmanaged > 0 {
+		fmt.Fprintf(w, "%d live records are not in the file and will be kept (use --prune to delete them).\n", p.Unmanaged)
+	}
+}
+
+// PrintChanges writes one line per change, followed by the changed fields
+// of updates.
+func (p Plan) PrintChanges(w io.Writer) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case Create:
+			fmt.Fprintf(w, "  + %-6s %s  %s%s\n", c.Desired.Type, c.Desired.Name, Content(c.Desired), attrs(c.Desired))
+		case Update:
+			fmt.Fprintf(w, "  ~ %-6s %s  %s\n", c.Current.Type, c.Current.Name, c.Current.Content)
+			for _, d := range c.Diffs {
+				fmt.Fprintf(w, "        %s\n", d)
+			}
+		case Delete:
+			fmt.Fprintf(w, "  - %-6s %s  %s\n", c.Current.Type, c.Current.Name, c.Current.Content)
+		}
+	}
+}
+
+func attrs(r Record) string {
+	var parts []string
+	if r.TTL != 0 {
+		parts = append(parts, fmt.Sprintf("ttl=%d", r.TTL))
+	}
+	if r.Proxied != nil {
+		parts = append(parts, fmt.Sprintf("proxied=%t", *r.Proxied))
+	}
+	if r.Priority != nil {
+		parts = append(parts, fmt.Sprintf("priority=%d", *r.Priority))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "  (" + strings.Join(parts, " ") + ")"
+}
+
+// Result summarises an Apply run.
+type Result struct {
+	Created int
+	Updated int
+	Deleted int
+	Failed  int
+	Errors  []error
+}
+
+// Apply executes the plan against the zone.
+func Apply(ctx context.Context, api *cloudflare.API, zoneID string, plan Plan) Result {
+	var res Result
+	rc := cloudflare.ZoneIdentifier(zoneID)
+
+	fail := func(c Change, err error) {
+		res.Failed++
+		name := c.Desired.Name
+		if c.Action == Delete {
+			name = c.Current.Name
+		}
+		res.Errors = append(res.Errors, fmt.Errorf("%s %s: %w", c.Action, name, err))
+	}
+
+	for _, c := range plan.Changes {
+		if ctx.Err() != nil {
+			res.Errors = append(res.Errors, fmt.Errorf("stopped: %w", ctx.Err()))
+			break
+		}
+
+		switch c.Action {
+		case Create:
+			if _, err := api.CreateDNSRecord(ctx, rc, CreateParams(c.Desired)); err != nil {
+				fail(c, err)
+				continue
+			}
+			res.Created++
+		case Update:
+			params := cloudflare.UpdateDNSRecordParams{
+				ID:       c.Current.ID,
+				Type:     c.Current.Type,
+				Name:     c.Current.Name,
+				Content:  c.Current.Content,
+				Data:     c.Current.Data,
+				TTL:      c.Current.TTL,
+				Proxied:  c.Current.Proxied,
+				Priority: c.Current.Priority,
+				Tags:     c.Current.Tags,
+			}
+			if c.Desired.TTL != 0 {
+				params.TTL = c.Desired.TTL
+			}
+			if c.Desired.Proxied != nil {
+				params.Proxied = c.Desired.Proxied
+			}
+			if c.Desired.Priority != nil {
+				params.Priority = c.Desired.Priority
+			}
+			if c.Desired.Comment != nil {
+				params.Comment = c.Desired.Comment
+			}
+			if c.Desired.Tags != nil {
+				params.Tags = c.Desired.Tags
+			}
+			if len(c.Desired.Data) > 0 {
+				params.Data = c.Desired.Data
+				params.Content = ""
+			}
+			if _, err := api.UpdateDNSRecord(ctx, rc, params); err != nil {
+				fail(c, err)
+				continue
+			}
+			res.Updated++
+		case Delete:
+			if err := api.DeleteDNSRecord(ctx, rc, c.Current.ID); err != nil {
+				fail(c, err)
+				continue
+			}
+			res.Deleted++
+		}
+	}
+	return res
+}
+
+// CreateParams converts a desired record into a create request.
+func CreateParams(r Record) cloudflare.CreateDNSRecordParams {
+	params := cloudflare.CreateDNSRecordParams{
+		Type:     r.Type,
+		Name:     r.Name,
+		Content:  r.Content,
+		TTL:      r.TTL,
+		Proxied:  r.Proxied,
+		Priority: r.Priority,
+		Tags:     r.Tags,
+	}
+	if params.TTL == 0 {
+		params.TTL = 1
+	}
+	if r.Comment != nil {
+		params.Comment = *r.Comment
+	}
+	if len(r.Data) > 0 {
+		params.Data = r.Data
+		params.Content = ""
+	}
+	return params
+}
+
+// FromDNSRecord converts a live record into a desired record with every
+// field set, so that reconciling against it also resets TTL, proxy status,
+// comments and tags.
+func FromDNSRecord(rec cloudflare.DNSRecord) Record {
+	proxied := rec.Proxied != nil && *rec.Proxied
+	comment := rec.Comment
+	r := Record{
+		Type:     rec.Type,
+		Name:     rec.Name,
+		Content:  rec.Content,
+		TTL:      rec.TTL,
+		Proxied:  &proxied,
+		Priority: rec.Priority,
+		Comment:  &comment,
+		Tags:     append([]string{}, rec.Tags...),
+	}
+	if data, ok := rec.Data.(map[string]interface{}); ok && zonefile.Structured(rec.Type) {
+		r.Data = data
+	}
+	return r
+}
@@ -0,0 +1,242 @@
+package fakecf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare-manager/zonefile"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// AddDNSRecord stores a record as if it had been created through the API.
+// Relative names are expanded against the zone name.
+func (s *Server) AddDNSRecord(zoneID string, rec cloudflare.DNSRecord) cloudflare.DNSRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	z := s.zone(zoneID)
+	if z == nil {
+		panic("fakecf: unknown zone " + zoneID)
+	}
+	return s.addRecord(z, rec)
+}
+
+// DNSRecords returns the records of a zone in creation order.
+func (s *Server) DNSRecords(zoneID string) []cloudflare.DNSRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		return append([]cloudflare.DNSRecord(nil), z.records...)
+	}
+	return nil
+}
+
+func (s *Server) addRecord(z *zone, rec cloudflare.DNSRecord) cloudflare.DNSRecord {
+	now := time.Now().UTC()
+	rec.ID = s.newID()
+	rec.ZoneID = z.ID
+	rec.ZoneName = z.Name
+	rec.Name = expandName(rec.Name, z.Name)
+	rec.CreatedOn = now
+	rec.ModifiedOn = now
+	if rec.TTL == 0 {
+		rec.TTL = 1
+	}
+	rec.Proxiable = proxiable(rec.Type)
+	setStructuredContent(&rec)
+	if rec.Proxied == nil {
+		proxied := false
+		rec.Proxied = &proxied
+	}
+	z.records = append(z.records, rec)
+	return rec
+}
+
+func expandName(name, zoneName string) string {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	switch {
+	case name == "" || name == "@":
+		return zoneName
+	case name == zoneName || strings.HasSuffix(name, "."+zoneName):
+		return name
+	default:
+		return name + "." + zoneName
+	}
+}
+
+// setStructuredContent renders the content of records sent as a data
+// object, as the real API does.
+func setStructuredContent(rec *cloudflare.DNSRecord) {
+	if data, ok := rec.Data.(map[string]interface{}); ok && zonefile.Structured(rec.Type) {
+		rec.Content = zonefile.FormatData(rec.Type, data)
+	}
+}
+
+func proxiable(recordType string) bool {
+	switch recordType {
+	case "A", "AAAA", "CNAME":
+		return true
+	}
+	return false
+}
+
+func (s *Server) registerDNSRoutes() {
+	s.handle(http.MethodGet, "/zones/:zone/dns_records", s.withZone(s.listDNSRecords))
+	s.handle(http.MethodPost, "/zones/:zone/dns_records", s.withZone(s.createDNSRecord))
+	s.handle(http.MethodGet, "/zones/:zone/dns_records/export", s.withZone(s.exportDNSRecords))
+	s.handle(http.MethodGet, "/zones/:zone/dns_records/:id", s.withZone(s.withRecord(s.getDNSRecord)))
+	s.handle(http.MethodPatch, "/zones/:zone/dns_records/:id", s.withZone(s.withRecord(s.updateDNSRecord)))
+	s.handle(http.MethodPut, "/zones/:zone/dns_records/:id", s.withZone(s.withRecord(s.updateDNSRecord)))
+	s.handle(http.MethodDelete, "/zones/:zone/dns_records/:id", s.withZone(s.withRecord(s.deleteDNSRecord)))
+}
+
+// recordHandler serves a route under /zones/:zone/dns_records/:id; i indexes
+// z.records.
+type recordHandler func(w http.ResponseWriter, r *http.Request, z *zone, i int)
+
+func (s *Server) withRecord(h recordHandler) zoneHandler {
+	return func(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+		for i, rec := range z.records {
+			if rec.ID == params["id"] {
+				h(w, r, z, i)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, 81044, "Record does not exist.")
+	}
+}
+
+func (s *Server) listDNSRecords(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	q := r.URL.Query()
+	var records []cloudflare.DNSRecord
+	for _, rec := range z.records {
+		if t := q.Get("type"); t != "" && rec.Type != t {
+			continue
+		}
+		if name := q.Get("name"); name != "" && rec.Name != strings.ToLower(name) {
+			continue
+		}
+		if content := q.Get("content"); content != "" && rec.Content != content {
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	page, info := paginate(records, r, 100)
+	writeResult(w, page, &info)
+}
+
+func (s *Server) createDNSRecord(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	var rec cloudflare.DNSRecord
+	if !decodeBody(w, r, &rec) {
+		return
+	}
+	setStructuredContent(&rec)
+	if !s.validRecord(w, z, rec, "") {
+		return
+	}
+	writeResult(w, s.addRecord(z, rec), nil)
+}
+
+func (s *Server) getDNSRecord(w http.ResponseWriter, r *http.Request, z *zone, i int) {
+	writeResult(w, z.records[i], nil)
+}
+
+// updateDNSRecord merges the fields present in the body into the record, so
+// it serves both PATCH and the full replacement done by PUT.
+func (s *Server) updateDNSRecord(w http.ResponseWriter, r *http.Request, z *zone, i int) {
+	var patch map[string]json.RawMessage
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	current, _ := json.Marshal(z.records[i])
+	var merged map[string]json.RawMessage
+	json.Unmarshal(current, &merged)
+	for k, v := range patch {
+		merged[k] = v
+	}
+	data, _ := json.Marshal(merged)
+
+	var rec cloudflare.DNSRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		writeError(w, http.StatusBadRequest, 9207, "Request body is invalid: "+err.Error())
+		return
+	}
+	rec.Name = expandName(rec.Name, z.Name)
+	if _, ok := patch["data"]; ok {
+		setStructuredContent(&rec)
+	}
+	if !s.validRecord(w, z, rec, rec.ID) {
+		return
+	}
+	rec.ModifiedOn = time.Now().UTC()
+	rec.Proxiable = proxiable(rec.Type)
+	z.records[i] = rec
+	writeResult(w, rec, nil)
+}
+
+func (s *Server) deleteDNSRecord(w http.ResponseWriter, r *http.Request, z *zone, i int) {
+	id := z.records[i].ID
+	z.records = append(z.records[:i], z.records[i+1:]...)
+	writeResult(w, map[string]string{"id": id}, nil)
+}
+
+// validRecord applies the checks the real API is strictest about: required
+// fields, identical duplicates and CNAMEs sharing a name with other records.
+func (s *Server) validRecord(w http.ResponseWriter, z *zone, rec cloudflare.DNSRecord, id string) bool {
+	if rec.Type == "" || rec.Name == "" {
+		writeError(w, http.StatusBadRequest, 9000, "DNS record type and name are required")
+		return false
+	}
+	if rec.Content == "" && rec.Data == nil {
+		writeError(w, http.StatusBadRequest, 9005, "Content for "+rec.Type+" record is invalid")
+		return false
+	}
+	if rec.Proxied != nil && *rec.Proxied && !proxiable(rec.Type) {
+		writeError(w, http.StatusBadRequest, 9004, "This record type cannot be proxied.")
+		return false
+	}
+
+	name := expandName(rec.Name, z.Name)
+	for _, other := range z.records {
+		if other.ID == id || other.Name != name {
+			continue
+		}
+		if other.Type == rec.Type && other.Content == rec.Content {
+			writeError(w, http.StatusBadRequest, 81058, "An identical record already exists.")
+			return false
+		}
+		if other.Type == "CNAME" || rec.Type == "CNAME" {
+			writeError(w, http.StatusBadRequest, 81053, "An A, AAAA, or CNAME record with that host already exists.")
+			return false
+		}
+	}
+	return true
+}
+
+// exportDNSRecords writes the zone as a BIND file, like the real endpoint.
+func (s *Server) exportDNSRecords(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, ";; Domain:     %s.\n", z.Name)
+	fmt.Fprintf(&b, ";; Exported:   %s\n\n", time.Now().UTC().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "$ORIGIN %s.\n", z.Name)
+	for _, rec := range z.records {
+		content := rec.Content
+		switch rec.Type {
+		case "CNAME", "NS", "PTR", "MX":
+			content += "."
+		case "TXT":
+			content = fmt.Sprintf("%q", content)
+		}
+		if rec.Type == "MX" && rec.Priority != nil {
+			content = fmt.Sprintf("%d %s", *rec.Priority, content)
+		}
+		fmt.Fprintf(&b, "%s.\t%d\tIN\t%s\t%s\n", rec.Name, rec.TTL, rec.Type, content)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,115 @@
+package fakecf
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+type dnssecState struct {
+	status      string
+	multiSigner bool
+	presigned   bool
+	modified    time.Time
+	// pendingReads is how many more reads a pending zone stays pending for;
+	// negative means it never activates.
+	pendingReads int
+}
+
+// dnssecResult is the /dnssec response body.
+type dnssecResult struct {
+	cloudflare.ZoneDNSSEC
+	MultiSigner bool `json:"dnssec_multi_signer"`
+	Presigned   bool `json:"dnssec_presigned"`
+}
+
+// ActivateDNSSECAfter makes a zone whose DNSSEC is pending become active
+// after the given number of status reads, as if the DS record had been
+// found at the registrar. By default pending zones stay pending.
+func (s *Server) ActivateDNSSECAfter(zoneID string, reads int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		z.dnssec.pendingReads = reads
+	}
+}
+
+func (s *Server) registerDNSSECRoutes() {
+	s.handle(http.MethodGet, "/zones/:zone/dnssec", s.withZone(s.getDNSSEC))
+	s.handle(http.MethodPatch, "/zones/:zone/dnssec", s.withZone(s.updateDNSSEC))
+}
+
+func (s *Server) getDNSSEC(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	if z.dnssec.status == "pending" && z.dnssec.pendingReads >= 0 {
+		if z.dnssec.pendingReads == 0 {
+			z.dnssec.status = "active"
+			z.dnssec.modified = time.Now().UTC()
+		}
+		z.dnssec.pendingReads--
+	}
+	writeResult(w, dnssecResponse(z), nil)
+}
+
+func (s *Server) updateDNSSEC(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	var body struct {
+		Status      string `json:"status"`
+		MultiSigner *bool  `json:"dnssec_multi_signer"`
+		Presigned   *bool  `json:"dnssec_presigned"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	switch body.Status {
+	case "":
+	case "active":
+		if z.dnssec.status == "" || z.dnssec.status == "disabled" {
+			z.dnssec.status = "pending"
+		}
+	case "disabled":
+		z.dnssec.status = "disabled"
+	default:
+		writeError(w, http.StatusBadRequest, 1007, "Invalid DNSSEC status "+body.Status)
+		return
+	}
+	if body.MultiSigner != nil {
+		z.dnssec.multiSigner = *body.MultiSigner
+	}
+	if body.Presigned != nil {
+		z.dnssec.presigned = *body.Presigned
+	}
+	z.dnssec.modified = time.Now().UTC()
+	writeResult(w, dnssecResponse(z), nil)
+}
+
+// dnssecResponse derives a stable key and DS record from the zone ID.
+func dnssecResponse(z *zone) dnssecResult {
+	res := dnssecResult{MultiSigner: z.dnssec.multiSigner, Presigned: z.dnssec.presigned}
+	res.Status = z.dnssec.status
+	if res.Status == "" {
+		res.Status = "disabled"
+	}
+	res.ModifiedOn = z.dnssec.modified
+	if res.Status == "disabled" {
+		return res
+	}
+
+	sum := sha256.Sum256([]byte(z.ID))
+	digest := strings.ToUpper(fmt.Sprintf("%x", sum))
+	res.Flags = 257
+	res.Algorithm = "13"
+	res.KeyType = "ECDSAP256SHA256"
+	res.DigestType = "2"
+	res.DigestAlgorithm = "SHA256"
+	res.Digest = digest
+	res.KeyTag = 2371
+	res.DS = fmt.Sprintf("%s. 3600 IN DS %d %s %s %s", z.Name, res.KeyTag, res.Algorithm, res.DigestType, digest)
+	key := sha256.Sum256(sum[:])
+	res.PublicKey = base64.StdEncoding.EncodeToString(append(sum[:], key[:]...))
+	return res
+}
@@ -0,0 +1,354 @@
+// Package fakecf is an in-memory stand-in for the Cloudflare v4 API. It
+// serves the zone, zone settings, rulesets, DNS, DNSSEC, Workers, Pages, KV
+// and R2 endpoints cfm uses, so commands can be exercised end to end
+// without network access.
+//
+// A test starts a server, seeds it and points an account's base_url at
+// URL():
+//
+//	api := fakecf.New(t)
+//	acct := api.AddAccount("Example")
+//	zone := api.AddZone(acct.ID, "example.com")
+package fakecf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Token is the API token the server accepts unless Server.Token is changed.
+const Token = "fakecf-token"
+
+// Server is a fake Cloudflare API backed by in-memory state. All methods are
+// safe for concurrent use.
+type Server struct {
+	// Token is the accepted bearer token. Requests authenticated with a
+	// Global API Key (X-Auth-Key and X-Auth-Email) are always accepted.
+	Token string
+
+	srv    *httptest.Server
+	routes []route
+
+	mu       sync.Mutex
+	nextID   int
+	requests []string
+
+	accounts    []cloudflare.Account
+	zones       []*zone
+	workers     map[string][]*worker
+	projects    map[string][]*project
+	namespaces  map[string][]cloudflare.WorkersKVNamespace
+	kvValues    map[string]map[string][]byte
+	buckets     map[string][]cloudflare.R2Bucket
+	tokenStatus string
+}
+
+// New starts a server that is closed when the test finishes.
+func New(tb testing.TB) *Server {
+	s := &Server{
+		Token:       Token,
+		workers:     map[string][]*worker{},
+		projects:    map[string][]*project{},
+		namespaces:  map[string][]cloudflare.WorkersKVNamespace{},
+		kvValues:    map[string]map[string][]byte{},
+		buckets:     map[string][]cloudflare.R2Bucket{},
+		tokenStatus: "active",
+	}
+	s.registerRoutes()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	tb.Cleanup(s.srv.Close)
+	return s
+}
+
+// URL is the API base URL, the equivalent of
+// https://api.cloudflare.com/client/v4.
+func (s *Server) URL() string {
+	return s.srv.URL + "/client/v4"
+}
+
+// Requests returns every request served so far as "METHOD /path".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// SetTokenStatus changes what /user/tokens/verify reports, e.g. "disabled".
+func (s *Server) SetTokenStatus(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenStatus = status
+}
+
+// AddAccount creates an account the token can access.
+func (s *Server) AddAccount(name string) cloudflare.Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct := cloudflare.Account{
+		ID:        s.newID(),
+		Name:      name,
+		Type:      "standard",
+		CreatedOn: time.Now().UTC(),
+		Settings:  &cloudflare.AccountSettings{},
+	}
+	s.accounts = append(s.accounts, acct)
+	return acct
+}
+
+// newID returns a unique 32 character hex identifier. Callers hold s.mu.
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("%032x", s.nextID)
+}
+
+func (s *Server) account(id string) (cloudflare.Account, bool) {
+	for _, acct := range s.accounts {
+		if acct.ID == id {
+			return acct, true
+		}
+	}
+	return cloudflare.Account{}, false
+}
+
+// handler serves one route. params holds the path segments named with a
+// leading colon in the route pattern.
+type handler func(w http.ResponseWriter, r *http.Request, params map[string]string)
+
+type route struct {
+	method string
+	parts  []string
+	handle handler
+	// account routes 404 unless :account names a known account.
+	account bool
+}
+
+func (s *Server) handle(method, pattern string, h handler) {
+	parts := strings.Split(strings.Trim(pattern, "/"), "/")
+	s.routes = append(s.routes, route{
+		method:  method,
+		parts:   parts,
+		handle:  h,
+		account: len(parts) > 1 && parts[0] == "accounts" && parts[1] == ":account",
+	})
+}
+
+func (s *Server) registerRoutes() {
+	s.handle(http.MethodGet, "/user", s.getUser)
+	s.handle(http.MethodGet, "/user/tokens/verify", s.verifyToken)
+	s.handle(http.MethodGet, "/user/tokens/:id", s.getToken)
+	s.handle(http.MethodGet, "/accounts", s.listAccounts)
+	s.handle(http.MethodGet, "/accounts/:account", s.getAccount)
+
+	s.registerZoneRoutes()
+	s.registerDNSSECRoutes()
+	s.registerSettingsRoutes()
+	s.registerRulesetRoutes()
+	s.registerDNSRoutes()
+	s.registerWorkerRoutes()
+	s.registerPagesRoutes()
+	s.registerKVRoutes()
+	s.registerR2Routes()
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/client/v4")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+path)
+	s.mu.Unlock()
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusForbidden, 10000, "Authentication error")
+		return
+	}
+
+	var segments []string
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		seg, err := url.PathUnescape(part)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 7003, "Could not route to "+path)
+			return
+		}
+		segments = append(segments, seg)
+	}
+
+	methodMismatch := false
+	for _, rt := range s.routes {
+		params, ok := match(rt.parts, segments)
+		if !ok {
+			continue
+		}
+		if rt.method != r.Method {
+			methodMismatch = true
+			continue
+		}
+		if rt.account {
+			s.mu.Lock()
+			_, known := s.account(params["account"])
+			s.mu.Unlock()
+			if !known {
+				writeError(w, http.StatusNotFound, 7003, "Could not route to "+path+", perhaps your object identifier is invalid?")
+				return
+			}
+		}
+		rt.handle(w, r, params)
+		return
+	}
+
+	if methodMismatch {
+		writeError(w, http.StatusMethodNotAllowed, 10405, "Method not allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, 7003, "Could not route to "+path)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if r.Header.Get("X-Auth-Key") != "" && r.Header.Get("X-Auth-Email") != "" {
+		return true
+	}
+	return r.Header.Get("Authorization") == "Bearer "+s.Token
+}
+
+func match(pattern, segments []string) (map[string]string, bool) {
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, part := range pattern {
+		if strings.HasPrefix(part, ":") {
+			params[part[1:]] = segments[i]
+		} else if part != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeResult(w, cloudflare.User{
+		ID:    "user",
+		Email: r.Header.Get("X-Auth-Email"),
+	}, nil)
+}
+
+func (s *Server) verifyToken(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeResult(w, cloudflare.APITokenVerifyBody{
+		ID:     "token",
+		Status: s.tokenStatus,
+	}, nil)
+}
+
+// getToken behaves like a token without the "API Tokens Read" permission,
+// which is the common case for tokens reading their own details.
+func (s *Server) getToken(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeError(w, http.StatusForbidden, 9109, "Unauthorized to access requested resource")
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page, info := paginate(s.accounts, r, 20)
+	writeResult(w, page, &info)
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, _ := s.account(params["account"])
+	writeResult(w, acct, nil)
+}
+
+type envelope struct {
+	Success    bool                      `json:"success"`
+	Errors     []cloudflare.ResponseInfo `json:"errors"`
+	Messages   []cloudflare.ResponseInfo `json:"messages"`
+	Result     interface{}               `json:"result"`
+	ResultInfo *cloudflare.ResultInfo    `json:"result_info,omitempty"`
+}
+
+func writeResult(w http.ResponseWriter, result interface{}, info *cloudflare.ResultInfo) {
+	writeJSON(w, http.StatusOK, envelope{
+		Success:    true,
+		Errors:     []cloudflare.ResponseInfo{},
+		Messages:   []cloudflare.ResponseInfo{},
+		Result:     result,
+		ResultInfo: info,
+	})
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, envelope{
+		Errors:   []cloudflare.ResponseInfo{{Code: code, Message: message}},
+		Messages: []cloudflare.ResponseInfo{},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, 9207, "Request body is invalid: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// paginate returns the page selected by the page and per_page query
+// parameters along with the matching result_info.
+func paginate[T any](items []T, r *http.Request, defaultPerPage int) ([]T, cloudflare.ResultInfo) {
+	perPage := queryInt(r, "per_page", defaultPerPage)
+	page := queryInt(r, "page", 1)
+
+	totalPages := (len(items) + perPage - 1) / perPage
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+
+	result := append([]T{}, items[start:end]...)
+	return result, cloudflare.ResultInfo{
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+		Count:      len(result),
+		Total:      len(items),
+	}
+}
+
+func queryInt(r *http.Request, name string, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
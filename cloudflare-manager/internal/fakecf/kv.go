@@ -0,0 +1,174 @@
+package fakecf
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// AddKVNamespace creates a Workers KV namespace.
+func (s *Server) AddKVNamespace(accountID, title string) cloudflare.WorkersKVNamespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addNamespace(accountID, title)
+}
+
+// PutKV stores a value in a namespace.
+func (s *Server) PutKV(namespaceID, key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kvValues[namespaceID][key] = []byte(value)
+}
+
+// KV returns a stored value.
+func (s *Server) KV(namespaceID, key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.kvValues[namespaceID][key]
+	return string(value), ok
+}
+
+// KVNamespaces returns the namespaces of an account.
+func (s *Server) KVNamespaces(accountID string) []cloudflare.WorkersKVNamespace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cloudflare.WorkersKVNamespace(nil), s.namespaces[accountID]...)
+}
+
+func (s *Server) addNamespace(accountID, title string) cloudflare.WorkersKVNamespace {
+	ns := cloudflare.WorkersKVNamespace{ID: s.newID(), Title: title}
+	s.namespaces[accountID] = append(s.namespaces[accountID], ns)
+	s.kvValues[ns.ID] = map[string][]byte{}
+	return ns
+}
+
+func (s *Server) namespaceIndex(accountID, id string) int {
+	for i, ns := range s.namespaces[accountID] {
+		if ns.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) registerKVRoutes() {
+	const ns = "/accounts/:account/storage/kv/namespaces"
+	s.handle(http.MethodGet, ns, s.listKVNamespaces)
+	s.handle(http.MethodPost, ns, s.createKVNamespace)
+	s.handle(http.MethodPut, ns+"/:ns", s.withNamespace(s.renameKVNamespace))
+	s.handle(http.MethodDelete, ns+"/:ns", s.withNamespace(s.deleteKVNamespace))
+	s.handle(http.MethodGet, ns+"/:ns/keys", s.withNamespace(s.listKVKeys))
+	s.handle(http.MethodGet, ns+"/:ns/values/:key", s.withNamespace(s.getKV))
+	s.handle(http.MethodPut, ns+"/:ns/values/:key", s.withNamespace(s.putKV))
+	s.handle(http.MethodDelete, ns+"/:ns/values/:key", s.withNamespace(s.deleteKV))
+}
+
+// namespaceHandler serves a route under a KV namespace; i indexes the
+// account's namespaces. It runs with s.mu held.
+type namespaceHandler func(w http.ResponseWriter, r *http.Request, i int, params map[string]string)
+
+func (s *Server) withNamespace(h namespaceHandler) handler {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		i := s.namespaceIndex(params["account"], params["ns"])
+		if i < 0 {
+			writeError(w, http.StatusNotFound, 10013, "list keys: 'namespace not found'")
+			return
+		}
+		h(w, r, i, params)
+	}
+}
+
+func (s *Server) listKVNamespaces(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	page, info := paginate(s.namespaces[params["account"]], r, 20)
+	writeResult(w, page, &info)
+}
+
+func (s *Server) createKVNamespace(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body cloudflare.CreateWorkersKVNamespaceParams
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ns := range s.namespaces[params["account"]] {
+		if ns.Title == body.Title {
+			writeError(w, http.StatusBadRequest, 10014, "create namespace: 'a namespace with this account ID and title already exists'")
+			return
+		}
+	}
+	writeResult(w, s.addNamespace(params["account"], body.Title), nil)
+}
+
+func (s *Server) renameKVNamespace(w http.ResponseWriter, r *http.Request, i int, params map[string]string) {
+	var body cloudflare.UpdateWorkersKVNamespaceParams
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	s.namespaces[params["account"]][i].Title = body.Title
+	writeResult(w, nil, nil)
+}
+
+func (s *Server) deleteKVNamespace(w http.ResponseWriter, r *http.Request, i int, params map[string]string) {
+	list := s.namespaces[params["account"]]
+	delete(s.kvValues, list[i].ID)
+	s.namespaces[params["account"]] = append(list[:i], list[i+1:]...)
+	writeResult(w, nil, nil)
+}
+
+// listKVKeys pages through keys in name order with an opaque cursor, which
+// here is simply the last key of the previous page.
+func (s *Server) listKVKeys(w http.ResponseWriter, r *http.Request, _ int, params map[string]string) {
+	q := r.URL.Query()
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 1000
+	}
+
+	keys := []cloudflare.StorageKey{}
+	cursor := ""
+	for _, name := range sortedKeys(s.kvValues[params["ns"]]) {
+		if !strings.HasPrefix(name, q.Get("prefix")) || name <= q.Get("cursor") {
+			continue
+		}
+		if len(keys) == limit {
+			cursor = keys[len(keys)-1].Name
+			break
+		}
+		keys = append(keys, cloudflare.StorageKey{Name: name})
+	}
+
+	writeResult(w, keys, &cloudflare.ResultInfo{Count: len(keys), Cursor: cursor})
+}
+
+func (s *Server) getKV(w http.ResponseWriter, r *http.Request, _ int, params map[string]string) {
+	value, ok := s.kvValues[params["ns"]][params["key"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, 10009, "get: 'key not found'")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(value)
+}
+
+func (s *Server) putKV(w http.ResponseWriter, r *http.Request, _ int, params map[string]string) {
+	value, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 10001, err.Error())
+		return
+	}
+	s.kvValues[params["ns"]][params["key"]] = value
+	writeResult(w, nil, nil)
+}
+
+func (s *Server) deleteKV(w http.ResponseWriter, r *http.Request, _ int, params map[string]string) {
+	delete(s.kvValues[params["ns"]], params["key"])
+	writeResult(w, nil, nil)
+}
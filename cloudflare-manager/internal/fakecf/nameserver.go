@@ -0,0 +1,139 @@
+package fakecf
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// NameServer is a local DNS server that plays every role zone check talks
+// to: recursive resolver, TLD server and authoritative server. All host
+// names it is told about resolve to its own address, so one instance, with
+// the checker's port set to Port(), answers every query.
+type NameServer struct {
+	srv  *dns.Server
+	addr *net.UDPAddr
+
+	mu        sync.Mutex
+	records   map[string][]dns.RR
+	referrals map[string][]dns.RR
+	hosts     map[string]bool
+}
+
+// NewNameServer starts a UDP DNS server on 127.0.0.1 that is shut down when
+// the test finishes.
+func NewNameServer(tb testing.TB) *NameServer {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to start name server: %v", err)
+	}
+	ns := &NameServer{
+		addr:      pc.LocalAddr().(*net.UDPAddr),
+		records:   map[string][]dns.RR{},
+		referrals: map[string][]dns.RR{},
+		hosts:     map[string]bool{},
+	}
+	started := make(chan struct{})
+	ns.srv = &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(ns.serveDNS), NotifyStartedFunc: func() { close(started) }}
+	go ns.srv.ActivateAndServe()
+	<-started
+	tb.Cleanup(func() { ns.srv.Shutdown() })
+	return ns
+}
+
+// Addr is the server's host:port, for use as the resolver.
+func (ns *NameServer) Addr() string {
+	return ns.addr.String()
+}
+
+// Port is the server's port, for use as the authoritative query port.
+func (ns *NameServer) Port() string {
+	return strings.TrimPrefix(ns.Addr(), "127.0.0.1:")
+}
+
+// Add serves records given in zone file format, e.g.
+// "www.example.com. 300 IN A 192.0.2.1". NS record targets are made to
+// resolve to the server itself.
+func (ns *NameServer) Add(tb testing.TB, records ...string) {
+	tb.Helper()
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for _, s := range records {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			tb.Fatalf("bad record %q: %v", s, err)
+		}
+		key := recordKey(rr.Header().Name, rr.Header().Rrtype)
+		ns.records[key] = append(ns.records[key], rr)
+		if n, ok := rr.(*dns.NS); ok {
+			ns.hosts[strings.ToLower(n.Ns)] = true
+		}
+	}
+}
+
+// Delegate serves NS records given in zone file format as a delegation to
+// another server: queries for their name get a referral, with the records
+// in the authority section and no answer.
+func (ns *NameServer) Delegate(tb testing.TB, records ...string) {
+	tb.Helper()
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for _, s := range records {
+		rr, err := dns.NewRR(s)
+		if err != nil || rr.Header().Rrtype != dns.TypeNS {
+			tb.Fatalf("bad NS record %q: %v", s, err)
+		}
+		name := strings.ToLower(rr.Header().Name)
+		ns.referrals[name] = append(ns.referrals[name], rr)
+	}
+}
+
+// AddHosts makes host names resolve to the server itself.
+func (ns *NameServer) AddHosts(names ...string) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for _, name := range names {
+		ns.hosts[strings.ToLower(dns.Fqdn(name))] = true
+	}
+}
+
+// Remove stops serving every record with the given name and type.
+func (ns *NameServer) Remove(name string, rrtype uint16) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	delete(ns.records, recordKey(name, rrtype))
+}
+
+func (ns *NameServer) serveDNS(w dns.ResponseWriter, req *dns.Msg) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Authoritative = true
+	q := req.Question[0]
+
+	if rrs, ok := ns.referrals[strings.ToLower(q.Name)]; ok {
+		m.Authoritative = false
+		m.Ns = rrs
+	} else if rrs, ok := ns.records[recordKey(q.Name, q.Qtype)]; ok {
+		m.Answer = rrs
+	} else if cname, ok := ns.records[recordKey(q.Name, dns.TypeCNAME)]; ok {
+		m.Answer = cname
+	} else if q.Qtype == dns.TypeA && ns.hosts[strings.ToLower(q.Name)] {
+		m.Answer = []dns.RR{&dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   ns.addr.IP,
+		}}
+	} else if q.Qtype == dns.TypeNS {
+		m.Rcode = dns.RcodeNameError
+	}
+	w.WriteMsg(m)
+}
+
+func recordKey(name string, rrtype uint16) string {
+	return strings.ToLower(dns.Fqdn(name)) + " " + dns.TypeToString[rrtype]
+}
@@ -0,0 +1,140 @@
+package fakecf
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+type project struct {
+	cloudflare.PagesProject
+	deployments []cloudflare.PagesProjectDeployment
+}
+
+// AddPagesProject creates a Pages project. ID, subdomain and creation time
+// are filled in when empty.
+func (s *Server) AddPagesProject(accountID string, p cloudflare.PagesProject) cloudflare.PagesProject {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == "" {
+		p.ID = s.newID()
+	}
+	if p.SubDomain == "" {
+		p.SubDomain = p.Name + ".pages.dev"
+	}
+	if p.CreatedOn == nil {
+		now := time.Now().UTC()
+		p.CreatedOn = &now
+	}
+	s.projects[accountID] = append(s.projects[accountID], &project{PagesProject: p})
+	return p
+}
+
+// AddPagesDeployment adds a deployment to a project and makes it the
+// project's latest deployment.
+func (s *Server) AddPagesDeployment(accountID, projectName string, d cloudflare.PagesProjectDeployment) cloudflare.PagesProjectDeployment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.project(accountID, projectName)
+	if p == nil {
+		panic("fakecf: unknown Pages project " + projectName)
+	}
+	if d.ID == "" {
+		d.ID = s.newID()
+	}
+	if d.ShortID == "" {
+		d.ShortID = d.ID[len(d.ID)-8:]
+	}
+	if d.Environment == "" {
+		d.Environment = "production"
+	}
+	if d.URL == "" {
+		d.URL = "https://" + d.ShortID + "." + p.SubDomain
+	}
+	if d.CreatedOn == nil {
+		now := time.Now().UTC()
+		d.CreatedOn = &now
+	}
+	d.ProjectID = p.ID
+	d.ProjectName = p.Name
+
+	// The API lists the newest deployment first.
+	p.deployments = append([]cloudflare.PagesProjectDeployment{d}, p.deployments...)
+	p.LatestDeployment = d
+	return d
+}
+
+func (s *Server) project(accountID, name string) *project {
+	for _, p := range s.projects[accountID] {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) registerPagesRoutes() {
+	s.handle(http.MethodGet, "/accounts/:account/pages/projects", s.listPagesProjects)
+	s.handle(http.MethodGet, "/accounts/:account/pages/projects/:project", s.withProject(s.getPagesProject))
+	s.handle(http.MethodDelete, "/accounts/:account/pages/projects/:project", s.withProject(s.deletePagesProject))
+	s.handle(http.MethodGet, "/accounts/:account/pages/projects/:project/deployments", s.withProject(s.listPagesDeployments))
+	s.handle(http.MethodGet, "/accounts/:account/pages/projects/:project/deployments/:id", s.withProject(s.getPagesDeployment))
+}
+
+// projectHandler serves a route under a Pages project. It runs with s.mu held.
+type projectHandler func(w http.ResponseWriter, r *http.Request, p *project, params map[string]string)
+
+func (s *Server) withProject(h projectHandler) handler {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		p := s.project(params["account"], params["project"])
+		if p == nil {
+			writeError(w, http.StatusNotFound, 8000007, "Project not found. The specified project name does not match any of your existing projects.")
+			return
+		}
+		h(w, r, p, params)
+	}
+}
+
+func (s *Server) listPagesProjects(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []cloudflare.PagesProject
+	for _, p := range s.projects[params["account"]] {
+		list = append(list, p.PagesProject)
+	}
+	page, info := paginate(list, r, 10)
+	writeResult(w, page, &info)
+}
+
+func (s *Server) getPagesProject(w http.ResponseWriter, r *http.Request, p *project, _ map[string]string) {
+	writeResult(w, p.PagesProject, nil)
+}
+
+func (s *Server) deletePagesProject(w http.ResponseWriter, r *http.Request, p *project, params map[string]string) {
+	list := s.projects[params["account"]]
+	for i := range list {
+		if list[i] == p {
+			s.projects[params["account"]] = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	writeResult(w, nil, nil)
+}
+
+func (s *Server) listPagesDeployments(w http.ResponseWriter, r *http.Request, p *project, _ map[string]string) {
+	page, info := paginate(p.deployments, r, 25)
+	writeResult(w, page, &info)
+}
+
+func (s *Server) getPagesDeployment(w http.ResponseWriter, r *http.Request, p *project, params map[string]string) {
+	for _, d := range p.deployments {
+		if d.ID == params["id"] {
+			writeResult(w, d, nil)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, 8000009, "The deployment ID you have specified does not exist. Update the deployment ID and try again.")
+}
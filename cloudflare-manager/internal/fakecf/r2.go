@@ -0,0 +1,119 @@
+package fakecf
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// AddR2Bucket creates an R2 bucket.
+func (s *Server) AddR2Bucket(accountID, name string) cloudflare.R2Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addBucket(accountID, name, "")
+}
+
+// R2Buckets returns the buckets of an account.
+func (s *Server) R2Buckets(accountID string) []cloudflare.R2Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cloudflare.R2Bucket(nil), s.buckets[accountID]...)
+}
+
+func (s *Server) addBucket(accountID, name, location string) cloudflare.R2Bucket {
+	now := time.Now().UTC()
+	if location == "" {
+		location = "WNAM"
+	}
+	bucket := cloudflare.R2Bucket{Name: name, CreationDate: &now, Location: strings.ToUpper(location)}
+	s.buckets[accountID] = append(s.buckets[accountID], bucket)
+	return bucket
+}
+
+func (s *Server) bucketIndex(accountID, name string) int {
+	for i, b := range s.buckets[accountID] {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) registerR2Routes() {
+	s.handle(http.MethodGet, "/accounts/:account/r2/buckets", s.listR2Buckets)
+	s.handle(http.MethodPost, "/accounts/:account/r2/buckets", s.createR2Bucket)
+	s.handle(http.MethodGet, "/accounts/:account/r2/buckets/:bucket", s.getR2Bucket)
+	s.handle(http.MethodDelete, "/accounts/:account/r2/buckets/:bucket", s.deleteR2Bucket)
+}
+
+// listR2Buckets pages through buckets in name order with an opaque cursor,
+// which here is simply the last bucket of the previous page.
+func (s *Server) listR2Buckets(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := r.URL.Query()
+	perPage := queryInt(r, "per_page", 20)
+
+	sorted := append([]cloudflare.R2Bucket(nil), s.buckets[params["account"]]...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	buckets := []cloudflare.R2Bucket{}
+	cursor := ""
+	for _, b := range sorted {
+		if !strings.Contains(b.Name, q.Get("name_contains")) || b.Name <= q.Get("start_after") || b.Name <= q.Get("cursor") {
+			continue
+		}
+		if len(buckets) == perPage {
+			cursor = buckets[len(buckets)-1].Name
+			break
+		}
+		buckets = append(buckets, b)
+	}
+	writeResult(w, cloudflare.R2Buckets{Buckets: buckets}, &cloudflare.ResultInfo{PerPage: perPage, Cursor: cursor})
+}
+
+func (s *Server) createR2Bucket(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	var body cloudflare.CreateR2BucketParameters
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bucketIndex(params["account"], body.Name) >= 0 {
+		writeError(w, http.StatusConflict, 10004, "The bucket you tried to create already exists, and you own it.")
+		return
+	}
+	location := body.LocationHint
+	if location == "auto" {
+		location = ""
+	}
+	writeResult(w, s.addBucket(params["account"], body.Name, location), nil)
+}
+
+func (s *Server) getR2Bucket(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.bucketIndex(params["account"], params["bucket"])
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 10006, "The specified bucket does not exist.")
+		return
+	}
+	writeResult(w, s.buckets[params["account"]][i], nil)
+}
+
+func (s *Server) deleteR2Bucket(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.bucketIndex(params["account"], params["bucket"])
+	if i < 0 {
+		writeError(w, http.StatusNotFound, 10006, "The specified bucket does not exist.")
+		return
+	}
+	list := s.buckets[params["account"]]
+	s.buckets[params["account"]] = append(list[:i], list[i+1:]...)
+	writeResult(w, nil, nil)
+}
@@ -0,0 +1,104 @@
+package fakecf
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// AddRuleset sets the zone's entrypoint ruleset for a phase.
+func (s *Server) AddRuleset(zoneID, phase string, rules []cloudflare.RulesetRule) cloudflare.Ruleset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	z := s.zone(zoneID)
+	if z == nil {
+		panic("fakecf: unknown zone " + zoneID)
+	}
+	return s.putEntrypoint(z, phase, "", rules)
+}
+
+// Rulesets returns the rulesets of a zone.
+func (s *Server) Rulesets(zoneID string) []cloudflare.Ruleset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		return append([]cloudflare.Ruleset(nil), z.rulesets...)
+	}
+	return nil
+}
+
+func (s *Server) registerRulesetRoutes() {
+	s.handle(http.MethodGet, "/zones/:zone/rulesets", s.withZone(s.listRulesets))
+	s.handle(http.MethodGet, "/zones/:zone/rulesets/:id", s.withZone(s.getRuleset))
+	s.handle(http.MethodGet, "/zones/:zone/rulesets/phases/:phase/entrypoint", s.withZone(s.getEntrypoint))
+	s.handle(http.MethodPut, "/zones/:zone/rulesets/phases/:phase/entrypoint", s.withZone(s.updateEntrypoint))
+}
+
+// listRulesets leaves out the rules, like the real API.
+func (s *Server) listRulesets(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	list := []cloudflare.Ruleset{}
+	for _, rs := range z.rulesets {
+		rs.Rules = nil
+		list = append(list, rs)
+	}
+	writeResult(w, list, nil)
+}
+
+func (s *Server) getRuleset(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	for _, rs := range z.rulesets {
+		if rs.ID == params["id"] {
+			writeResult(w, rs, nil)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, 10000, "Could not find ruleset "+params["id"])
+}
+
+func (s *Server) getEntrypoint(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	for _, rs := range z.rulesets {
+		if rs.Phase == params["phase"] {
+			writeResult(w, rs, nil)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, 10003, "Could not find entrypoint ruleset in the "+params["phase"]+" phase")
+}
+
+func (s *Server) updateEntrypoint(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	var body cloudflare.UpdateEntrypointRulesetParams
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	writeResult(w, s.putEntrypoint(z, params["phase"], body.Description, body.Rules), nil)
+}
+
+// putEntrypoint replaces the rules of a phase's entrypoint ruleset, creating
+// it if needed. Callers hold s.mu.
+func (s *Server) putEntrypoint(z *zone, phase, description string, rules []cloudflare.RulesetRule) cloudflare.Ruleset {
+	now := time.Now().UTC()
+	for i := range rules {
+		rules[i].ID = s.newID()
+		rules[i].LastUpdated = &now
+	}
+	for i, rs := range z.rulesets {
+		if rs.Phase == phase {
+			rs.Rules = rules
+			rs.Description = description
+			rs.LastUpdated = &now
+			z.rulesets[i] = rs
+			return rs
+		}
+	}
+	rs := cloudflare.Ruleset{
+		ID:          s.newID(),
+		Name:        "default",
+		Description: description,
+		Kind:        "zone",
+		Phase:       phase,
+		Rules:       rules,
+		LastUpdated: &now,
+	}
+	z.rulesets = append(z.rulesets, rs)
+	return rs
+}
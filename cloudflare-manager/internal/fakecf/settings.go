@@ -0,0 +1,144 @@
+package fakecf
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// defaultSettings are the values a new zone starts with.
+var defaultSettings = map[string]interface{}{
+	"0rtt":                     "off",
+	"always_online":            "off",
+	"always_use_https":         "off",
+	"automatic_https_rewrites": "on",
+	"brotli":                   "on",
+	"browser_cache_ttl":        14400,
+	"browser_check":            "on",
+	"cache_level":              "aggressive",
+	"challenge_ttl":            1800,
+	"development_mode":         "off",
+	"early_hints":              "off",
+	"email_obfuscation":        "on",
+	"hotlink_protection":       "off",
+	"http2":                    "on",
+	"http3":                    "on",
+	"ipv6":                     "on",
+	"max_upload":               100,
+	"min_tls_version":          "1.0",
+	"opportunistic_encryption": "on",
+	"rocket_loader":            "off",
+	"security_level":           "medium",
+	"ssl":                      "flexible",
+	"tls_1_3":                  "on",
+	"websockets":               "on",
+}
+
+// readOnlySettings cannot be changed on the free plan.
+var readOnlySettings = map[string]bool{"http2": true}
+
+// ZoneSettings returns a zone's current setting values.
+func (s *Server) ZoneSettings(zoneID string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	z := s.zone(zoneID)
+	if z == nil {
+		return nil
+	}
+	values := make(map[string]interface{}, len(z.settings))
+	for id, v := range z.settings {
+		values[id] = v.Value
+	}
+	return values
+}
+
+func (s *Server) registerSettingsRoutes() {
+	s.handle(http.MethodGet, "/zones/:zone/settings", s.withZone(s.listSettings))
+	s.handle(http.MethodPatch, "/zones/:zone/settings", s.withZone(s.updateSettings))
+	s.handle(http.MethodGet, "/zones/:zone/settings/:name", s.withZone(s.getSetting))
+	s.handle(http.MethodPatch, "/zones/:zone/settings/:name", s.withZone(s.updateSetting))
+}
+
+func newSettings() map[string]cloudflare.ZoneSetting {
+	settings := make(map[string]cloudflare.ZoneSetting, len(defaultSettings))
+	for id, v := range defaultSettings {
+		settings[id] = cloudflare.ZoneSetting{ID: id, Value: v, Editable: !readOnlySettings[id]}
+	}
+	return settings
+}
+
+func (s *Server) listSettings(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	writeResult(w, sortedSettings(z), nil)
+}
+
+func (s *Server) getSetting(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	setting, ok := z.settings[params["name"]]
+	if !ok {
+		writeError(w, http.StatusNotFound, 1003, "Invalid or missing zone setting "+params["name"])
+		return
+	}
+	writeResult(w, setting, nil)
+}
+
+func (s *Server) updateSettings(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	var body struct {
+		Items []cloudflare.ZoneSetting `json:"items"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	// Validate everything first so a bad item changes nothing.
+	for _, item := range body.Items {
+		if !checkSetting(w, z, item.ID) {
+			return
+		}
+	}
+	for _, item := range body.Items {
+		setSetting(z, item.ID, item.Value)
+	}
+	writeResult(w, sortedSettings(z), nil)
+}
+
+func (s *Server) updateSetting(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	var body struct {
+		Value interface{} `json:"value"`
+	}
+	if !decodeBody(w, r, &body) || !checkSetting(w, z, params["name"]) {
+		return
+	}
+	writeResult(w, setSetting(z, params["name"], body.Value), nil)
+}
+
+func checkSetting(w http.ResponseWriter, z *zone, id string) bool {
+	setting, ok := z.settings[id]
+	switch {
+	case !ok:
+		writeError(w, http.StatusBadRequest, 1006, "Unrecognized zone setting name "+id)
+	case !setting.Editable:
+		writeError(w, http.StatusBadRequest, 1007, id+" is not editable on this plan")
+	}
+	return ok && setting.Editable
+}
+
+func setSetting(z *zone, id string, value interface{}) cloudflare.ZoneSetting {
+	setting := z.settings[id]
+	setting.Value = value
+	setting.ModifiedOn = time.Now().UTC().Format(time.RFC3339)
+	setting.TimeRemaining = 0
+	if id == "development_mode" && value == "on" {
+		setting.TimeRemaining = 3 * 60 * 60
+	}
+	z.settings[id] = setting
+	return setting
+}
+
+func sortedSettings(z *zone) []cloudflare.ZoneSetting {
+	list := make([]cloudflare.ZoneSetting, 0, len(z.settings))
+	for _, setting := range z.settings {
+		list = append(list, setting)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
@@ -0,0 +1,216 @@
+package fakecf
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+type worker struct {
+	cloudflare.WorkerMetaData
+	name   string
+	script string
+}
+
+// AddWorker uploads a script to an account.
+func (s *Server) AddWorker(accountID, name, script string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putWorker(accountID, name, script)
+}
+
+// Worker returns the script uploaded under name.
+func (s *Server) Worker(accountID, name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w := s.worker(accountID, name); w != nil {
+		return w.script, true
+	}
+	return "", false
+}
+
+// AddWorkerRoute adds a route to a zone.
+func (s *Server) AddWorkerRoute(zoneID, pattern, script string) cloudflare.WorkerRoute {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	z := s.zone(zoneID)
+	if z == nil {
+		panic("fakecf: unknown zone " + zoneID)
+	}
+	route := cloudflare.WorkerRoute{ID: s.newID(), Pattern: pattern, ScriptName: script}
+	z.routes = append(z.routes, route)
+	return route
+}
+
+// WorkerRoutes returns the routes of a zone.
+func (s *Server) WorkerRoutes(zoneID string) []cloudflare.WorkerRoute {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		return append([]cloudflare.WorkerRoute(nil), z.routes...)
+	}
+	return nil
+}
+
+func (s *Server) worker(accountID, name string) *worker {
+	for _, w := range s.workers[accountID] {
+		if w.name == name {
+			return w
+		}
+	}
+	return nil
+}
+
+func (s *Server) putWorker(accountID, name, script string) *worker {
+	now := time.Now().UTC()
+	w := s.worker(accountID, name)
+	if w == nil {
+		w = &worker{name: name}
+		w.ID = name
+		w.CreatedOn = now
+		s.workers[accountID] = append(s.workers[accountID], w)
+	}
+	w.script = script
+	w.ETAG = s.newID()
+	w.Size = len(script)
+	w.ModifiedOn = now
+	return w
+}
+
+func (s *Server) registerWorkerRoutes() {
+	s.handle(http.MethodGet, "/accounts/:account/workers/scripts", s.listWorkers)
+	s.handle(http.MethodGet, "/accounts/:account/workers/scripts/:name", s.getWorker)
+	s.handle(http.MethodPut, "/accounts/:account/workers/scripts/:name", s.uploadWorker)
+	s.handle(http.MethodDelete, "/accounts/:account/workers/scripts/:name", s.deleteWorker)
+
+	s.handle(http.MethodGet, "/zones/:zone/workers/routes", s.withZone(s.listWorkerRoutes))
+	s.handle(http.MethodPost, "/zones/:zone/workers/routes", s.withZone(s.createWorkerRoute))
+	s.handle(http.MethodGet, "/zones/:zone/workers/routes/:id", s.withZone(s.getWorkerRoute))
+	s.handle(http.MethodDelete, "/zones/:zone/workers/routes/:id", s.withZone(s.deleteWorkerRoute))
+}
+
+func (s *Server) listWorkers(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []cloudflare.WorkerMetaData{}
+	for _, wk := range s.workers[params["account"]] {
+		list = append(list, wk.WorkerMetaData)
+	}
+	writeResult(w, list, nil)
+}
+
+// getWorker returns the raw script, as the download endpoint does.
+func (s *Server) getWorker(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wk := s.worker(params["account"], params["name"])
+	if wk == nil {
+		writeError(w, http.StatusNotFound, 10007, "workers.api.error.script_not_found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/javascript")
+	io.WriteString(w, wk.script)
+}
+
+func (s *Server) uploadWorker(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	script, err := readScript(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 10021, "Uncaught error in script: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(script) == "" {
+		writeError(w, http.StatusBadRequest, 10021, "No script content")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wk := s.putWorker(params["account"], params["name"], script)
+	writeResult(w, cloudflare.WorkerScript{WorkerMetaData: wk.WorkerMetaData}, nil)
+}
+
+// readScript returns the uploaded script, either the raw body or, for module
+// uploads, the first part of the multipart form that is not the metadata.
+func readScript(r *http.Request) (string, error) {
+	mediaType, mediaParams, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		body, err := io.ReadAll(r.Body)
+		return string(body), err
+	}
+
+	reader := multipart.NewReader(r.Body, mediaParams["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if part.FormName() == "metadata" {
+			continue
+		}
+		body, err := io.ReadAll(part)
+		return string(body), err
+	}
+}
+
+func (s *Server) deleteWorker(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.workers[params["account"]]
+	for i, wk := range list {
+		if wk.name == params["name"] {
+			s.workers[params["account"]] = append(list[:i], list[i+1:]...)
+			writeResult(w, nil, nil)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, 10007, "workers.api.error.script_not_found")
+}
+
+func (s *Server) listWorkerRoutes(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	writeResult(w, append([]cloudflare.WorkerRoute{}, z.routes...), nil)
+}
+
+func (s *Server) createWorkerRoute(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	var params cloudflare.CreateWorkerRouteParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	for _, route := range z.routes {
+		if route.Pattern == params.Pattern {
+			writeError(w, http.StatusConflict, 10020, "A route with the same pattern already exists")
+			return
+		}
+	}
+	route := cloudflare.WorkerRoute{ID: s.newID(), Pattern: params.Pattern, ScriptName: params.Script}
+	z.routes = append(z.routes, route)
+	writeResult(w, route, nil)
+}
+
+func (s *Server) getWorkerRoute(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	for _, route := range z.routes {
+		if route.ID == params["id"] {
+			writeResult(w, route, nil)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, 10005, "Route not found")
+}
+
+func (s *Server) deleteWorkerRoute(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string) {
+	for i, route := range z.routes {
+		if route.ID == params["id"] {
+			z.routes = append(z.routes[:i], z.routes[i+1:]...)
+			writeResult(w, map[string]string{"id": route.ID}, nil)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, 10005, "Route not found")
+}
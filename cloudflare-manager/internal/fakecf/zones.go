@@ -0,0 +1,271 @@
+package fakecf
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// NameServers are assigned to every zone the server creates.
+var NameServers = []string{"ada.ns.cloudflare.com", "bob.ns.cloudflare.com"}
+
+type zone struct {
+	cloudflare.Zone
+	records  []cloudflare.DNSRecord
+	routes   []cloudflare.WorkerRoute
+	rulesets []cloudflare.Ruleset
+	purges   []Purge
+	dnssec   dnssecState
+	// settings holds the zone settings by ID.
+	settings map[string]cloudflare.ZoneSetting
+	// activationChecks counts activation check requests.
+	activationChecks int
+}
+
+// AddZone creates an active zone in the given account.
+func (s *Server) AddZone(accountID, name string) cloudflare.Zone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	z := s.newZone(accountID, name)
+	z.Status = "active"
+	return z.Zone
+}
+
+// Zone returns the zone with the given ID.
+func (s *Server) Zone(id string) (cloudflare.Zone, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(id); z != nil {
+		return z.Zone, true
+	}
+	return cloudflare.Zone{}, false
+}
+
+// ZoneByName returns the zone with the given name.
+func (s *Server) ZoneByName(name string) (cloudflare.Zone, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, z := range s.zones {
+		if z.Name == name {
+			return z.Zone, true
+		}
+	}
+	return cloudflare.Zone{}, false
+}
+
+// SetZoneStatus changes a zone's status, e.g. to "pending".
+func (s *Server) SetZoneStatus(zoneID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		z.Status = status
+	}
+}
+
+// ActivationChecks returns how many activation checks were requested for a
+// zone.
+func (s *Server) ActivationChecks(zoneID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		return z.activationChecks
+	}
+	return 0
+}
+
+// Purge is a cache purge request as received.
+type Purge struct {
+	Everything bool        `json:"purge_everything"`
+	Files      []PurgeFile `json:"files"`
+	Tags       []string    `json:"tags"`
+	Hosts      []string    `json:"hosts"`
+	Prefixes   []string    `json:"prefixes"`
+}
+
+// PurgeFile is a file to purge, given either as a URL or as an object with
+// the URL and cache key headers.
+type PurgeFile struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// UnmarshalJSON accepts both forms.
+func (f *PurgeFile) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &f.URL)
+	}
+	type plain PurgeFile
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+// maxPurgeItems is the API's limit on files, tags, hosts and prefixes per
+// request.
+const maxPurgeItems = 30
+
+// Purges returns the cache purge requests received for a zone.
+func (s *Server) Purges(zoneID string) []Purge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if z := s.zone(zoneID); z != nil {
+		return append([]Purge(nil), z.purges...)
+	}
+	return nil
+}
+
+func (s *Server) newZone(accountID, name string) *zone {
+	acct, _ := s.account(accountID)
+	now := time.Now().UTC()
+	z := &zone{Zone: cloudflare.Zone{
+		ID:          s.newID(),
+		Name:        name,
+		CreatedOn:   now,
+		ModifiedOn:  now,
+		NameServers: append([]string(nil), NameServers...),
+		Plan:        cloudflare.ZonePlan{ZonePlanCommon: cloudflare.ZonePlanCommon{Name: "Free Website"}},
+		Status:      "pending",
+		Type:        "full",
+		Account:     cloudflare.Account{ID: acct.ID, Name: acct.Name},
+	}, settings: newSettings()}
+	s.zones = append(s.zones, z)
+	return z
+}
+
+func (s *Server) zone(id string) *zone {
+	for _, z := range s.zones {
+		if z.ID == id {
+			return z
+		}
+	}
+	return nil
+}
+
+func (s *Server) registerZoneRoutes() {
+	s.handle(http.MethodGet, "/zones", s.listZones)
+	s.handle(http.MethodPost, "/zones", s.createZone)
+	s.handle(http.MethodGet, "/zones/:zone", s.withZone(s.getZone))
+	s.handle(http.MethodDelete, "/zones/:zone", s.withZone(s.deleteZone))
+	s.handle(http.MethodPost, "/zones/:zone/purge_cache", s.withZone(s.purgeCache))
+	s.handle(http.MethodPut, "/zones/:zone/activation_check", s.withZone(s.activationCheck))
+}
+
+// zoneHandler serves a route under /zones/:zone. It runs with s.mu held.
+type zoneHandler func(w http.ResponseWriter, r *http.Request, z *zone, params map[string]string)
+
+func (s *Server) withZone(h zoneHandler) handler {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		z := s.zone(params["zone"])
+		if z == nil {
+			writeError(w, http.StatusNotFound, 1001, "Invalid zone identifier")
+			return
+		}
+		h(w, r, z, params)
+	}
+}
+
+func (s *Server) listZones(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	if perPage := q.Get("per_page"); perPage != "" {
+		if n, err := strconv.Atoi(perPage); err != nil || n < 5 || n > 50 {
+			writeError(w, http.StatusBadRequest, 1001, "per_page must be between 5 and 50")
+			return
+		}
+	}
+	var zones []cloudflare.Zone
+	for _, z := range s.zones {
+		if name := q.Get("name"); name != "" && z.Name != name {
+			continue
+		}
+		if status := q.Get("status"); status != "" && z.Status != status {
+			continue
+		}
+		if id := q.Get("account.id"); id != "" && z.Account.ID != id {
+			continue
+		}
+		zones = append(zones, z.Zone)
+	}
+
+	page, info := paginate(zones, r, 20)
+	writeResult(w, page, &info)
+}
+
+func (s *Server) createZone(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var body struct {
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Account struct {
+			ID string `json:"id"`
+		} `json:"organization"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if body.Name == "" {
+		writeError(w, http.StatusBadRequest, 1002, "Invalid or missing zone name")
+		return
+	}
+	for _, z := range s.zones {
+		if z.Name == body.Name {
+			writeError(w, http.StatusBadRequest, 1061, body.Name+" already exists")
+			return
+		}
+	}
+	if _, ok := s.account(body.Account.ID); !ok {
+		writeError(w, http.StatusBadRequest, 1003, "Invalid or missing account")
+		return
+	}
+
+	z := s.newZone(body.Account.ID, body.Name)
+	if body.Type != "" {
+		z.Type = body.Type
+	}
+	writeResult(w, z.Zone, nil)
+}
+
+func (s *Server) getZone(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	writeResult(w, z.Zone, nil)
+}
+
+func (s *Server) deleteZone(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	for i := range s.zones {
+		if s.zones[i] == z {
+			s.zones = append(s.zones[:i], s.zones[i+1:]...)
+			break
+		}
+	}
+	writeResult(w, map[string]string{"id": z.ID}, nil)
+}
+
+func (s *Server) purgeCache(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	var req Purge
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !req.Everything && len(req.Files)+len(req.Tags)+len(req.Hosts)+len(req.Prefixes) == 0 {
+		writeError(w, http.StatusBadRequest, 1012, "Request must contain one of \"purge_everything\", \"files\", \"tags\", \"hosts\" or \"prefixes\"")
+		return
+	}
+	for _, n := range []int{len(req.Files), len(req.Tags), len(req.Hosts), len(req.Prefixes)} {
+		if n > maxPurgeItems {
+			writeError(w, http.StatusBadRequest, 1015, "Only 30 items can be purged per request")
+			return
+		}
+	}
+	z.purges = append(z.purges, req)
+	writeResult(w, map[string]string{"id": z.ID}, nil)
+}
+
+func (s *Server) activationCheck(w http.ResponseWriter, r *http.Request, z *zone, _ map[string]string) {
+	z.activationChecks++
+	writeResult(w, map[string]string{"id": z.ID}, nil)
+}
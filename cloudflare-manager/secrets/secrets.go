@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	BackendKeyring = "keyring"
+	BackendVault   = "vault"
+
+	keyringService = "cloudflare-manager"
+)
+
+// Store keeps API tokens outside the config file, keyed by account name.
+type Store interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// Open returns the store for backend. vaultPath is only used by the vault
+// backend.
+func Open(backend, vaultPath string) (Store, error) {
+	switch backend {
+	case BackendKeyring:
+		return keyringStore{}, nil
+	case BackendVault:
+		return &Vault{Path: vaultPath}, nil
+	}
+	return nil, fmt.Errorf("unknown secrets backend %q (use keyring or vault)", backend)
+}
+
+// Ref builds the reference stored in the config file for an account's token.
+func Ref(backend, account string) string {
+	return backend + ":" + account
+}
+
+// ParseRef splits a reference into backend and account name.
+func ParseRef(ref string) (string, string, error) {
+	backend, account, ok := strings.Cut(ref, ":")
+	if !ok || account == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+	return backend, account, nil
+}
+
+// Resolve looks up the secret a reference points to.
+func Resolve(ref, vaultPath string) (string, error) {
+	backend, account, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	store, err := Open(backend, vaultPath)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(account)
+}
+
+type keyringStore struct{}
+
+func (keyringStore) Get(account string) (string, error) {
+	secret, err := keyring.Get(keyringService, account)
+	if err != nil {
+		return "", fmt.Errorf("keyring lookup for %s failed: %w", account, err)
+	}
+	return secret, nil
+}
+
+func (keyringStore) Set(account, secret string) error {
+	if err := keyring.Set(keyringService, account, secret); err != nil {
+		return fmt.Errorf("failed to store token for %s in keyring: %w", account, err)
+	}
+	return nil
+}
+
+func (keyringStore) Delete(account string) error {
+	if err := keyring.Delete(keyringService, account); err != nil && err != keyring.ErrNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// EnvVaultPassphrase unlocks the vault without prompting.
+const EnvVaultPassphrase = "CFM_VAULT_PASSPHRASE"
+
+// passphrases caches unlocked vaults by path for the life of the process so
+// each invocation asks at most once. Commands that work on several accounts
+// at once read it concurrently.
+var (
+	passphrasesMu sync.Mutex
+	passphrases   = map[string]string{}
+)
+
+// Vault is a passphrase-encrypted file of tokens (scrypt + AES-256-GCM).
+type Vault struct {
+	Path string
+}
+
+type vaultFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func (v *Vault) Get(account string) (string, error) {
+	entries, err := v.load(false)
+	if err != nil {
+		return "", err
+	}
+	secret, ok := entries[account]
+	if !ok {
+		return "", fmt.Errorf("no token for %s in vault %s", account, v.Path)
+	}
+	return secret, nil
+}
+
+func (v *Vault) Set(account, secret string) error {
+	entries, err := v.load(true)
+	if err != nil {
+		return err
+	}
+	entries[account] = secret
+	return v.save(entries)
+}
+
+func (v *Vault) Delete(account string) error {
+	if _, err := os.Stat(v.Path); os.IsNotExist(err) {
+		return nil
+	}
+	entries, err := v.load(false)
+	if err != nil {
+		return err
+	}
+	delete(entries, account)
+	return v.save(entries)
+}
+
+func (v *Vault) load(create bool) (map[string]string, error) {
+	if _, err := os.Stat(v.Path); os.IsNotExist(err) {
+		if !create {
+			return nil, fmt.Errorf("vault %s does not exist", v.Path)
+		}
+		passphrase, err := v.passphrase(true)
+		if err != nil {
+			return nil, err
+		}
+		v.remember(passphrase)
+		return map[string]string{}, nil
+	}
+
+	passphrase, err := v.passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decrypt(v.Path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	v.remember(passphrase)
+	return entries, nil
+}
+
+func (v *Vault) save(entries map[string]string) error {
+	passphrase, err := v.passphrase(false)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(vaultFile{
+		Version: 1,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(v.Path, data, 0600)
+}
+
+// remember caches a verified passphrase.
+func (v *Vault) remember(passphrase string) {
+	passphrasesMu.Lock()
+	defer passphrasesMu.Unlock()
+	passphrases[v.Path] = passphrase
+}
+
+// passphrase returns the cached passphrase, CFM_VAULT_PASSPHRASE, or prompts
+// on the terminal. When confirm is set the user is asked twice, for creating
+// a new vault. Callers cache it once it has been verified.
+func (v *Vault) passphrase(confirm bool) (string, error) {
+	passphrasesMu.Lock()
+	cached, ok := passphrases[v.Path]
+	passphrasesMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+	if p := os.Getenv(EnvVaultPassphrase); p != "" {
+		return p, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("vault %s is locked: set %s or run interactively", v.Path, EnvVaultPassphrase)
+	}
+
+	fmt.Fprintf(os.Stderr, "Vault passphrase (%s): ", v.Path)
+	p, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(again) != string(p) {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	if strings.TrimSpace(string(p)) == "" {
+		return "", errors.New("empty passphrase")
+	}
+
+	return string(p), nil
+}
+
+func decrypt(path, passphrase string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f vaultFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("corrupt vault %s: %w", path, err)
+	}
+	if f.Version != 1 {
+		return nil, fmt.Errorf("unsupported vault version %d", f.Version)
+	}
+
+	gcm, err := newGCM(passphrase, f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, errors.New("wrong vault passphrase")
+	}
+
+	entries := map[string]string{}
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("corrupt vault %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
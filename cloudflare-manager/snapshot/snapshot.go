@@ -0,0 +1,188 @@
+// Package snapshot stores point-in-time copies of a zone's DNS records as
+// JSON files, one directory per zone.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// timeFormat names snapshot files; it sorts chronologically.
+const timeFormat = "20060102T150405Z"
+
+// Snapshot is the full set of DNS records of a zone at one point in time.
+type Snapshot struct {
+	// ID is "<zone>@<timestamp>". It is derived from the file name and not
+	// stored in the file.
+	ID      string                 `json:"-"`
+	Zone    string                 `json:"zone"`
+	ZoneID  string                 `json:"zone_id"`
+	Account string                 `json:"account,omitempty"`
+	Taken   time.Time              `json:"taken"`
+	Note    string                 `json:"note,omitempty"`
+	Records []cloudflare.DNSRecord `json:"records"`
+}
+
+// Store keeps snapshots under a directory.
+type Store struct {
+	Dir string
+}
+
+// NewStore returns a store rooted at dir, which is created on first save.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// ErrNotFound is returned when no snapshot matches a reference.
+var ErrNotFound = errors.New("snapshot not found")
+
+// Save writes s and sets its ID. Snapshots taken within the same second get
+// a numeric suffix.
+func (st *Store) Save(s *Snapshot) error {
+	if s.Zone == "" {
+		return fmt.Errorf("snapshot has no zone")
+	}
+	if s.Taken.IsZero() {
+		s.Taken = time.Now()
+	}
+	s.Taken = s.Taken.UTC().Truncate(time.Second)
+
+	dir := filepath.Join(st.Dir, s.Zone)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	stamp := s.Taken.Format(timeFormat)
+	for i := 1; ; i++ {
+		name := stamp
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d", stamp, i)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name+".json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		s.ID = s.Zone + "@" + name
+		return nil
+	}
+}
+
+// List returns the snapshots of zone, or of every zone when zone is empty,
+// newest first.
+func (st *Store) List(zone string) ([]Snapshot, error) {
+	zones := []string{zone}
+	if zone == "" {
+		entries, err := os.ReadDir(st.Dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+		}
+		zones = nil
+		for _, e := range entries {
+			if e.IsDir() {
+				zones = append(zones, e.Name())
+			}
+		}
+	}
+
+	var snaps []Snapshot
+	for _, z := range zones {
+		entries, err := os.ReadDir(filepath.Join(st.Dir, z))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+		}
+		for _, e := range entries {
+			name, ok := strings.CutSuffix(e.Name(), ".json")
+			if !ok || e.IsDir() {
+				continue
+			}
+			s, err := readFile(filepath.Join(st.Dir, z, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			s.ID = z + "@" + name
+			snaps = append(snaps, *s)
+		}
+	}
+
+	sort.SliceStable(snaps, func(i, j int) bool {
+		if !snaps[i].Taken.Equal(snaps[j].Taken) {
+			return snaps[i].Taken.After(snaps[j].Taken)
+		}
+		return snaps[i].ID > snaps[j].ID
+	})
+	return snaps, nil
+}
+
+// Load finds a snapshot by reference: an ID as shown by List, a zone name
+// for its latest snapshot, or the path of a snapshot file.
+func (st *Store) Load(ref string) (*Snapshot, error) {
+	if zone, name, ok := strings.Cut(ref, "@"); ok {
+		s, err := readFile(filepath.Join(st.Dir, zone, name+".json"))
+		if os.IsNotExist(errors.Unwrap(err)) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.ID = ref
+		return s, nil
+	}
+
+	if strings.HasSuffix(ref, ".json") {
+		if _, err := os.Stat(ref); err == nil {
+			s, err := readFile(ref)
+			if err != nil {
+				return nil, err
+			}
+			s.ID = ref
+			return s, nil
+		}
+	}
+
+	snaps, err := st.List(strings.ToLower(ref))
+	if err != nil {
+		return nil, err
+	}
+	if len(snaps) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	}
+	return &snaps[0], nil
+}
+
+func readFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
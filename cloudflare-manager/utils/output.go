@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+)
+
+var (
+	outputFormat   = FormatTable
+	outputTemplate *template.Template
+)
+
+// View describes one piece of command output. Data is the full value used for
+// JSON, YAML and templates; Headers and Rows are used for table and CSV.
+type View struct {
+	Data    interface{}
+	Headers []string
+	Rows    [][]string
+
+	// MaxWidth truncates the named columns in table output only.
+	MaxWidth map[string]int
+	// Empty is printed in table output when there are no rows.
+	Empty string
+	// Footer is printed after the table in table output.
+	Footer string
+	// Text replaces the table in table output, for detail views.
+	Text func()
+}
+
+// SetOutput selects the output format for Render. A non-empty tmpl takes
+// precedence over format and is executed once per item.
+func SetOutput(format, tmpl string) error {
+	outputTemplate = nil
+	if tmpl != "" {
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid --template: %w", err)
+		}
+		outputTemplate = t
+		return nil
+	}
+
+	switch strings.ToLower(format) {
+	case "", FormatTable:
+		outputFormat = FormatTable
+	case FormatJSON, FormatYAML, FormatCSV:
+		outputFormat = strings.ToLower(format)
+	default:
+		return fmt.Errorf("unknown output format %q (use json, yaml, csv or table)", format)
+	}
+	return nil
+}
+
+// OutputFormat returns the selected format, or "template" when a template is set.
+func OutputFormat() string {
+	if outputTemplate != nil {
+		return "template"
+	}
+	return outputFormat
+}
+
+// IsTableOutput reports whether human-readable output is selected.
+func IsTableOutput() bool {
+	return outputTemplate == nil && outputFormat == FormatTable
+}
+
+// Render writes v in the selected output format.
+func Render(v View) error {
+	if rv := reflect.ValueOf(v.Data); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v.Data = []interface{}{}
+	}
+
+	if outputTemplate != nil {
+		return renderTemplate(v.Data)
+	}
+
+	switch outputFormat {
+	case FormatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v.Data)
+	case FormatYAML:
+		generic, err := toGeneric(v.Data)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write(v.Headers); err != nil {
+			return err
+		}
+		if err := w.WriteAll(v.Rows); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	if v.Text != nil {
+		v.Text()
+		return nil
+	}
+	if len(v.Rows) == 0 && v.Empty != "" {
+		fmt.Println(v.Empty)
+		return nil
+	}
+
+	rows := v.Rows
+	if len(v.MaxWidth) > 0 {
+		rows = make([][]string, len(v.Rows))
+		for i, row := range v.Rows {
+			rows[i] = make([]string, len(row))
+			for j, cell := range row {
+				if j < len(v.Headers) {
+					if max, ok := v.MaxWidth[v.Headers[j]]; ok {
+						cell = Truncate(cell, max)
+					}
+				}
+				rows[i][j] = cell
+			}
+		}
+	}
+	PrintTable(v.Headers, rows)
+	if v.Footer != "" {
+		fmt.Println(v.Footer)
+	}
+	return nil
+}
+
+func renderTemplate(data interface{}) error {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			if err := executeTemplate(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return executeTemplate(data)
+}
+
+func executeTemplate(item interface{}) error {
+	if err := outputTemplate.Execute(os.Stdout, item); err != nil {
+		return fmt.Errorf("template error: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// toGeneric round-trips v through JSON so YAML output uses the same field
+// names as the API.
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// streamBlock is how many table rows a Stream buffers to align columns.
+const streamBlock = 500
+
+// Stream writes a list item by item, for lists too long to hold in memory.
+// The output is the same as Render with the whole list: JSON is one array,
+// YAML one sequence and templates run once per item. Table columns are
+// sized by the first rows; wider cells further down push their row out
+// instead of realigning what was already printed.
+type Stream struct {
+	view   View
+	n      int
+	rows   [][]string
+	widths []int
+	csv    *csv.Writer
+}
+
+// NewStream starts streaming a list. Headers, MaxWidth and Empty are used
+// as in Render; Data, Rows, Footer and Text are ignored.
+func NewStream(v View) *Stream {
+	return &Stream{view: v}
+}
+
+// Add writes one item, with row as its table and CSV cells.
+func (s *Stream) Add(item interface{}, row []string) error {
+	s.n++
+	if outputTemplate != nil {
+		return executeTemplate(item)
+	}
+
+	switch outputFormat {
+	case FormatJSON:
+		data, err := json.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if s.n == 1 {
+			sep = "[\n  "
+		}
+		_, err = fmt.Fprintf(os.Stdout, "%s%s", sep, data)
+		return err
+	case FormatYAML:
+		generic, err := toGeneric([]interface{}{item})
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		if err := s.startCSV(); err != nil {
+			return err
+		}
+		return s.csv.Write(row)
+	}
+
+	s.rows = append(s.rows, row)
+	if len(s.rows) == streamBlock {
+		s.flushTable()
+	}
+	return nil
+}
+
+// Close ends the list. footer is printed after the table in table output.
+func (s *Stream) Close(footer string) error {
+	if outputTemplate != nil {
+		return nil
+	}
+
+	switch outputFormat {
+	case FormatJSON:
+		end := "\n]\n"
+		if s.n == 0 {
+			end = "[]\n"
+		}
+		_, err := fmt.Print(end)
+		return err
+	case FormatYAML:
+		if s.n == 0 {
+			fmt.Println("[]")
+		}
+		return nil
+	case FormatCSV:
+		if err := s.startCSV(); err != nil {
+			return err
+		}
+		s.csv.Flush()
+		return s.csv.Error()
+	}
+
+	if s.n == 0 && s.view.Empty != "" {
+		fmt.Println(s.view.Empty)
+		return nil
+	}
+	s.flushTable()
+	if footer != "" {
+		fmt.Println(footer)
+	}
+	return nil
+}
+
+func (s *Stream) startCSV() error {
+	if s.csv != nil {
+		return nil
+	}
+	s.csv = csv.NewWriter(os.Stdout)
+	return s.csv.Write(s.view.Headers)
+}
+
+// flushTable prints the buffered rows, and the header before the first
+// ones. Columns are laid out like PrintTable.
+func (s *Stream) flushTable() {
+	for _, row := range s.rows {
+		for j, cell := range row {
+			if j < len(s.view.Headers) {
+				if max, ok := s.view.MaxWidth[s.view.Headers[j]]; ok {
+					row[j] = Truncate(cell, max)
+				}
+			}
+		}
+	}
+
+	if s.widths == nil {
+		s.widths = make([]int, len(s.view.Headers))
+		for _, row := range append([][]string{s.view.Headers}, s.rows...) {
+			for j, cell := range row {
+				if j < len(s.widths) {
+					s.widths[j] = max(s.widths[j], utf8.RuneCountInString(cell)+3)
+				}
+			}
+		}
+		dashes := make([]string, len(s.view.Headers))
+		for j, h := range s.view.Headers {
+			dashes[j] = strings.Repeat("-", utf8.RuneCountInString(h))
+		}
+		s.printRow(s.view.Headers)
+		s.printRow(dashes)
+	}
+
+	for _, row := range s.rows {
+		s.printRow(row)
+	}
+	s.rows = s.rows[:0]
+}
+
+func (s *Stream) printRow(row []string) {
+	var b strings.Builder
+	for j, cell := range row {
+		b.WriteString(cell)
+		if j < len(row)-1 {
+			pad := 3
+			if j < len(s.widths) {
+				pad = max(s.widths[j]-utf8.RuneCountInString(cell), 3)
+			}
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	fmt.Println(b.String())
+}
@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+
+	"golang.org/x/term"
 )
 
 func PrintTable(headers []string, rows [][]string) {
@@ -52,3 +57,35 @@ func BoolToString(b bool) string {
 	}
 	return "✗"
 }
+
+func Confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Choose asks the user to pick one of options by number and returns its index.
+func Choose(prompt string, options []string) (int, error) {
+	fmt.Println(prompt)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	fmt.Printf("Enter a number [1-%d]: ", len(options))
+
+	reader := bufio.NewReader(os.Stdin)
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(options) {
+		return 0, fmt.Errorf("invalid choice %q", strings.TrimSpace(answer))
+	}
+	return n - 1, nil
+}
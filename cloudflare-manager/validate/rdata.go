@@ -0,0 +1,338 @@
+package validate
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// rdataChecks validate the content of each supported record type.
+var rdataChecks = map[string]func(*checker, Record){
+	"A":     checkA,
+	"AAAA":  checkAAAA,
+	"CNAME": checkTarget("CNAME"),
+	"NS":    checkTarget("NS"),
+	"PTR":   checkTarget("PTR"),
+	"MX":    checkMX,
+	"TXT":   checkTXT,
+	"SRV":   checkSRV,
+	"CAA":   checkCAA,
+	"HTTPS": checkSVCB,
+	"SVCB":  checkSVCB,
+}
+
+func checkA(c *checker, r Record) {
+	ip := net.ParseIP(strings.TrimSpace(r.Content))
+	if ip == nil || ip.To4() == nil {
+		c.add(Error, r, "%q is not an IPv4 address", r.Content)
+		return
+	}
+	checkAddress(c, r, ip)
+}
+
+func checkAAAA(c *checker, r Record) {
+	ip := net.ParseIP(strings.TrimSpace(r.Content))
+	if ip == nil || ip.To4() != nil || !strings.Contains(r.Content, ":") {
+		c.add(Error, r, "%q is not an IPv6 address", r.Content)
+		return
+	}
+	checkAddress(c, r, ip)
+}
+
+func checkAddress(c *checker, r Record, ip net.IP) {
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() {
+		c.add(Warning, r, "%s is not a routable address", ip)
+	} else if r.Proxied && (ip.IsPrivate() || ip.IsLinkLocalUnicast()) {
+		c.add(Warning, r, "proxied record points at private address %s, which Cloudflare cannot reach", ip)
+	}
+}
+
+func checkTarget(rrtype string) func(*checker, Record) {
+	return func(c *checker, r Record) {
+		target := strings.TrimSpace(r.Content)
+		if net.ParseIP(target) != nil {
+			c.add(Error, r, "%s target must be a hostname, not an IP address (%s)", rrtype, target)
+			return
+		}
+		if err := checkName(normalizeName(target), false); err != nil {
+			c.add(Error, r, "invalid %s target %q: %v", rrtype, target, err)
+			return
+		}
+		// An address written without a trailing dot in a zone file becomes
+		// a name like 192.0.2.1.example.com.
+		if labels := strings.Split(target, "."); len(labels) > 4 && net.ParseIP(strings.Join(labels[:4], ".")) != nil {
+			c.add(Warning, r, "%s target %s looks like an IP address made relative to the zone", rrtype, target)
+		}
+		if rrtype == "CNAME" && normalizeName(target) == normalizeName(r.Name) {
+			c.add(Error, r, "CNAME points at itself")
+		}
+	}
+}
+
+func checkMX(c *checker, r Record) {
+	priority := r.Priority
+	if priority == nil {
+		if v, ok := r.Data["priority"]; ok {
+			if p, err := parseUint(v, 16); err == nil {
+				p16 := uint16(p)
+				priority = &p16
+			}
+		}
+	}
+	if priority == nil {
+		c.add(Error, r, "MX record needs a priority")
+	}
+
+	if strings.TrimSpace(r.Content) == "." {
+		// RFC 7505 null MX: the domain accepts no mail.
+		return
+	}
+	checkTarget("MX")(c, r)
+}
+
+func checkTXT(c *checker, r Record) {
+	text := r.Content
+	if text == "" {
+		c.add(Error, r, "TXT record is empty")
+		return
+	}
+	if len(text) > 2048 {
+		c.add(Error, r, "TXT content is %d characters, the limit is 2048", len(text))
+	}
+	if spf, ok := spfTerms(text); ok {
+		checkSPFSyntax(c, r, spf)
+	}
+}
+
+// structured returns the named fields of an SRV, CAA, HTTPS or SVCB record,
+// from Data when it is set and otherwise from Content in presentation
+// format.
+func structured(r Record, fields ...string) (map[string]string, error) {
+	values := map[string]string{}
+	if len(r.Data) > 0 {
+		for _, f := range fields {
+			if v, ok := r.Data[f]; ok && v != nil {
+				values[f] = fmt.Sprint(v)
+			}
+		}
+		return values, nil
+	}
+
+	parts := strings.Fields(r.Content)
+	// SRV content may leave the priority to the separate priority field.
+	if len(parts) == len(fields)-1 && fields[0] == "priority" && r.Priority != nil {
+		parts = append([]string{strconv.Itoa(int(*r.Priority))}, parts...)
+	}
+	if len(parts) < len(fields) {
+		return nil, fmt.Errorf("expected %s, got %q", strings.Join(fields, " "), r.Content)
+	}
+	for i, f := range fields {
+		if i == len(fields)-1 {
+			values[f] = strings.Join(parts[i:], " ")
+		} else {
+			values[f] = parts[i]
+		}
+	}
+	return values, nil
+}
+
+func checkSRV(c *checker, r Record) {
+	labels := strings.Split(normalizeName(r.Name), ".")
+	if len(labels) < 3 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+		c.add(Error, r, "SRV name must have the form _service._proto.name")
+	}
+
+	v, err := structured(r, "priority", "weight", "port", "target")
+	if err != nil {
+		c.add(Error, r, "invalid SRV data: %v", err)
+		return
+	}
+	for _, f := range []string{"priority", "weight", "port"} {
+		if _, err := parseUint(v[f], 16); err != nil {
+			c.add(Error, r, "SRV %s %q must be a number from 0 to 65535", f, v[f])
+		}
+	}
+	target := strings.TrimSpace(v["target"])
+	switch {
+	case target == ".":
+		// "." means the service is not available at this domain.
+	case net.ParseIP(target) != nil:
+		c.add(Error, r, "SRV target must be a hostname, not an IP address (%s)", target)
+	default:
+		if err := checkName(normalizeName(target), false); err != nil {
+			c.add(Error, r, "invalid SRV target %q: %v", target, err)
+		}
+	}
+}
+
+var caaTags = map[string]bool{
+	"issue": true, "issuewild": true, "iodef": true, "issuemail": true,
+	"issuevmc": true, "contactemail": true, "contactphone": true,
+}
+
+func checkCAA(c *checker, r Record) {
+	v, err := structured(r, "flags", "tag", "value")
+	if err != nil {
+		c.add(Error, r, "invalid CAA data: %v", err)
+		return
+	}
+	if _, err := parseUint(v["flags"], 8); err != nil {
+		c.add(Error, r, "CAA flags %q must be a number from 0 to 255", v["flags"])
+	}
+
+	tag := v["tag"]
+	switch {
+	case tag == "" || !isAlphaNum(tag):
+		c.add(Error, r, "invalid CAA tag %q: tags are letters and digits only", tag)
+		return
+	case !caaTags[strings.ToLower(tag)]:
+		c.add(Warning, r, "unknown CAA tag %q (expected issue, issuewild or iodef)", tag)
+		return
+	}
+
+	value := strings.Trim(v["value"], `"`)
+	switch strings.ToLower(tag) {
+	case "issue", "issuewild":
+		domain := strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+		if domain != "" {
+			if err := checkName(strings.ToLower(domain), false); err != nil {
+				c.add(Error, r, "invalid CAA issuer %q: %v", domain, err)
+			}
+		}
+	case "iodef":
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https") {
+			c.add(Error, r, "CAA iodef value %q must be a mailto:, http: or https: URL", value)
+		}
+	}
+}
+
+// svcParams are the SvcParamKeys registered by RFC 9460.
+var svcParams = map[string]bool{
+	"mandatory": true, "alpn": true, "no-default-alpn": true, "port": true,
+	"ipv4hint": true, "ech": true, "ipv6hint": true, "dohpath": true, "ohttp": true,
+}
+
+func checkSVCB(c *checker, r Record) {
+	rrtype := strings.ToUpper(r.Type)
+	v, err := structured(r, "priority", "target", "value")
+	if err != nil {
+		// Alias mode records have no parameters.
+		v, err = structured(r, "priority", "target")
+	}
+	if err != nil {
+		c.add(Error, r, "invalid %s data: %v", rrtype, err)
+		return
+	}
+
+	priority, err := parseUint(v["priority"], 16)
+	if err != nil {
+		c.add(Error, r, "%s priority %q must be a number from 0 to 65535", rrtype, v["priority"])
+	}
+	target := strings.TrimSpace(v["target"])
+	if target != "." {
+		if err := checkName(normalizeName(target), false); err != nil {
+			c.add(Error, r, "invalid %s target %q: %v", rrtype, target, err)
+		}
+	}
+
+	params := strings.Fields(v["value"])
+	if err == nil && priority == 0 && len(params) > 0 {
+		c.add(Error, r, "%s alias mode (priority 0) records cannot have parameters", rrtype)
+		return
+	}
+	for _, p := range params {
+		key, value, hasValue := strings.Cut(p, "=")
+		value = strings.Trim(value, `"`)
+		key = strings.ToLower(key)
+		if !svcParams[key] && !isGenericKey(key) {
+			c.add(Error, r, "unknown %s parameter %q", rrtype, key)
+			continue
+		}
+		if key == "no-default-alpn" {
+			if hasValue {
+				c.add(Error, r, "%s parameter no-default-alpn takes no value", rrtype)
+			}
+			continue
+		}
+		if !hasValue || value == "" {
+			c.add(Error, r, "%s parameter %s needs a value", rrtype, key)
+			continue
+		}
+		switch key {
+		case "port":
+			if _, err := parseUint(value, 16); err != nil {
+				c.add(Error, r, "%s port %q must be a number from 0 to 65535", rrtype, value)
+			}
+		case "ipv4hint", "ipv6hint":
+			for _, addr := range strings.Split(value, ",") {
+				ip := net.ParseIP(addr)
+				if ip == nil || (key == "ipv4hint") != (ip.To4() != nil) {
+					c.add(Error, r, "%s %s contains %q, which is not an %s address", rrtype, key, addr, strings.TrimSuffix(key, "hint"))
+				}
+			}
+		}
+	}
+}
+
+// isGenericKey matches the keyNNNNN form of unregistered SvcParamKeys.
+func isGenericKey(key string) bool {
+	n, ok := strings.CutPrefix(key, "key")
+	if !ok {
+		return false
+	}
+	_, err := strconv.ParseUint(n, 10, 16)
+	return err == nil
+}
+
+// checkName validates a domain name: labels of 1-63 letters, digits,
+// hyphens and underscores, at most 253 characters in total. A leading "*"
+// label is accepted when wildcard is true.
+func checkName(name string, wildcard bool) error {
+	if name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("name is %d characters, the limit is 253", len(name))
+	}
+	for i, label := range strings.Split(name, ".") {
+		if label == "*" && i == 0 && wildcard {
+			continue
+		}
+		if label == "" {
+			return fmt.Errorf("empty label")
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("label %q is longer than 63 characters", label)
+		}
+		for _, ch := range label {
+			if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_') {
+				return fmt.Errorf("label %q contains %q", label, ch)
+			}
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("label %q starts or ends with a hyphen", label)
+		}
+	}
+	return nil
+}
+
+func parseUint(v interface{}, bits int) (uint64, error) {
+	s := strings.TrimSpace(fmt.Sprint(v))
+	// Numbers decoded from JSON are float64 and may print as 1e+06.
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f == float64(uint64(f)) {
+		s = strconv.FormatUint(uint64(f), 10)
+	}
+	return strconv.ParseUint(s, 10, bits)
+}
+
+func isAlphaNum(s string) bool {
+	for _, ch := range s {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
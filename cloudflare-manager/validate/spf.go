@@ -0,0 +1,156 @@
+package validate
+
+import (
+	"net"
+	"strings"
+)
+
+// spfLookupLimit is the number of DNS-querying terms an SPF evaluation may
+// use (RFC 7208 section 4.6.4).
+const spfLookupLimit = 10
+
+// spfTerms returns the terms of an SPF policy after "v=spf1", or false if
+// text is not an SPF policy. Quoted TXT chunks are joined first.
+func spfTerms(text string) ([]string, bool) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, `"`) {
+		text = strings.ReplaceAll(strings.Trim(text, `"`), `" "`, "")
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "v=spf1") {
+		return nil, false
+	}
+	return fields[1:], true
+}
+
+// spfTerm splits a term into its mechanism or modifier name and argument.
+func spfTerm(term string) (name, arg string, modifier bool) {
+	term = strings.TrimLeft(term, "+-~?")
+	if i := strings.IndexAny(term, ":/="); i >= 0 {
+		return strings.ToLower(term[:i]), term[i+1:], term[i] == '='
+	}
+	return strings.ToLower(term), "", false
+}
+
+func checkSPFSyntax(c *checker, r Record, terms []string) {
+	for i, term := range terms {
+		name, arg, modifier := spfTerm(term)
+		if modifier {
+			if (name == "redirect" || name == "exp") && arg == "" {
+				c.add(Error, r, "SPF %s= needs a domain", name)
+			}
+			continue
+		}
+
+		switch name {
+		case "all":
+			if i < len(terms)-1 {
+				c.add(Warning, r, "SPF terms after %q are never evaluated", term)
+			}
+		case "include", "exists":
+			if arg == "" {
+				c.add(Error, r, "SPF %s needs a domain", name)
+			}
+		case "a", "mx":
+		case "ptr":
+			c.add(Warning, r, "SPF ptr mechanism is deprecated (RFC 7208) and should not be used")
+		case "ip4", "ip6":
+			ip := arg
+			if j := strings.Index(arg, "/"); j >= 0 {
+				_, _, err := net.ParseCIDR(arg)
+				if err != nil {
+					c.add(Error, r, "invalid SPF %s network %q", name, arg)
+					continue
+				}
+				ip = arg[:j]
+			}
+			parsed := net.ParseIP(ip)
+			if parsed == nil || (name == "ip4") != (parsed.To4() != nil) {
+				c.add(Error, r, "invalid SPF %s address %q", name, arg)
+			}
+		default:
+			c.add(Error, r, "unknown SPF mechanism %q", term)
+		}
+	}
+}
+
+// spfPolicies maps each name to the SPF policies published there.
+func spfPolicies(records []Record) map[string][][]string {
+	policies := map[string][][]string{}
+	for _, r := range records {
+		if !strings.EqualFold(r.Type, "TXT") {
+			continue
+		}
+		if terms, ok := spfTerms(r.Content); ok {
+			name := normalizeName(r.Name)
+			policies[name] = append(policies[name], terms)
+		}
+	}
+	return policies
+}
+
+// spf checks the SPF policies in one name group: only one may be
+// published, and evaluating it may not take more than ten DNS lookups.
+// Includes of names in the same record set are followed; others count as a
+// single lookup, so the total is a lower bound.
+func (c *checker) spf(records []Record, group []int, policies map[string][][]string, reported func(...int) bool) {
+	var spf []int
+	for _, i := range group {
+		if strings.EqualFold(records[i].Type, "TXT") {
+			if _, ok := spfTerms(records[i].Content); ok {
+				spf = append(spf, i)
+			}
+		}
+	}
+	if len(spf) > 1 && reported(spf...) {
+		c.add(Error, records[spf[len(spf)-1]], "%d SPF records published at the same name; receivers treat this as a permanent error", len(spf))
+	}
+
+	for _, i := range spf {
+		if !reported(i) {
+			continue
+		}
+		terms, _ := spfTerms(records[i].Content)
+		lookups, external := spfLookups(terms, policies, map[string]bool{normalizeName(records[i].Name): true})
+		if lookups > spfLookupLimit {
+			bound := ""
+			if external {
+				bound = "at least "
+			}
+			c.add(Error, records[i], "SPF policy needs %s%d DNS lookups, more than the limit of %d", bound, lookups, spfLookupLimit)
+		}
+	}
+}
+
+// spfLookups counts the DNS-querying terms of a policy, following includes
+// and redirects to policies in the record set. external reports whether an
+// include pointed outside it.
+func spfLookups(terms []string, policies map[string][][]string, visiting map[string]bool) (n int, external bool) {
+	for _, term := range terms {
+		name, arg, modifier := spfTerm(term)
+		if modifier && name != "redirect" {
+			continue
+		}
+		switch name {
+		case "a", "mx", "ptr", "exists":
+			n++
+		case "include", "redirect":
+			n++
+			target := normalizeName(arg)
+			nested, ok := policies[target]
+			if !ok || len(nested) != 1 {
+				external = true
+				continue
+			}
+			if visiting[target] {
+				continue
+			}
+			visiting[target] = true
+			m, ext := spfLookups(nested[0], policies, visiting)
+			delete(visiting, target)
+			n += m
+			external = external || ext
+		}
+	}
+	return n, external
+}
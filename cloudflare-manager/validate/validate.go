@@ -0,0 +1,291 @@
+// Package validate checks DNS records before they are sent to Cloudflare:
+// RFC syntax per record type, TTL ranges, proxy eligibility, CNAME
+// conflicts, duplicates and SPF lookup limits.
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare-manager/dnssync"
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Severity says whether an issue blocks a change.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Issue is one problem found with a record.
+type Issue struct {
+	Severity Severity `json:"severity" yaml:"severity"`
+	Record   string   `json:"record" yaml:"record"`
+	Message  string   `json:"message" yaml:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Record, i.Message)
+}
+
+// Record is the part of a DNS record that is validated. Structured types
+// (SRV, CAA, HTTPS, SVCB) may be given either as Data or as Content in zone
+// file presentation format.
+type Record struct {
+	Type     string
+	Name     string
+	Content  string
+	TTL      int
+	Proxied  bool
+	Priority *uint16
+	Data     map[string]interface{}
+}
+
+func (r Record) String() string {
+	return strings.ToUpper(r.Type) + " " + normalizeName(r.Name)
+}
+
+// FromDNSRecord converts a live record.
+func FromDNSRecord(rec cloudflare.DNSRecord) Record {
+	data, _ := rec.Data.(map[string]interface{})
+	return Record{
+		Type:     rec.Type,
+		Name:     rec.Name,
+		Content:  rec.Content,
+		TTL:      rec.TTL,
+		Proxied:  rec.Proxied != nil && *rec.Proxied,
+		Priority: rec.Priority,
+		Data:     data,
+	}
+}
+
+// FromParams converts a create request.
+func FromParams(params cloudflare.CreateDNSRecordParams) Record {
+	data, _ := params.Data.(map[string]interface{})
+	return Record{
+		Type:     params.Type,
+		Name:     params.Name,
+		Content:  params.Content,
+		TTL:      params.TTL,
+		Proxied:  params.Proxied != nil && *params.Proxied,
+		Priority: params.Priority,
+		Data:     data,
+	}
+}
+
+// FromSyncRecord converts a desired-state record, expanding its name
+// against zone.
+func FromSyncRecord(zone string, r dnssync.Record) Record {
+	r = dnssync.Normalize(zone, r)
+	return Record{
+		Type:     r.Type,
+		Name:     r.Name,
+		Content:  r.Content,
+		TTL:      r.TTL,
+		Proxied:  r.Proxied != nil && *r.Proxied,
+		Priority: r.Priority,
+		Data:     r.Data,
+	}
+}
+
+// Check validates a single record on its own.
+func Check(r Record) []Issue {
+	var c checker
+	c.record(r)
+	return c.issues
+}
+
+// CheckZone validates every record of a zone, including the checks that
+// compare records with each other.
+func CheckZone(zone string, records []Record) []Issue {
+	var c checker
+	for _, r := range records {
+		c.record(r)
+	}
+	c.set(zone, records, nil)
+	return c.issues
+}
+
+// CheckChange validates records about to be written to a zone that already
+// holds existing. Only problems involving the changed records are reported.
+func CheckChange(zone string, changed, existing []Record) []Issue {
+	var c checker
+	for _, r := range changed {
+		c.record(r)
+	}
+	all := append(append([]Record(nil), existing...), changed...)
+	c.set(zone, all, func(i int) bool { return i >= len(existing) })
+	return c.issues
+}
+
+// HasErrors reports whether any issue is an error.
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns an error listing the error-level issues, or nil if there are
+// none.
+func Err(issues []Issue) error {
+	var b strings.Builder
+	n := 0
+	for _, i := range issues {
+		if i.Severity != Error {
+			continue
+		}
+		n++
+		fmt.Fprintf(&b, "\n  %s", i)
+	}
+	if n == 0 {
+		return nil
+	}
+	if n == 1 {
+		return fmt.Errorf("validation failed:%s", b.String())
+	}
+	return fmt.Errorf("validation failed with %d errors:%s", n, b.String())
+}
+
+type checker struct {
+	issues []Issue
+}
+
+func (c *checker) add(sev Severity, r Record, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Severity: sev, Record: r.String(), Message: fmt.Sprintf(format, args...)})
+}
+
+// record runs the checks that need only the record itself.
+func (c *checker) record(r Record) {
+	rrtype := strings.ToUpper(r.Type)
+	if rrtype == "" {
+		c.add(Error, r, "record type is missing")
+		return
+	}
+	if err := checkName(normalizeName(r.Name), true); err != nil {
+		c.add(Error, r, "invalid name: %v", err)
+	}
+
+	switch {
+	case r.TTL == 0 || r.TTL == 1:
+	case r.TTL < 30 || r.TTL > 86400:
+		c.add(Error, r, "TTL %d is out of range: use 1 (automatic) or 30-86400 seconds", r.TTL)
+	case r.TTL < 60:
+		c.add(Warning, r, "TTL %d is below 60 seconds, which only Enterprise zones allow", r.TTL)
+	}
+
+	if r.Proxied {
+		switch rrtype {
+		case "A", "AAAA", "CNAME":
+			if r.TTL > 1 {
+				c.add(Warning, r, "TTL %d is ignored for proxied records", r.TTL)
+			}
+		default:
+			c.add(Error, r, "%s records cannot be proxied", rrtype)
+		}
+	}
+
+	if check, ok := rdataChecks[rrtype]; ok {
+		check(c, r)
+	}
+}
+
+// set runs the checks that compare records sharing a name. When report is
+// not nil only issues involving a record i with report(i) are kept.
+func (c *checker) set(zone string, records []Record, report func(int) bool) {
+	zone = normalizeName(zone)
+	reported := func(idx ...int) bool {
+		if report == nil {
+			return true
+		}
+		for _, i := range idx {
+			if report(i) {
+				return true
+			}
+		}
+		return false
+	}
+
+	byName := map[string][]int{}
+	for i, r := range records {
+		name := normalizeName(r.Name)
+		byName[name] = append(byName[name], i)
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	policies := spfPolicies(records)
+
+	for _, name := range names {
+		group := byName[name]
+
+		// Duplicates: the same type, name and content more than once.
+		seen := map[string]int{}
+		for _, i := range group {
+			r := records[i]
+			key := dnssync.Key(r.Type, r.Name, content(r))
+			if first, ok := seen[key]; ok {
+				if reported(first, i) {
+					c.add(Error, r, "duplicate record with content %q", content(r))
+				}
+				continue
+			}
+			seen[key] = i
+		}
+
+		// A CNAME must be the only record at its name.
+		for _, i := range group {
+			r := records[i]
+			if !strings.EqualFold(r.Type, "CNAME") || len(group) == 1 {
+				continue
+			}
+			var others []string
+			var involved []int
+			for _, j := range group {
+				if j == i {
+					continue
+				}
+				involved = append(involved, j)
+				if other := strings.ToUpper(records[j].Type); !contains(others, other) {
+					others = append(others, other)
+				}
+			}
+			if !reported(append(involved, i)...) {
+				continue
+			}
+			where := ""
+			if name == zone {
+				where = " at the zone apex"
+			}
+			c.add(Error, r, "CNAME%s cannot coexist with other records at the same name (%s)", where, strings.Join(others, ", "))
+		}
+
+		c.spf(records, group, policies, reported)
+	}
+}
+
+// content returns the record's presentation content, derived from Data for
+// structured records.
+func content(r Record) string {
+	return dnssync.Content(dnssync.Record{Type: r.Type, Content: r.Content, Data: r.Data})
+}
+
+func normalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package zonefile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Params converts the record into a Cloudflare create request.
+func (r Record) Params() (cloudflare.CreateDNSRecordParams, error) {
+	params := cloudflare.CreateDNSRecordParams{
+		Type: r.Type,
+		Name: r.Name,
+		TTL:  r.TTL,
+	}
+
+	need := func(n int) error {
+		if len(r.Data) < n {
+			return fmt.Errorf("line %d: %s record needs %d fields, got %d", r.Line, r.Type, n, len(r.Data))
+		}
+		return nil
+	}
+
+	switch r.Type {
+	case "A", "AAAA":
+		if err := need(1); err != nil {
+			return params, err
+		}
+		params.Content = r.Data[0]
+	case "CNAME", "NS", "PTR":
+		if err := need(1); err != nil {
+			return params, err
+		}
+		params.Content = strings.TrimSuffix(r.Data[0], ".")
+	case "TXT", "SPF":
+		if err := need(1); err != nil {
+			return params, err
+		}
+		params.Type = "TXT"
+		params.Content = strings.Join(r.Data, "")
+	case "MX":
+		if err := need(2); err != nil {
+			return params, err
+		}
+		priority, err := parseUint16(r.Data[0])
+		if err != nil {
+			return params, fmt.Errorf("line %d: MX preference: %w", r.Line, err)
+		}
+		params.Priority = &priority
+		params.Content = strings.TrimSuffix(r.Data[1], ".")
+	case "SRV", "CAA", "TLSA", "HTTPS", "SVCB", "URI", "LOC":
+		data, err := ParseData(r.Type, r.Data)
+		if err != nil {
+			return params, fmt.Errorf("line %d: %w", r.Line, err)
+		}
+		params.Data, params.Priority = SplitPriority(r.Type, data)
+	default:
+		return params, fmt.Errorf("line %d: %s records are not supported for import", r.Line, r.Type)
+	}
+
+	return params, nil
+}
+
+func parseUint16(s string) (uint16, error) {
+	v, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	return uint16(v), nil
+}
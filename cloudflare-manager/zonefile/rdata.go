@@ -0,0 +1,344 @@
+package zonefile
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	kindUint8 fieldKind = iota
+	kindUint16
+	kindFloat
+	kindString
+	kindName
+	kindHex
+	kindDirection
+)
+
+// dataField is one field of a structured record's data object, in
+// presentation order.
+type dataField struct {
+	name string
+	kind fieldKind
+	// rest fields take every remaining presentation token.
+	rest bool
+	// optional fields may be left out.
+	optional bool
+}
+
+// dataFields lists the data objects of the record types whose value the
+// Cloudflare API takes in "data" rather than "content". LOC is parsed
+// separately because its minutes and seconds are optional.
+var dataFields = map[string][]dataField{
+	"SRV": {
+		{name: "priority", kind: kindUint16},
+		{name: "weight", kind: kindUint16},
+		{name: "port", kind: kindUint16},
+		{name: "target", kind: kindName},
+	},
+	"CAA": {
+		{name: "flags", kind: kindUint8},
+		{name: "tag", kind: kindString},
+		{name: "value", kind: kindString, rest: true},
+	},
+	"TLSA": {
+		{name: "usage", kind: kindUint8},
+		{name: "selector", kind: kindUint8},
+		{name: "matching_type", kind: kindUint8},
+		{name: "certificate", kind: kindHex, rest: true},
+	},
+	"HTTPS": svcbFields,
+	"SVCB":  svcbFields,
+	"URI": {
+		{name: "priority", kind: kindUint16},
+		{name: "weight", kind: kindUint16},
+		{name: "target", kind: kindString},
+	},
+	"LOC": {
+		{name: "lat_degrees", kind: kindUint8},
+		{name: "lat_minutes", kind: kindUint8, optional: true},
+		{name: "lat_seconds", kind: kindFloat, optional: true},
+		{name: "lat_direction", kind: kindDirection},
+		{name: "long_degrees", kind: kindUint8},
+		{name: "long_minutes", kind: kindUint8, optional: true},
+		{name: "long_seconds", kind: kindFloat, optional: true},
+		{name: "long_direction", kind: kindDirection},
+		{name: "altitude", kind: kindFloat},
+		{name: "size", kind: kindFloat, optional: true},
+		{name: "precision_horz", kind: kindFloat, optional: true},
+		{name: "precision_vert", kind: kindFloat, optional: true},
+	},
+}
+
+var svcbFields = []dataField{
+	{name: "priority", kind: kindUint16},
+	{name: "target", kind: kindName},
+	{name: "value", kind: kindString, rest: true, optional: true},
+}
+
+// Structured reports whether records of rrtype carry their value as a data
+// object.
+func Structured(rrtype string) bool {
+	_, ok := dataFields[strings.ToUpper(rrtype)]
+	return ok
+}
+
+// DataFieldNames returns the data object keys of a structured type.
+func DataFieldNames(rrtype string) []string {
+	var names []string
+	for _, f := range dataFields[strings.ToUpper(rrtype)] {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// ParseData converts the RDATA fields of a structured record, as written in
+// a zone file, into a data object. Priorities are included in the object;
+// see SplitPriority.
+func ParseData(rrtype string, fields []string) (map[string]interface{}, error) {
+	rrtype = strings.ToUpper(rrtype)
+	if rrtype == "LOC" {
+		return parseLOC(fields)
+	}
+	schema, ok := dataFields[rrtype]
+	if !ok {
+		return nil, fmt.Errorf("%s records do not take structured data", rrtype)
+	}
+
+	data := map[string]interface{}{}
+	for i, f := range schema {
+		if i >= len(fields) {
+			if f.optional {
+				break
+			}
+			return nil, fmt.Errorf("%s record needs %s, got %d fields", rrtype, strings.Join(DataFieldNames(rrtype), " "), len(fields))
+		}
+		value := fields[i]
+		if f.rest {
+			sep := " "
+			if f.kind == kindHex {
+				sep = ""
+			}
+			value = strings.Join(fields[i:], sep)
+		}
+		if err := setField(rrtype, data, f, value); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields) > len(schema) && !schema[len(schema)-1].rest {
+		return nil, fmt.Errorf("%s record has %d fields, expected %d", rrtype, len(fields), len(schema))
+	}
+	return data, nil
+}
+
+// SetDataField sets one key of a data object from its text form, converting
+// it to the type the API expects.
+func SetDataField(rrtype string, data map[string]interface{}, key, value string) error {
+	rrtype = strings.ToUpper(rrtype)
+	for _, f := range dataFields[rrtype] {
+		if f.name == key {
+			return setField(rrtype, data, f, value)
+		}
+	}
+	return fmt.Errorf("unknown %s data field %q (expected one of %s)", rrtype, key, strings.Join(DataFieldNames(rrtype), ", "))
+}
+
+// MissingDataFields returns the required keys that data lacks.
+func MissingDataFields(rrtype string, data map[string]interface{}) []string {
+	var missing []string
+	for _, f := range dataFields[strings.ToUpper(rrtype)] {
+		if _, ok := data[f.name]; !ok && !f.optional {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
+// SplitPriority returns the data object to send and the record-level
+// priority for types that have one: SRV keeps its priority in both places,
+// URI only at record level.
+func SplitPriority(rrtype string, data map[string]interface{}) (map[string]interface{}, *uint16) {
+	rrtype = strings.ToUpper(rrtype)
+	if rrtype != "SRV" && rrtype != "URI" {
+		return data, nil
+	}
+	v, ok := data["priority"]
+	if !ok {
+		return data, nil
+	}
+	p, err := strconv.ParseUint(fmt.Sprint(v), 10, 16)
+	if err != nil {
+		return data, nil
+	}
+	priority := uint16(p)
+	if rrtype == "URI" {
+		out := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			if k != "priority" {
+				out[k] = v
+			}
+		}
+		data = out
+	}
+	return data, &priority
+}
+
+// FormatData renders a data object the way the API renders the content of
+// structured records. SRV and URI content leaves out the priority, which is
+// a separate field of the record.
+func FormatData(rrtype string, data map[string]interface{}) string {
+	get := func(key string) string {
+		if v, ok := data[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+
+	switch strings.ToUpper(rrtype) {
+	case "SRV":
+		return fmt.Sprintf("%s %s %s", get("weight"), get("port"), get("target"))
+	case "CAA":
+		return fmt.Sprintf("%s %s %q", get("flags"), get("tag"), get("value"))
+	case "TLSA":
+		return fmt.Sprintf("%s %s %s %s", get("usage"), get("selector"), get("matching_type"), get("certificate"))
+	case "HTTPS", "SVCB":
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", get("priority"), get("target"), get("value")))
+	case "URI":
+		return fmt.Sprintf("%s %q", get("weight"), get("target"))
+	case "LOC":
+		num := func(key string, def float64) float64 {
+			if f, err := strconv.ParseFloat(get(key), 64); err == nil {
+				return f
+			}
+			return def
+		}
+		return fmt.Sprintf("%g %g %.3f %s %g %g %.3f %s %.2fm %.2fm %.2fm %.2fm",
+			num("lat_degrees", 0), num("lat_minutes", 0), num("lat_seconds", 0), get("lat_direction"),
+			num("long_degrees", 0), num("long_minutes", 0), num("long_seconds", 0), get("long_direction"),
+			num("altitude", 0), num("size", 1), num("precision_horz", 10000), num("precision_vert", 10))
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprint(data[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Fields splits a record value into presentation fields the way a zone file
+// line is split, so quoted strings may contain spaces.
+func Fields(s string) ([]string, error) {
+	entries, err := scan(strings.NewReader(s))
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, e := range entries {
+		for _, t := range e.tokens {
+			fields = append(fields, t.text)
+		}
+	}
+	return fields, nil
+}
+
+func setField(rrtype string, data map[string]interface{}, f dataField, value string) error {
+	switch f.kind {
+	case kindUint8, kindUint16:
+		bits := 8
+		if f.kind == kindUint16 {
+			bits = 16
+		}
+		v, err := strconv.ParseUint(value, 10, bits)
+		if err != nil {
+			return fmt.Errorf("%s %s %q must be a number from 0 to %d", rrtype, f.name, value, 1<<bits-1)
+		}
+		data[f.name] = int(v)
+	case kindFloat:
+		v, err := strconv.ParseFloat(strings.TrimSuffix(value, "m"), 64)
+		if err != nil {
+			return fmt.Errorf("%s %s %q must be a number", rrtype, f.name, value)
+		}
+		data[f.name] = v
+	case kindName:
+		if value != "." {
+			value = strings.TrimSuffix(value, ".")
+		}
+		data[f.name] = value
+	case kindHex:
+		if _, err := hex.DecodeString(value); err != nil {
+			return fmt.Errorf("%s %s must be hexadecimal", rrtype, f.name)
+		}
+		data[f.name] = strings.ToLower(value)
+	case kindDirection:
+		value = strings.ToUpper(value)
+		allowed := "NS"
+		if strings.HasPrefix(f.name, "long") {
+			allowed = "EW"
+		}
+		if len(value) != 1 || !strings.Contains(allowed, value) {
+			return fmt.Errorf("%s %s %q must be one of %s", rrtype, f.name, value, strings.Join(strings.Split(allowed, ""), ", "))
+		}
+		data[f.name] = value
+	default:
+		data[f.name] = value
+	}
+	return nil
+}
+
+// parseLOC reads RFC 1876 presentation format:
+// d1 [m1 [s1]] N|S d2 [m2 [s2]] E|W alt[m] [size[m] [hp[m] [vp[m]]]]
+func parseLOC(fields []string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	byName := map[string]dataField{}
+	for _, f := range dataFields["LOC"] {
+		byName[f.name] = f
+	}
+	set := func(name, value string) error {
+		return setField("LOC", data, byName[name], value)
+	}
+
+	i := 0
+	for _, axis := range []string{"lat", "long"} {
+		parts := []string{"_degrees", "_minutes", "_seconds"}
+		for n := 0; i < len(fields); n++ {
+			if _, err := strconv.ParseFloat(fields[i], 64); err != nil || n == len(parts) {
+				break
+			}
+			if err := set(axis+parts[n], fields[i]); err != nil {
+				return nil, err
+			}
+			i++
+		}
+		if _, ok := data[axis+"_degrees"]; !ok || i >= len(fields) {
+			return nil, fmt.Errorf("LOC record needs a %situde: degrees [minutes [seconds]] and a direction", axis)
+		}
+		if err := set(axis+"_direction", fields[i]); err != nil {
+			return nil, err
+		}
+		i++
+	}
+
+	rest := []string{"altitude", "size", "precision_horz", "precision_vert"}
+	if i >= len(fields) {
+		return nil, fmt.Errorf("LOC record needs an altitude")
+	}
+	if len(fields)-i > len(rest) {
+		return nil, fmt.Errorf("LOC record has %d fields after the longitude, expected at most %d", len(fields)-i, len(rest))
+	}
+	for n := 0; i < len(fields); n, i = n+1, i+1 {
+		if err := set(rest[n], fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
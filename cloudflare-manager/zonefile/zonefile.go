@@ -0,0 +1,394 @@
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Record is a single resource record read from an RFC 1035 master file.
+// Name is fully qualified without the trailing dot and Data holds the RDATA
+// fields with quoted strings already unquoted.
+type Record struct {
+	Name  string
+	TTL   int
+	Class string
+	Type  string
+	Data  []string
+	Line  int
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+type entry struct {
+	tokens   []token
+	indented bool
+	line     int
+}
+
+var recordTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "CERT": true, "CNAME": true,
+	"DNSKEY": true, "DS": true, "HTTPS": true, "LOC": true, "MX": true,
+	"NAPTR": true, "NS": true, "PTR": true, "SMIMEA": true, "SOA": true,
+	"SPF": true, "SRV": true, "SSHFP": true, "SVCB": true, "TLSA": true,
+	"TXT": true, "URI": true,
+}
+
+var classes = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+// Parse reads a zone file and returns its records. origin is used until the
+// file sets its own $ORIGIN; relative names and "@" are resolved against it.
+func Parse(r io.Reader, origin string) ([]Record, error) {
+	entries, err := scan(r)
+	if err != nil {
+		return nil, err
+	}
+
+	origin = Fqdn(origin)
+	defaultTTL := -1
+	lastName := ""
+	lastTTL := -1
+	var records []Record
+
+	for _, e := range entries {
+		first := e.tokens[0]
+		if !first.quoted && strings.HasPrefix(first.text, "$") {
+			directive := strings.ToUpper(first.text)
+			switch directive {
+			case "$ORIGIN":
+				if len(e.tokens) < 2 {
+					return nil, fmt.Errorf("line %d: $ORIGIN requires a domain name", e.line)
+				}
+				origin = absolute(e.tokens[1].text, origin)
+			case "$TTL":
+				if len(e.tokens) < 2 {
+					return nil, fmt.Errorf("line %d: $TTL requires a value", e.line)
+				}
+				ttl, err := ParseTTL(e.tokens[1].text)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", e.line, err)
+				}
+				defaultTTL = ttl
+			default:
+				return nil, fmt.Errorf("line %d: unsupported directive %s", e.line, first.text)
+			}
+			continue
+		}
+
+		tokens := e.tokens
+		name := lastName
+		if !e.indented {
+			name = absolute(tokens[0].text, origin)
+			tokens = tokens[1:]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("line %d: record has no owner name", e.line)
+		}
+
+		ttl := -1
+		class := ""
+		for len(tokens) > 0 && !tokens[0].quoted {
+			t := strings.ToUpper(tokens[0].text)
+			if classes[t] && class == "" {
+				class = t
+			} else if v, err := ParseTTL(t); err == nil && ttl < 0 && !recordTypes[t] {
+				ttl = v
+			} else {
+				break
+			}
+			tokens = tokens[1:]
+		}
+
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("line %d: missing record type", e.line)
+		}
+		rrtype := strings.ToUpper(tokens[0].text)
+		if !recordTypes[rrtype] {
+			return nil, fmt.Errorf("line %d: unknown record type %q", e.line, tokens[0].text)
+		}
+		tokens = tokens[1:]
+
+		if ttl < 0 {
+			ttl = defaultTTL
+		}
+		if ttl < 0 {
+			ttl = lastTTL
+		}
+		if ttl < 0 && rrtype == "SOA" && len(tokens) == 7 {
+			if v, err := ParseTTL(tokens[6].text); err == nil {
+				ttl = v
+			}
+		}
+		if ttl < 0 {
+			return nil, fmt.Errorf("line %d: no TTL given and no $TTL in effect", e.line)
+		}
+		if class == "" {
+			class = "IN"
+		}
+
+		data := make([]string, 0, len(tokens))
+		for i, t := range tokens {
+			if !t.quoted && nameField(rrtype, i) {
+				data = append(data, absolute(t.text, origin))
+			} else {
+				data = append(data, t.text)
+			}
+		}
+
+		records = append(records, Record{
+			Name:  strings.TrimSuffix(name, "."),
+			TTL:   ttl,
+			Class: class,
+			Type:  rrtype,
+			Data:  data,
+			Line:  e.line,
+		})
+		lastName = name
+		lastTTL = ttl
+	}
+
+	return records, nil
+}
+
+// nameField reports whether the i-th RDATA field of rrtype is a domain name
+// that must be made absolute.
+func nameField(rrtype string, i int) bool {
+	switch rrtype {
+	case "CNAME", "NS", "PTR":
+		return i == 0
+	case "MX":
+		return i == 1
+	case "SRV":
+		return i == 3
+	case "SOA":
+		return i < 2
+	case "HTTPS", "SVCB":
+		return i == 1
+	}
+	return false
+}
+
+func absolute(name, origin string) string {
+	if name == "@" {
+		return origin
+	}
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	if origin == "" || origin == "." {
+		return name + "."
+	}
+	return name + "." + origin
+}
+
+// Fqdn returns name with exactly one trailing dot, or "" for an empty name.
+func Fqdn(name string) string {
+	if name == "" {
+		return ""
+	}
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+// ParseTTL accepts plain seconds or BIND-style unit suffixes such as 1h30m.
+func ParseTTL(s string) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty TTL")
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		return n, nil
+	}
+
+	total := 0
+	num := ""
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsDigit(r) {
+			num += string(r)
+			continue
+		}
+		if num == "" {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		n, _ := strconv.Atoi(num)
+		switch r {
+		case 's':
+		case 'm':
+			n *= 60
+		case 'h':
+			n *= 3600
+		case 'd':
+			n *= 86400
+		case 'w':
+			n *= 604800
+		default:
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		total += n
+		num = ""
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	return total, nil
+}
+
+// scan splits the input into logical entries, joining lines inside
+// parentheses and stripping comments.
+func scan(r io.Reader) ([]entry, error) {
+	var entries []entry
+	var cur *entry
+	depth := 0
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+
+		if depth == 0 {
+			if cur != nil && len(cur.tokens) > 0 {
+				entries = append(entries, *cur)
+			}
+			cur = &entry{
+				indented: len(line) > 0 && (line[0] == ' ' || line[0] == '\t'),
+				line:     lineNo,
+			}
+		}
+
+		i := 0
+		// glueAt is where the last unquoted token ended, so a quoted string
+		// right after it (alpn="h2,h3") stays part of that token.
+		glueAt := -1
+		for i < len(line) {
+			ch := line[i]
+			switch {
+			case ch == ';':
+				i = len(line)
+			case ch == ' ' || ch == '\t' || ch == '\r':
+				i++
+			case ch == '(':
+				depth++
+				i++
+			case ch == ')':
+				if depth == 0 {
+					return nil, fmt.Errorf("line %d: unbalanced ')'", lineNo)
+				}
+				depth--
+				i++
+			case ch == '"':
+				text, n, err := readQuoted(line[i+1:])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				if i == glueAt {
+					last := &cur.tokens[len(cur.tokens)-1]
+					last.text += `"` + text + `"`
+				} else {
+					cur.tokens = append(cur.tokens, token{text: text, quoted: true})
+				}
+				i += n + 2
+			default:
+				start := i
+				for i < len(line) && !strings.ContainsRune(" \t\r;()\"", rune(line[i])) {
+					if line[i] == '\\' {
+						i++
+					}
+					i++
+				}
+				if i > len(line) {
+					i = len(line)
+				}
+				text, err := unescape(line[start:i])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				cur.tokens = append(cur.tokens, token{text: text})
+				glueAt = i
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unexpected end of file inside parentheses")
+	}
+	if cur != nil && len(cur.tokens) > 0 {
+		entries = append(entries, *cur)
+	}
+	return entries, nil
+}
+
+// readQuoted reads a quoted string body up to the closing quote and returns
+// the unescaped text and the number of input bytes consumed (excluding the
+// closing quote).
+func readQuoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i, nil
+		case '\\':
+			c, n, err := escape(s[i+1:])
+			if err != nil {
+				return "", 0, fmt.Errorf("%w in quoted string", err)
+			}
+			b.WriteByte(c)
+			i += n
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// unescape decodes the \X and \DDD escapes of an unquoted field.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		c, n, err := escape(s[i+1:])
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(c)
+		i += n
+	}
+	return b.String(), nil
+}
+
+// escape decodes the escape that follows a backslash at the start of s and
+// returns the byte it stands for and the number of bytes it took.
+func escape(s string) (byte, int, error) {
+	if s == "" {
+		return 0, 0, fmt.Errorf("unterminated escape")
+	}
+	if len(s) >= 3 && isDigit(s[0]) && isDigit(s[1]) && isDigit(s[2]) {
+		v, _ := strconv.Atoi(s[:3])
+		if v > 255 {
+			return 0, 0, fmt.Errorf("invalid escape \\%s", s[:3])
+		}
+		return byte(v), 3, nil
+	}
+	return s[0], 1, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,136 @@
+package zonefile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Record
+	}{
+		{
+			name: "multi-line SOA with comments",
+			input: `$TTL 3600
+@ IN SOA ns1.example.net. admin ( ; primary and contact
+	2024010101 ; serial
+	7200       ; refresh
+	3600       ; retry
+	1209600    ; expire
+	300 )      ; minimum
+`,
+			want: []Record{
+				{Name: "example.com", TTL: 3600, Class: "IN", Type: "SOA", Line: 2, Data: []string{
+					"ns1.example.net.", "admin.example.com.", "2024010101", "7200", "3600", "1209600", "300",
+				}},
+			},
+		},
+		{
+			name: "TXT chunks split across lines",
+			input: `$TTL 300
+@ IN TXT ( "v=DKIM1; k=rsa; "
+           "p=MIGfMA0GCSqGSIb3" )
+`,
+			want: []Record{
+				{Name: "example.com", TTL: 300, Class: "IN", Type: "TXT", Line: 2, Data: []string{
+					"v=DKIM1; k=rsa; ", "p=MIGfMA0GCSqGSIb3",
+				}},
+			},
+		},
+		{
+			name: "second $ORIGIN",
+			input: `$TTL 300
+www IN CNAME web
+$ORIGIN dev.example.com.
+www IN CNAME web
+api IN A 192.0.2.1
+`,
+			want: []Record{
+				{Name: "www.example.com", TTL: 300, Class: "IN", Type: "CNAME", Line: 2, Data: []string{"web.example.com."}},
+				{Name: "www.dev.example.com", TTL: 300, Class: "IN", Type: "CNAME", Line: 4, Data: []string{"web.dev.example.com."}},
+				{Name: "api.dev.example.com", TTL: 300, Class: "IN", Type: "A", Line: 5, Data: []string{"192.0.2.1"}},
+			},
+		},
+		{
+			name: "blank owner reuses the previous name",
+			input: `$TTL 300
+mail IN A 192.0.2.1
+     IN AAAA 2001:db8::1
+@    IN MX 10 mail
+	IN MX 20 backup.example.net.
+`,
+			want: []Record{
+				{Name: "mail.example.com", TTL: 300, Class: "IN", Type: "A", Line: 2, Data: []string{"192.0.2.1"}},
+				{Name: "mail.example.com", TTL: 300, Class: "IN", Type: "AAAA", Line: 3, Data: []string{"2001:db8::1"}},
+				{Name: "example.com", TTL: 300, Class: "IN", Type: "MX", Line: 4, Data: []string{"10", "mail.example.com."}},
+				{Name: "example.com", TTL: 300, Class: "IN", Type: "MX", Line: 5, Data: []string{"20", "backup.example.net."}},
+			},
+		},
+		{
+			name: "$TTL and explicit TTL",
+			input: `$TTL 1h
+a IN A 192.0.2.1
+b 60 IN A 192.0.2.2
+c IN 2d A 192.0.2.3
+$TTL 90
+d A 192.0.2.4
+`,
+			want: []Record{
+				{Name: "a.example.com", TTL: 3600, Class: "IN", Type: "A", Line: 2, Data: []string{"192.0.2.1"}},
+				{Name: "b.example.com", TTL: 60, Class: "IN", Type: "A", Line: 3, Data: []string{"192.0.2.2"}},
+				{Name: "c.example.com", TTL: 172800, Class: "IN", Type: "A", Line: 4, Data: []string{"192.0.2.3"}},
+				{Name: "d.example.com", TTL: 90, Class: "IN", Type: "A", Line: 6, Data: []string{"192.0.2.4"}},
+			},
+		},
+		{
+			name: "escapes",
+			input: `$TTL 300
+@ IN TXT "caf\195\169 \"quoted\" \\"
+host\.name IN A 192.0.2.1
+\065b IN TXT semi\;colon
+`,
+			want: []Record{
+				{Name: "example.com", TTL: 300, Class: "IN", Type: "TXT", Line: 2, Data: []string{`café "quoted" \`}},
+				{Name: "host.name.example.com", TTL: 300, Class: "IN", Type: "A", Line: 3, Data: []string{"192.0.2.1"}},
+				{Name: "Ab.example.com", TTL: 300, Class: "IN", Type: "TXT", Line: 4, Data: []string{"semi;colon"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input), "example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no TTL", "www IN A 192.0.2.1\n", "line 1: no TTL given"},
+		{"unbalanced parenthesis", "$TTL 300\n@ IN SOA a. b. ( 1 2 3 4 5\n", "inside parentheses"},
+		{"escape out of range", "$TTL 300\n@ IN TXT \"\\300\"\n", `line 2: invalid escape \300`},
+		{"trailing backslash", "$TTL 300\nwww IN A 192.0.2.1\\\n", "line 2: unterminated escape"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input), "example.com")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
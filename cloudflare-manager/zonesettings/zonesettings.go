@@ -0,0 +1,267 @@
+// Package zonesettings knows the types of Cloudflare zone settings, parses
+// and validates values for them, and compares zones against a settings
+// profile.
+package zonesettings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"gopkg.in/yaml.v3"
+)
+
+type kind int
+
+const (
+	kindOnOff kind = iota
+	kindEnum
+	kindInt
+)
+
+// Setting describes the value a zone setting takes.
+type Setting struct {
+	Name        string
+	Description string
+	kind        kind
+	// values are the allowed values of enum settings, and of integer
+	// settings that only take certain numbers.
+	values []string
+}
+
+// Values returns the allowed values, or nil when any value of the type is
+// accepted.
+func (s Setting) Values() []string {
+	if s.kind == kindOnOff {
+		return []string{"on", "off"}
+	}
+	return s.values
+}
+
+// Type is a short description of the value type for help output.
+func (s Setting) Type() string {
+	if s.kind == kindInt {
+		return "number"
+	}
+	return strings.Join(s.Values(), "|")
+}
+
+func onOff(name, description string) Setting {
+	return Setting{Name: name, Description: description, kind: kindOnOff}
+}
+
+func enum(name, description string, values ...string) Setting {
+	return Setting{Name: name, Description: description, kind: kindEnum, values: values}
+}
+
+var settings = map[string]Setting{}
+
+func init() {
+	for _, s := range []Setting{
+		enum("ssl", "SSL/TLS encryption mode", "off", "flexible", "full", "strict"),
+		onOff("always_use_https", "Redirect HTTP requests to HTTPS"),
+		onOff("automatic_https_rewrites", "Rewrite HTTP links to HTTPS"),
+		enum("min_tls_version", "Minimum TLS version", "1.0", "1.1", "1.2", "1.3"),
+		enum("tls_1_3", "TLS 1.3", "on", "off", "zrt"),
+		onOff("opportunistic_encryption", "Opportunistic encryption"),
+		onOff("http3", "HTTP/3 (QUIC)"),
+		onOff("http2", "HTTP/2"),
+		onOff("0rtt", "0-RTT connection resumption"),
+		onOff("brotli", "Brotli compression"),
+		onOff("early_hints", "Early Hints"),
+		onOff("ipv6", "IPv6 compatibility"),
+		onOff("websockets", "WebSockets"),
+		onOff("always_online", "Always Online"),
+		onOff("development_mode", "Development mode, bypasses the cache for 3 hours"),
+		onOff("email_obfuscation", "Email address obfuscation"),
+		onOff("hotlink_protection", "Hotlink protection"),
+		onOff("rocket_loader", "Rocket Loader"),
+		onOff("browser_check", "Browser integrity check"),
+		enum("security_level", "Security level", "off", "essentially_off", "low", "medium", "high", "under_attack"),
+		enum("cache_level", "Caching level", "bypass", "basic", "simplified", "aggressive", "cache_everything"),
+		{Name: "browser_cache_ttl", Description: "Browser cache TTL in seconds, 0 respects existing headers", kind: kindInt, values: []string{
+			"0", "30", "60", "120", "300", "1200", "1800", "3600", "7200", "10800", "14400", "18000", "28800",
+			"43200", "57600", "72000", "86400", "172800", "259200", "345600", "432000", "691200", "1382400",
+			"2073600", "2678400", "5356800", "16070400", "31536000",
+		}},
+		{Name: "challenge_ttl", Description: "Challenge passage TTL in seconds", kind: kindInt, values: []string{
+			"300", "900", "1800", "2700", "3600", "7200", "10800", "14400", "28800", "57600", "86400", "604800", "2592000", "31536000",
+		}},
+		{Name: "max_upload", Description: "Maximum upload size in MB", kind: kindInt},
+	} {
+		settings[s.Name] = s
+	}
+}
+
+// Lookup returns the description of a known setting.
+func Lookup(name string) (Setting, bool) {
+	s, ok := settings[name]
+	return s, ok
+}
+
+// Known returns every known setting, sorted by name.
+func Known() []Setting {
+	list := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Normalize validates a value for a setting and converts it to the API's
+// type: "on"/"off" for switches (true and false are accepted), a number for
+// integer settings and a string otherwise.
+func Normalize(name string, value interface{}) (interface{}, error) {
+	s, ok := settings[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown zone setting %q (see 'cfm zone settings set --help')", name)
+	}
+
+	text := strings.ToLower(strings.TrimSpace(fmt.Sprint(value)))
+	if f, ok := value.(float64); ok && s.kind == kindEnum {
+		// YAML reads min_tls_version: 1.0 as a number.
+		text = strconv.FormatFloat(f, 'f', 1, 64)
+	}
+	switch s.kind {
+	case kindOnOff:
+		switch text {
+		case "on", "true":
+			return "on", nil
+		case "off", "false":
+			return "off", nil
+		}
+		return nil, fmt.Errorf("%s must be on or off, got %q", name, text)
+	case kindInt:
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", name, text)
+		}
+		if len(s.values) > 0 && !contains(s.values, text) {
+			return nil, fmt.Errorf("%s %d is not allowed (use one of %s)", name, n, strings.Join(s.values, ", "))
+		}
+		return n, nil
+	}
+	if !contains(s.values, text) {
+		return nil, fmt.Errorf("%s must be one of %s, got %q", name, strings.Join(s.values, ", "), text)
+	}
+	return text, nil
+}
+
+// Format renders a setting value for display and comparison. Numbers are
+// shown without a fraction and objects as compact JSON.
+func Format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int, bool:
+		return fmt.Sprint(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// Profile is a set of desired setting values, read from YAML:
+//
+//	settings:
+//	  ssl: strict
+//	  always_use_https: on
+//	  min_tls_version: "1.2"
+type Profile struct {
+	Settings map[string]interface{} `yaml:"settings"`
+}
+
+// LoadProfile reads and validates a settings profile.
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(p.Settings) == 0 {
+		return nil, fmt.Errorf("%s has no settings", path)
+	}
+	profile, err := NewProfile(p.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// NewProfile validates settings and returns a profile with their values
+// normalized.
+func NewProfile(settings map[string]interface{}) (*Profile, error) {
+	p := &Profile{Settings: make(map[string]interface{}, len(settings))}
+	for name, value := range settings {
+		v, err := Normalize(name, value)
+		if err != nil {
+			return nil, err
+		}
+		p.Settings[name] = v
+	}
+	return p, nil
+}
+
+// Change is a setting whose current value differs from the profile.
+type Change struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Value is the value to send to the API.
+	Value interface{} `json:"-"`
+}
+
+// Diff returns the settings of the profile whose current value differs,
+// sorted by name. Settings the zone does not report count as changed.
+func (p *Profile) Diff(current []cloudflare.ZoneSetting) []Change {
+	values := map[string]string{}
+	for _, s := range current {
+		values[s.ID] = Format(s.Value)
+	}
+
+	var changes []Change
+	for name, want := range p.Settings {
+		have, ok := values[name]
+		if ok && have == Format(want) {
+			continue
+		}
+		if !ok {
+			have = "(not set)"
+		}
+		changes = append(changes, Change{Name: name, From: have, To: Format(want), Value: want})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// Updates converts changes into an API update request.
+func Updates(changes []Change) []cloudflare.ZoneSetting {
+	updates := make([]cloudflare.ZoneSetting, 0, len(changes))
+	for _, c := range changes {
+		updates = append(updates, cloudflare.ZoneSetting{ID: c.Name, Value: c.Value})
+	}
+	return updates
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}